@@ -0,0 +1,206 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/allezxandre/go-hls-encoder/converter"
+	"github.com/allezxandre/go-hls-encoder/encryption"
+	"github.com/allezxandre/go-hls-encoder/suggest"
+)
+
+func runEncode(fs *flag.FlagSet, args []string) error {
+	outputDir := fs.String("o", "", "Output directory. Required")
+	planFile := fs.String("plan", "", "Encode the plan from this file (see the suggest command) instead of suggesting one")
+	masterName := fs.String("master", "", "Name of the master playlist, without extension. Overrides the plan")
+	streamName := fs.String("stream", "", "Prefix of the variant playlists, without extension. Overrides the plan")
+	segmentDuration := fs.Float64("segment-duration", 0, "Target segment duration in seconds. Overrides the plan")
+	segmentContainer := fs.String("segment-type", "", "Segment container, \"fmp4\" or \"mpegts\". Overrides the plan")
+	playlistType := fs.String("playlist-type", "", "Media playlist type, \"vod\", \"event\" or \"live\". Overrides the plan")
+	listSize := fs.Int("list-size", 0, "Maximum number of segments in live media playlists, whose old segments are removed. 0 to keep them all")
+	partDuration := fs.Float64("part-duration", 0, "Low-Latency HLS: announce parts of this many seconds as they are written. Needs an event or live playlist type")
+	timeout := fs.Duration("timeout", 0, "Stop the encode after this duration. 0 to disable")
+	writeDASH := fs.Bool("dash", false, "Also write a DASH manifest next to the master playlist, with the same fmp4 segments")
+	encrypt := fs.String("encrypt", "", "Encrypt the segments with \"AES-128\" (mpegts), \"SAMPLE-AES\" or \"SAMPLE-AES-CTR\" (fmp4). Overrides the plan")
+	keyRotation := fs.Int("key-rotation", 0, "With -encrypt, use a new key every this many segments. 0 for a single key")
+	keyURIPrefix := fs.String("key-uri-prefix", "", "With -encrypt, prefix of the key URIs, e.g. \"https://keys.example.com/movie/\". Keys are written to the output directory")
+	options := suggestFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if len(*outputDir) == 0 || (len(*planFile) == 0) == (fs.NArg() == 0) {
+		fs.Usage()
+		return flag.ErrHelp
+	}
+
+	// Inputs are probed again for the progress of the encode
+	if err := options.probe.apply(); err != nil {
+		return err
+	}
+	var plan *suggest.EncodingPlan
+	var err error
+	if len(*planFile) > 0 {
+		plan, err = suggest.LoadPlan(*planFile)
+	} else {
+		plan, err = suggestPlan(fs.Args(), options)
+		if err == nil && len(plan.Subtitles) > 0 && hasLiveInput(plan.Inputs) {
+			fmt.Fprintln(os.Stderr, "Subtitles of live inputs are not converted")
+			plan.Subtitles = nil
+		}
+	}
+	if err != nil {
+		return err
+	}
+	if len(*masterName) > 0 {
+		plan.HLS.MasterPlaylistName = *masterName
+	}
+	if len(*streamName) > 0 {
+		plan.HLS.StreamPlaylistName = *streamName
+	}
+	if *segmentDuration > 0 {
+		plan.Packaging.SegmentDuration = *segmentDuration
+	}
+	if len(*segmentContainer) > 0 {
+		plan.Packaging.SegmentContainer = suggest.SegmentContainer(*segmentContainer)
+	}
+	if len(*playlistType) > 0 {
+		plan.Packaging.PlaylistType = suggest.PlaylistType(*playlistType)
+	}
+	if *listSize > 0 {
+		plan.Packaging.ListSize = *listSize
+	}
+	if *partDuration > 0 {
+		plan.Packaging.LowLatency = &suggest.LowLatencyOptions{PartDuration: *partDuration}
+		if len(*planFile) == 0 {
+			plan.Video = encodedVideo(plan.Video)
+		}
+	}
+	if *writeDASH {
+		plan.Packaging.DASH = true
+	}
+	if len(*encrypt) > 0 {
+		plan.Packaging.Encryption = &suggest.EncryptionOptions{
+			Method:       encryption.Method(*encrypt),
+			KeyRotation:  *keyRotation,
+			KeyURIPrefix: *keyURIPrefix,
+		}
+	}
+
+	// Stop on SIGINT / SIGTERM, or after the timeout
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if *timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, *timeout)
+		defer cancel()
+	}
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(signals)
+	launched := make(chan *converter.Conversion, 1)
+	go func() {
+		var conversion *converter.Conversion
+		for {
+			select {
+			case conversion = <-launched:
+				continue
+			case <-signals:
+				if conversion != nil && conversion.Live() {
+					// Live conversions end with what was encoded so far. A second signal aborts them
+					fmt.Fprintln(os.Stderr, "Stopping the live conversion, interrupt again to abort it")
+					conversion.Stop()
+					conversion = nil
+					continue
+				}
+				cancel()
+			case <-ctx.Done():
+			}
+			return
+		}
+	}()
+
+	conversion, err := converter.LaunchPlan(ctx, *outputDir, plan)
+	if err != nil {
+		return err
+	}
+	launched <- conversion
+
+	printProgress(conversion.Progress())
+	result, err := conversion.Wait()
+	printResult(result)
+	return err
+}
+
+// hasLiveInput Tells whether one of `inputs` is live. See converter.IsLiveInput.
+func hasLiveInput(inputs []string) bool {
+	for _, input := range inputs {
+		if converter.IsLiveInput(input) {
+			return true
+		}
+	}
+	return false
+}
+
+// encodedVideo Returns the video variants that are not copied: the key frames of
+// copied ones follow their source, not the parts of low-latency playlists
+func encodedVideo(variants []suggest.VideoVariant) (encoded []suggest.VideoVariant) {
+	for _, v := range variants {
+		if v.Codec == "copy" {
+			fmt.Fprintf(os.Stderr, "Video variant %s (%s) is copied: left out of the low-latency playlists\n", v.MapInput, v.Resolution)
+			continue
+		}
+		encoded = append(encoded, v)
+	}
+	return
+}
+
+// printProgress Prints progress updates to Stderr until the channel is closed
+func printProgress(progress <-chan converter.Progress) {
+	lastPrint := time.Time{}
+	for p := range progress {
+		if !p.Done && time.Since(lastPrint) < time.Second {
+			continue
+		}
+		lastPrint = time.Now()
+		name := string(p.Phase)
+		if len(p.Name) > 0 {
+			name += " " + p.Name
+		}
+		percent := "?"
+		if p.Percent >= 0 {
+			percent = fmt.Sprintf("%.1f", p.Percent)
+		}
+		if p.Phase == converter.IFrameStep || p.Phase == converter.EncryptStep {
+			fmt.Fprintf(os.Stderr, "[%s] %s%%\n", name, percent)
+		} else {
+			fmt.Fprintf(os.Stderr, "[%s] %s%% time=%v speed=%.2fx fps=%.1f bitrate=%s\n",
+				name, percent, p.OutTime, p.Speed, p.FPS, p.Bitrate)
+		}
+	}
+}
+
+func printResult(r *converter.Result) {
+	if r == nil {
+		return
+	}
+	fmt.Printf("Output directory: %s\n", r.OutputDirectory)
+	if len(r.MasterPlaylist) > 0 {
+		fmt.Printf("Master playlist: %s\n", r.MasterPlaylist)
+	}
+	fmt.Printf("%d playlists, %d segments, %d log files\n", len(r.Playlists), len(r.Segments), len(r.LogFiles))
+	if len(r.Keys) > 0 {
+		fmt.Printf("Keys: %s\n", strings.Join(r.Keys, ", "))
+	}
+	for _, s := range r.Steps {
+		fmt.Println("  " + s.String())
+	}
+	if errors.Is(r.Interrupted, context.DeadlineExceeded) {
+		fmt.Println("Encode timed out")
+	}
+}
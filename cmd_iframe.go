@@ -0,0 +1,30 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+
+	"github.com/allezxandre/go-hls-encoder/converter"
+	"github.com/allezxandre/go-hls-encoder/iframe-playlist-generator"
+)
+
+func runIFrame(fs *flag.FlagSet, args []string) error {
+	dir := fs.String("dir", "", "Directory of the HLS package. Required")
+	master := fs.String("master", "master.m3u8", "Master playlist to update")
+	info := fs.String("info", converter.FFMPEG_MASTER_PLAYLIST, "Master playlist written by ffmpeg, to enrich the master playlist with. Skipped if missing")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if len(*dir) == 0 {
+		fs.Usage()
+		return flag.ErrHelp
+	}
+
+	if _, err := os.Stat(filepath.Join(*dir, *info)); err == nil {
+		if _, err := iframe_playlist_generator.EnrichPlaylist(*dir, *master, *dir, *info, *master); err != nil {
+			return err
+		}
+	}
+	return iframe_playlist_generator.GeneratePlaylist(*dir, *master)
+}
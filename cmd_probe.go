@@ -0,0 +1,124 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/allezxandre/go-hls-encoder/probe"
+	"github.com/allezxandre/go-hls-encoder/suggest"
+)
+
+func runProbe(fs *flag.FlagSet, args []string) error {
+	asJSON := fs.Bool("json", false, "Print the raw probe data as JSON")
+	probeOptions := probeFlags(fs)
+	inputs, err := parseInputs(fs, args)
+	if err != nil {
+		return err
+	}
+	if err := probeOptions.apply(); err != nil {
+		return err
+	}
+
+	probes, err := probe.GetProbeData(inputs...)
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		return printJSON(probes)
+	}
+	for i, p := range probes {
+		if i > 0 {
+			fmt.Println()
+		}
+		printProbe(inputs[i], p)
+	}
+	return nil
+}
+
+// probeOptions The flags of the commands that probe their inputs
+type probeOptions struct {
+	cacheDir *string
+	backend  *string
+}
+
+// probeFlags Defines the flags of probeOptions on `fs`
+func probeFlags(fs *flag.FlagSet) probeOptions {
+	return probeOptions{
+		cacheDir: fs.String("probe-cache", "", "Cache probe data in this directory: unchanged files are not probed again"),
+		backend:  fs.String("prober", "ffprobe", "Probe with \"ffprobe\", or \"native\" to read MP4, Matroska and MPEG-TS files without ffprobe"),
+	}
+}
+
+// apply Sets the prober and cache used by probe.GetProbeData
+func (o probeOptions) apply() error {
+	switch *o.backend {
+	case "ffprobe":
+		probe.DefaultProber = probe.FFprobe{}
+	case "native":
+		probe.DefaultProber = probe.Native{}
+	default:
+		return fmt.Errorf("unknown prober %q", *o.backend)
+	}
+	if len(*o.cacheDir) == 0 {
+		return nil
+	}
+	cache, err := probe.NewCache(*o.cacheDir)
+	if err != nil {
+		return err
+	}
+	probe.DefaultCache = cache
+	return nil
+}
+
+// printProbe Prints a human-readable summary of the probe data
+func printProbe(input string, p *probe.ProbeData) {
+	fmt.Println(input)
+	if p.Format != nil {
+		fmt.Printf("  Format: %s, duration: %ss, bitrate: %s\n",
+			p.Format.FormatLongName, p.Format.DurationSeconds, p.Format.BitRate)
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "  #\tTYPE\tCODEC\tDETAILS\tLANGUAGE\tTITLE\tFLAGS")
+	for _, s := range p.Streams {
+		var details string
+		switch s.CodecType {
+		case "video":
+			details = fmt.Sprintf("%dx%d %s fps %s", s.Width, s.Height, s.AvgFrameRate, s.PixFmt)
+			if dynamicRange := suggest.ClassifyDynamicRange(s); dynamicRange != suggest.SDR {
+				details += " " + string(dynamicRange)
+			}
+		case "audio":
+			details = fmt.Sprintf("%d ch %s Hz %s", s.Channels, s.SampleRate, s.ChannelLayout)
+		}
+		codec := s.CodecName
+		if len(s.Profile) > 0 {
+			codec += " (" + s.Profile + ")"
+		}
+		var flags []string
+		if s.Disposition.Default == 1 {
+			flags = append(flags, "default")
+		}
+		if s.Disposition.Forced == 1 {
+			flags = append(flags, "forced")
+		}
+		if s.Disposition.HearingImpaired == 1 {
+			flags = append(flags, "hearing-impaired")
+		}
+		fmt.Fprintf(w, "  %d\t%s\t%s\t%s\t%s\t%s\t%s\n", s.Index, s.CodecType, codec,
+			details, s.Tags.Language, s.Tags.Title, strings.Join(flags, ","))
+	}
+	w.Flush()
+	for _, c := range p.Chapters {
+		fmt.Printf("  Chapter %d: %ss - %ss %s\n", c.ID, c.StartTime, c.EndTime, c.Tags.Title)
+	}
+}
+
+func printJSON(v interface{}) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/allezxandre/go-hls-encoder/input"
+	"github.com/allezxandre/go-hls-encoder/language"
+	"github.com/allezxandre/go-hls-encoder/probe"
+	"github.com/allezxandre/go-hls-encoder/suggest"
+)
+
+// noAdditionalSubtitles Is an additional subtitles searcher that finds nothing
+func noAdditionalSubtitles([]input.Language) map[input.Language][]input.SubtitleInput {
+	return nil
+}
+
+// suggestOptions The flags shared by the commands that suggest a plan
+type suggestOptions struct {
+	stereo     *bool
+	removeVFQ  *bool
+	languages  languageOptions
+	ladderFile *string
+	analyze    *bool
+	hevc       *string
+	toneMap    *string
+	probe      probeOptions
+}
+
+// suggestFlags Defines the flags of suggestOptions on `fs`
+func suggestFlags(fs *flag.FlagSet) suggestOptions {
+	return suggestOptions{
+		stereo:     fs.Bool("stereo", true, "Create an alternate stereo variant for surround audio"),
+		removeVFQ:  fs.Bool("remove-vfq", false, "Remove Québec French when another French is available. Same as -drop-dialects fr-CA"),
+		languages:  languageFlags(fs),
+		ladderFile: fs.String("ladder", "", "Read the H.264 bitrate ladder from this JSON or YAML file instead of using Apple's TN2224 one"),
+		analyze:    fs.Bool("analyze", false, "Adapt the ladder to the complexity of the video, measured by encoding a few samples"),
+		hevc:       fs.String("hevc", string(suggest.HEVCWithH264Fallback), "HEVC sources: \"copy\", \"h264-fallback\" to add an H.264 rendition, or \"h264\" to convert them"),
+		toneMap:    fs.String("tonemap", suggest.DefaultToneMapOperator, "Tone-mapping operator of the SDR H.264 renditions of HDR sources, or \"\" to keep them in HDR"),
+		probe:      probeFlags(fs),
+	}
+}
+
+func runSuggest(fs *flag.FlagSet, args []string) error {
+	options := suggestFlags(fs)
+	output := fs.String("o", "", "Write the plan to this file (.json, .yaml or .yml) instead of Stdout")
+	asYAML := fs.Bool("yaml", false, "Print the plan as YAML instead of JSON")
+	inputs, err := parseInputs(fs, args)
+	if err != nil {
+		return err
+	}
+
+	plan, err := suggestPlan(inputs, options)
+	if err != nil {
+		return err
+	}
+	if len(*output) > 0 {
+		return plan.Save(*output)
+	}
+	format := suggest.JSONPlan
+	if *asYAML {
+		format = suggest.YAMLPlan
+	}
+	return plan.Encode(os.Stdout, format)
+}
+
+// suggestPlan Probes the inputs and suggests their plan
+func suggestPlan(inputs []string, options suggestOptions) (*suggest.EncodingPlan, error) {
+	if err := options.probe.apply(); err != nil {
+		return nil, err
+	}
+	probes, err := probe.GetProbeData(inputs...)
+	if err != nil {
+		return nil, err
+	}
+	videoOptions := suggest.DefaultVideoOptions()
+	videoOptions.HEVC = suggest.HEVCPolicy(*options.hevc)
+	videoOptions.ToneMap = *options.toneMap
+	if len(*options.ladderFile) > 0 {
+		if videoOptions.Ladder, err = suggest.LoadLadder(*options.ladderFile); err != nil {
+			return nil, err
+		}
+	}
+	if err := videoOptions.Validate(); err != nil {
+		return nil, err
+	}
+	policy, err := options.languages.policy()
+	if err != nil {
+		return nil, err
+	}
+	if *options.removeVFQ {
+		policy.DropDialects = append(policy.DropDialects, language.CanadianFrench)
+	}
+	plan := suggest.SuggestPlanWithPolicy(inputs, probes, *options.stereo, noAdditionalSubtitles, policy)
+	if *options.analyze {
+		if plan.Video, err = suggest.SuggestPerTitleVideo(inputs, probes, videoOptions); err != nil {
+			return nil, err
+		}
+	} else {
+		plan.Video = suggest.SuggestVideo(probes, videoOptions)
+	}
+	return plan, nil
+}
+
+// languageOptions The flags of the language policy
+type languageOptions struct {
+	audio        *string
+	subtitles    *string
+	only         *bool
+	dropDialects *string
+	defaultLang  *string
+}
+
+// languageFlags Defines the flags of languageOptions on `fs`
+func languageFlags(fs *flag.FlagSet) languageOptions {
+	defaults := suggest.DefaultLanguagePolicy()
+	return languageOptions{
+		audio:        fs.String("audio-languages", joinLanguages(defaults.Audio), "Wanted audio languages, in priority order, e.g. \"fr,en\""),
+		subtitles:    fs.String("subtitle-languages", joinLanguages(defaults.Subtitles), "Wanted subtitle languages, in priority order"),
+		only:         fs.Bool("only-languages", defaults.Only, "Drop the audio and subtitle languages that are not wanted"),
+		dropDialects: fs.String("drop-dialects", joinLanguages(defaults.DropDialects), "Dialects to drop when another dialect of their language is available, e.g. \"fr-CA\""),
+		defaultLang:  fs.String("default-language", string(defaults.Default), "Language of the DEFAULT audio, instead of the default stream of the source"),
+	}
+}
+
+// policy Returns the language policy of the flags
+func (o languageOptions) policy() (policy suggest.LanguagePolicy, err error) {
+	if policy.Audio, err = parseLanguages(*o.audio); err != nil {
+		return
+	}
+	if policy.Subtitles, err = parseLanguages(*o.subtitles); err != nil {
+		return
+	}
+	if policy.DropDialects, err = parseLanguages(*o.dropDialects); err != nil {
+		return
+	}
+	if policy.Default, err = language.Parse(*o.defaultLang); err != nil {
+		return policy, fmt.Errorf("%v %q", err, *o.defaultLang)
+	}
+	policy.Only = *o.only
+	return policy, policy.Validate()
+}
+
+// parseLanguages Parses a comma-separated list of language tags
+func parseLanguages(list string) (languages []input.Language, err error) {
+	for _, s := range strings.Split(list, ",") {
+		if len(strings.TrimSpace(s)) == 0 {
+			continue
+		}
+		tag, err := language.Parse(s)
+		if err != nil {
+			return nil, fmt.Errorf("%v %q", err, s)
+		}
+		languages = append(languages, tag)
+	}
+	return languages, nil
+}
+
+func joinLanguages(languages []input.Language) string {
+	s := make([]string, len(languages))
+	for i, l := range languages {
+		s[i] = string(l)
+	}
+	return strings.Join(s, ",")
+}
@@ -0,0 +1,223 @@
+// Package codecs builds the codec strings of RFC 6381, as used by the
+// CODECS attribute of HLS master playlists.
+package codecs
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrUnknownCodec Is returned when the codec string of a stream cannot be determined
+var ErrUnknownCodec = errors.New("unknown codec")
+
+// Codec strings that do not depend on the stream parameters
+const (
+	AC3  = "ac-3"
+	EAC3 = "ec-3"
+	FLAC = "fLaC"
+	Opus = "Opus"
+	ALAC = "alac"
+)
+
+// MPEG-4 audio object types
+const (
+	AACMain   = 1
+	AACLC     = 2
+	AACLTP    = 4
+	HEAAC     = 5
+	HEAACv2   = 29
+	MP3Object = 34
+)
+
+// MP4A Returns the codec string of MPEG-4 audio with the given object type, e.g. "mp4a.40.2"
+func MP4A(objectType int) string {
+	return fmt.Sprintf("mp4a.40.%d", objectType)
+}
+
+// H.264 profile_idc values
+const (
+	AVCBaseline = 66
+	AVCMain     = 77
+	AVCHigh     = 100
+	AVCHigh10   = 110
+	AVCHigh422  = 122
+	AVCHigh444  = 244
+)
+
+// AVC Returns the codec string of an H.264 stream, e.g. "avc1.640028" for High@4.0.
+// `constraintFlags` is the byte holding constraint_set0_flag to constraint_set5_flag,
+// and `levelIDC` is 10 times the level.
+func AVC(profileIDC, constraintFlags, levelIDC int) string {
+	return fmt.Sprintf("avc1.%02x%02x%02x", profileIDC, constraintFlags, levelIDC)
+}
+
+// AVCLevel Parses an H.264 level such as "3.1", "31" or "1b" into its level_idc
+func AVCLevel(level string) (int, error) {
+	if level == "1b" {
+		return 9, nil
+	}
+	return parseLevel(level, 10)
+}
+
+// HEVC Returns the codec string of an HEVC stream with the `hvc1` sample entry,
+// e.g. "hvc1.2.4.L123.B0" for Main 10, Main tier, level 4.1.
+// `compatibility` has its bit `j` set if the stream is compatible with profile `j`,
+// `levelIDC` is 30 times the level, and `constraints` are the constraint indicator
+// bytes, of which trailing zeros are omitted.
+func HEVC(profileIDC int, compatibility uint32, highTier bool, levelIDC int, constraints ...byte) string {
+	tier := "L"
+	if highTier {
+		tier = "H"
+	}
+	codec := fmt.Sprintf("hvc1.%d.%X.%s%d", profileIDC, compatibility, tier, levelIDC)
+	for len(constraints) > 0 && constraints[len(constraints)-1] == 0 {
+		constraints = constraints[:len(constraints)-1]
+	}
+	for _, b := range constraints {
+		codec += fmt.Sprintf(".%X", b)
+	}
+	return codec
+}
+
+// HEVC profile_idc values, and the compatibility flags streams usually have
+const (
+	HEVCMain   = 1
+	HEVCMain10 = 2
+	HEVCRExt   = 4
+
+	HEVCMainCompatibility   uint32 = 1<<HEVCMain | 1<<HEVCMain10
+	HEVCMain10Compatibility uint32 = 1 << HEVCMain10
+	HEVCRExtCompatibility   uint32 = 1 << HEVCRExt
+)
+
+// HEVCProgressiveConstraints The constraint byte of progressive, non-packed, frame-only streams
+const HEVCProgressiveConstraints byte = 0xB0
+
+// HEVCLevel Parses an HEVC level such as "4.1" into its level_idc
+func HEVCLevel(level string) (int, error) {
+	return parseLevel(level, 30)
+}
+
+// AV1 profiles
+const (
+	AV1Main         = 0
+	AV1High         = 1
+	AV1Professional = 2
+)
+
+// AV1 Returns the codec string of an AV1 stream, e.g. "av01.0.08M.10"
+// for Main profile, level 4.0, Main tier, 10 bits.
+// `seqLevelIdx` is the index of the level: 4 * (major - 2) + minor.
+func AV1(profile, seqLevelIdx int, highTier bool, bitDepth int) string {
+	tier := "M"
+	if highTier {
+		tier = "H"
+	}
+	return fmt.Sprintf("av01.%d.%02d%s.%02d", profile, seqLevelIdx, tier, bitDepth)
+}
+
+// AV1Level Parses an AV1 level such as "4.0" into its seq_level_idx
+func AV1Level(level string) (int, error) {
+	parts := strings.SplitN(level, ".", 2)
+	major, err := strconv.Atoi(parts[0])
+	minor := 0
+	if err == nil && len(parts) == 2 {
+		minor, err = strconv.Atoi(parts[1])
+	}
+	if err != nil || major < 2 || minor < 0 || minor > 3 {
+		return 0, fmt.Errorf("invalid AV1 level %q", level)
+	}
+	return 4*(major-2) + minor, nil
+}
+
+// Dolby Vision signal compatibility IDs, of the base layer
+const (
+	DVCompatibilityNone  = 0 // Not backward compatible, e.g. profile 5
+	DVCompatibilityHDR10 = 1
+	DVCompatibilitySDR   = 2
+	DVCompatibilityHLG   = 4
+)
+
+// dvBrands The compatibility brands of Dolby Vision base layers, by signal compatibility ID
+var dvBrands = map[int]string{
+	DVCompatibilityHDR10: "db1p",
+	DVCompatibilitySDR:   "db2g",
+	DVCompatibilityHLG:   "db4h",
+}
+
+// DolbyVision Returns the codec string of a Dolby Vision stream, e.g. "dvh1.05.06"
+// for profile 5, level 6. Returns "" for unknown profiles.
+func DolbyVision(profile, level int) string {
+	var entry string
+	switch profile {
+	case 4, 5, 7, 8:
+		entry = "dvh1"
+	case 9:
+		entry = "dva1"
+	case 10:
+		entry = "dav1"
+	default:
+		return ""
+	}
+	return fmt.Sprintf("%s.%02d.%02d", entry, profile, level)
+}
+
+// DolbyVisionSupplemental Returns the SUPPLEMENTAL-CODECS entry of a Dolby Vision stream
+// whose base layer has the signal compatibility `compatibilityID`, e.g. "dvh1.08.06/db1p".
+// Returns "" if the base layer is not backward compatible.
+func DolbyVisionSupplemental(profile, level, compatibilityID int) string {
+	brand, codec := dvBrands[compatibilityID], DolbyVision(profile, level)
+	if len(brand) == 0 || len(codec) == 0 {
+		return ""
+	}
+	return codec + "/" + brand
+}
+
+// parseLevel Parses a level such as "4.1", or an integer already multiplied by `factor` such as "41"
+func parseLevel(level string, factor float64) (int, error) {
+	if !strings.Contains(level, ".") {
+		if value, err := strconv.Atoi(level); err == nil && float64(value) >= factor {
+			return value, nil
+		}
+	}
+	value, err := strconv.ParseFloat(level, 64)
+	if err != nil || value <= 0 {
+		return 0, fmt.Errorf("invalid level %q", level)
+	}
+	return int(value*factor + 0.5), nil
+}
+
+// Join Returns the value of a CODECS attribute listing `codecs`, without duplicates.
+func Join(codecs ...string) string {
+	var unique []string
+	for _, codec := range codecs {
+		found := false
+		for _, u := range unique {
+			found = found || u == codec
+		}
+		if !found {
+			unique = append(unique, codec)
+		}
+	}
+	return strings.Join(unique, ",")
+}
+
+// videoPrefixes The sample entries of video codecs
+var videoPrefixes = []string{"avc1.", "avc3.", "hvc1.", "hev1.", "dvh1.", "dvhe.", "dva1.", "dvav.", "dav1.", "av01.", "vp09."}
+
+// Video Returns the video codecs of a CODECS attribute value,
+// e.g. "avc1.640028" for "avc1.640028,mp4a.40.2"
+func Video(list string) string {
+	var video []string
+	for _, codec := range strings.Split(list, ",") {
+		for _, prefix := range videoPrefixes {
+			if strings.HasPrefix(strings.TrimSpace(codec), prefix) {
+				video = append(video, strings.TrimSpace(codec))
+				break
+			}
+		}
+	}
+	return strings.Join(video, ",")
+}
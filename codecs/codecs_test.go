@@ -0,0 +1,71 @@
+package codecs
+
+import (
+	"testing"
+
+	"github.com/allezxandre/go-hls-encoder/probe"
+)
+
+func TestFromProbe(t *testing.T) {
+	for _, expected := range []struct {
+		stream probe.ProbeStream
+		codecs string
+	}{
+		{probe.ProbeStream{CodecName: "h264", Profile: "High", Level: 40}, "avc1.640028"},
+		{probe.ProbeStream{CodecName: "h264", Profile: "Constrained Baseline", Level: 30}, "avc1.42c01e"},
+		{probe.ProbeStream{CodecName: "hevc", Profile: "Main 10", Level: 123}, "hvc1.2.4.L123.B0"},
+		{probe.ProbeStream{CodecName: "hevc", Profile: "Main", Level: 150}, "hvc1.1.6.L150.B0"},
+		{probe.ProbeStream{CodecName: "av1", Profile: "Main", Level: 8, PixFmt: "yuv420p10le"}, "av01.0.08M.10"},
+		{probe.ProbeStream{CodecName: "aac", Profile: "LC"}, "mp4a.40.2"},
+		{probe.ProbeStream{CodecName: "aac", Profile: "HE-AACv2"}, "mp4a.40.29"},
+		{probe.ProbeStream{CodecName: "eac3"}, "ec-3"},
+	} {
+		codecs, err := FromProbe(&expected.stream)
+		if err != nil || codecs != expected.codecs {
+			t.Errorf("Unexpected codecs for %s %s: %q instead of %q (%v)",
+				expected.stream.CodecName, expected.stream.Profile, codecs, expected.codecs, err)
+		}
+	}
+	if _, err := FromProbe(&probe.ProbeStream{CodecName: "h264", Profile: "High"}); err == nil {
+		t.Error("No error for an H.264 stream without level")
+	}
+}
+
+func TestFromEncoder(t *testing.T) {
+	for _, expected := range []struct{ encoder, profile, level, codecs string }{
+		{"libx264", "main", "3.1", "avc1.4d401f"},
+		{"libx264", "", "4.0", "avc1.640028"},
+		{"libx265", "main10", "4.1", "hvc1.2.4.L123.B0"},
+		{"libaom-av1", "", "5.1", "av01.0.13M.08"},
+		{"aac", "aac_he", "", "mp4a.40.5"},
+		{"ac3", "", "", "ac-3"},
+	} {
+		codecs, err := FromEncoder(expected.encoder, expected.profile, expected.level)
+		if err != nil || codecs != expected.codecs {
+			t.Errorf("Unexpected codecs for %s %s@%s: %q instead of %q (%v)",
+				expected.encoder, expected.profile, expected.level, codecs, expected.codecs, err)
+		}
+	}
+	if _, err := FromEncoder("libx264", "high", ""); err == nil {
+		t.Error("No error for an H.264 encode without level")
+	}
+}
+
+func TestJoin(t *testing.T) {
+	if joined := Join("avc1.640028", "mp4a.40.2", "ec-3", "mp4a.40.2"); joined != "avc1.640028,mp4a.40.2,ec-3" {
+		t.Error("Unexpected joined codecs:", joined)
+	}
+}
+
+func TestFromDOVI(t *testing.T) {
+	stream := &probe.ProbeStream{CodecName: "hevc", Profile: "Main 10", Level: 153, SideDataList: []probe.StreamSideData{{
+		SideDataType: probe.SideDataDOVIConfiguration, DVProfile: 8, DVLevel: 6, DVBLSignalCompatibilityID: DVCompatibilityHDR10,
+	}}}
+	if codec, supplemental, err := FromDOVI(stream); err != nil || codec != "hvc1.2.4.L153.B0" || supplemental != "dvh1.08.06/db1p" {
+		t.Errorf("Unexpected codecs of profile 8.1: %q %q (%v)", codec, supplemental, err)
+	}
+	stream.SideDataList[0].DVProfile, stream.SideDataList[0].DVBLSignalCompatibilityID = 5, DVCompatibilityNone
+	if codec, supplemental, err := FromDOVI(stream); err != nil || codec != "dvh1.05.06" || len(supplemental) > 0 {
+		t.Errorf("Unexpected codecs of profile 5: %q %q (%v)", codec, supplemental, err)
+	}
+}
@@ -0,0 +1,179 @@
+package codecs
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/allezxandre/go-hls-encoder/probe"
+)
+
+// avcProfiles profile_idc and constraint flags of the H.264 profiles,
+// by their name as written by ffprobe or given to x264, lowercase.
+var avcProfiles = map[string][2]int{
+	"baseline":              {AVCBaseline, 0xc0}, // x264 sets constraint_set0 and constraint_set1: constrained baseline
+	"constrained baseline":  {AVCBaseline, 0xc0},
+	"main":                  {AVCMain, 0x40},
+	"high":                  {AVCHigh, 0x00},
+	"high10":                {AVCHigh10, 0x00},
+	"high 10":               {AVCHigh10, 0x00},
+	"high422":               {AVCHigh422, 0x00},
+	"high 4:2:2":            {AVCHigh422, 0x00},
+	"high444":               {AVCHigh444, 0x00},
+	"high 4:4:4 predictive": {AVCHigh444, 0x00},
+}
+
+// hevcProfiles profile_idc and compatibility flags of the HEVC profiles,
+// by their name as written by ffprobe or given to x265, lowercase.
+var hevcProfiles = map[string]struct {
+	idc           int
+	compatibility uint32
+}{
+	"main":    {HEVCMain, HEVCMainCompatibility},
+	"main 10": {HEVCMain10, HEVCMain10Compatibility},
+	"main10":  {HEVCMain10, HEVCMain10Compatibility},
+	"rext":    {HEVCRExt, HEVCRExtCompatibility},
+}
+
+// av1Profiles seq_profile of the AV1 profiles, lowercase
+var av1Profiles = map[string]int{
+	"main":         AV1Main,
+	"high":         AV1High,
+	"professional": AV1Professional,
+}
+
+// aacProfiles Object types of the AAC profiles, as written by ffprobe
+// or given to ffmpeg's AAC encoders
+var aacProfiles = map[string]int{
+	"":          AACLC,
+	"lc":        AACLC,
+	"aac_low":   AACLC,
+	"main":      AACMain,
+	"aac_main":  AACMain,
+	"ltp":       AACLTP,
+	"aac_ltp":   AACLTP,
+	"he-aac":    HEAAC,
+	"aac_he":    HEAAC,
+	"he-aacv2":  HEAACv2,
+	"aac_he_v2": HEAACv2,
+}
+
+// FromProbe Returns the codec string of a stream, as it is when copied.
+// HEVC streams are assumed to be tagged `hvc1`, in the Main tier.
+func FromProbe(stream *probe.ProbeStream) (string, error) {
+	profile := strings.ToLower(stream.Profile)
+	switch stream.CodecName {
+	case "h264":
+		p, ok := avcProfiles[profile]
+		if !ok || stream.Level <= 0 {
+			break
+		}
+		return AVC(p[0], p[1], stream.Level), nil
+	case "hevc", "h265":
+		p, ok := hevcProfiles[profile]
+		if !ok || stream.Level <= 0 {
+			break
+		}
+		return HEVC(p.idc, p.compatibility, false, stream.Level, HEVCProgressiveConstraints), nil
+	case "av1":
+		p, ok := av1Profiles[profile]
+		if !ok || stream.Level < 0 {
+			break
+		}
+		return AV1(p, stream.Level, false, bitDepth(stream.PixFmt)), nil
+	case "aac":
+		if objectType, ok := aacProfiles[profile]; ok {
+			return MP4A(objectType), nil
+		}
+	default:
+		return FromEncoder(stream.CodecName, "", "")
+	}
+	return "", fmt.Errorf("%w: %s profile %q level %d", ErrUnknownCodec, stream.CodecName, stream.Profile, stream.Level)
+}
+
+// FromDOVI Returns the codec strings of a copied Dolby Vision stream: `codec` for its
+// CODECS attribute, and `supplemental` for its SUPPLEMENTAL-CODECS attribute.
+// Streams with a backward compatible base layer are announced with the codec of that layer,
+// e.g. "hvc1.2.4.L153.B0" with the supplemental "dvh1.08.06/db1p". Other streams only
+// have a Dolby Vision codec, e.g. "dvh1.05.06".
+func FromDOVI(stream *probe.ProbeStream) (codec, supplemental string, err error) {
+	dovi := stream.DOVIConfiguration()
+	if dovi == nil {
+		return "", "", fmt.Errorf("%w: stream %d has no Dolby Vision configuration", ErrUnknownCodec, stream.Index)
+	}
+	if supplemental = DolbyVisionSupplemental(dovi.DVProfile, dovi.DVLevel, dovi.DVBLSignalCompatibilityID); len(supplemental) > 0 {
+		codec, err = FromProbe(stream)
+		return codec, supplemental, err
+	}
+	if codec = DolbyVision(dovi.DVProfile, dovi.DVLevel); len(codec) == 0 {
+		return "", "", fmt.Errorf("%w: Dolby Vision profile %d", ErrUnknownCodec, dovi.DVProfile)
+	}
+	return codec, "", nil
+}
+
+// FromEncoder Returns the codec string of the output of an ffmpeg encoder,
+// configured with `profile` and `level` (empty for the defaults).
+// Video encoders that choose the level themselves require one.
+func FromEncoder(encoder, profile, level string) (string, error) {
+	profile = strings.ToLower(profile)
+	switch encoder {
+	case "libx264", "h264", "h264_nvenc", "h264_qsv", "h264_videotoolbox", "h264_vaapi":
+		if len(profile) == 0 {
+			profile = "high"
+		}
+		p, ok := avcProfiles[profile]
+		levelIDC, err := AVCLevel(level)
+		if !ok || err != nil {
+			break
+		}
+		return AVC(p[0], p[1], levelIDC), nil
+	case "libx265", "hevc", "hevc_nvenc", "hevc_qsv", "hevc_videotoolbox", "hevc_vaapi":
+		if len(profile) == 0 {
+			profile = "main"
+		}
+		p, ok := hevcProfiles[profile]
+		levelIDC, err := HEVCLevel(level)
+		if !ok || err != nil {
+			break
+		}
+		return HEVC(p.idc, p.compatibility, false, levelIDC, HEVCProgressiveConstraints), nil
+	case "libaom-av1", "libsvtav1", "librav1e", "av1":
+		if len(profile) == 0 {
+			profile = "main"
+		}
+		p, ok := av1Profiles[profile]
+		seqLevelIdx, err := AV1Level(level)
+		if !ok || err != nil {
+			break
+		}
+		return AV1(p, seqLevelIdx, false, 8), nil
+	case "aac", "libfdk_aac":
+		if objectType, ok := aacProfiles[profile]; ok {
+			return MP4A(objectType), nil
+		}
+	case "ac3":
+		return AC3, nil
+	case "eac3":
+		return EAC3, nil
+	case "mp3", "libmp3lame":
+		return MP4A(MP3Object), nil
+	case "flac":
+		return FLAC, nil
+	case "opus", "libopus":
+		return Opus, nil
+	case "alac":
+		return ALAC, nil
+	}
+	return "", fmt.Errorf("%w: encoder %s profile %q level %q", ErrUnknownCodec, encoder, profile, level)
+}
+
+// bitDepth Returns the bit depth of a pixel format such as "yuv420p10le"
+func bitDepth(pixFmt string) int {
+	switch {
+	case strings.Contains(pixFmt, "p10"):
+		return 10
+	case strings.Contains(pixFmt, "p12"):
+		return 12
+	default:
+		return 8
+	}
+}
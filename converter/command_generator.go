@@ -2,59 +2,145 @@ package converter
 
 import (
 	"fmt"
+	"math"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/allezxandre/go-hls-encoder/probe"
 	"github.com/allezxandre/go-hls-encoder/suggest"
 )
 
-func videoConversionArgs(variants []suggest.VideoVariant) (args []string) {
+// maxKeyframeInterval The maximum duration between two key frames,
+// as recommended by Apple's HLS authoring specification.
+const maxKeyframeInterval = 2 * time.Second
+
+// keyframeInterval Returns the interval between key frames: the largest
+// fraction of the segment duration that does not exceed `maxKeyframeInterval`.
+// Segment boundaries are then always on a key frame.
+func keyframeInterval(segmentDuration time.Duration) time.Duration {
+	if segmentDuration <= 0 {
+		return maxKeyframeInterval
+	}
+	keyframesPerSegment := int64(math.Ceil(float64(segmentDuration) / float64(maxKeyframeInterval)))
+	return time.Duration(int64(segmentDuration) / keyframesPerSegment)
+}
+
+// gopSize Returns the number of frames between two key frames, or 0 if
+// the frame rate is unknown.
+func gopSize(frameRate float64, interval time.Duration) int {
+	return int(math.Round(frameRate * interval.Seconds()))
+}
+
+// keyframeArgs Returns the arguments that force a key frame every `interval`
+// on output video stream `indexS`, so that all encoded variants
+// have their IDR frames at the exact same positions.
+func keyframeArgs(indexS string, frameRate string, interval time.Duration) (args []string) {
+	args = append(args,
+		// Key frames based on time, whatever the frame rate
+		"-force_key_frames:v:"+indexS, fmt.Sprintf("expr:gte(t,n_forced*%s)",
+			strconv.FormatFloat(interval.Seconds(), 'f', -1, 64)),
+		// No additional key frames on scene changes, and closed GOPs
+		"-sc_threshold:v:"+indexS, "0",
+		"-flags:v:"+indexS, "+cgop")
+	rate, err := probe.ParseRational(frameRate)
+	if gop := gopSize(rate, interval); err == nil && gop > 0 {
+		args = append(args,
+			"-g:v:"+indexS, strconv.Itoa(gop),
+			"-keyint_min:v:"+indexS, strconv.Itoa(gop))
+	}
+	return
+}
+
+// videoConversionArgs Returns the arguments encoding the video variants, with a key frame every `interval`
+func videoConversionArgs(variants []suggest.VideoVariant, interval time.Duration) (args []string) {
 	for outputIndex, variant := range variants {
 		indexS := strconv.Itoa(outputIndex)
 		// Map & codec
 		args = append(args, "-map", variant.MapInput,
-			"-c:v:"+indexS, variant.Codec, "-g", "60")
+			"-c:v:"+indexS, variant.Codec)
+		if variant.Codec != "copy" {
+			// Aligned key frames
+			args = append(args, keyframeArgs(indexS, variant.FrameRate, interval)...)
+		}
 		if variant.Codec == "libx264" {
 			// Additional X264 parameters
 			args = append(args,
 				"-bsf:v:"+indexS, "h264_mp4toannexb",
-				"-pix_fmt", "yuv420p")
+				"-pix_fmt:v:"+indexS, "yuv420p")
 		}
 		// -tag:v hvc1
-		if variant.AddHVC1Tag {
+		if len(variant.CodecTag) > 0 {
+			args = append(args, "-tag:v:"+indexS, variant.CodecTag)
+		} else if variant.AddHVC1Tag {
 			args = append(args, "-tag:v:"+indexS, "hvc1")
 		}
-		// Resolution
+		// Resolution, then tone mapping on the smaller frames
+		var filters []string
 		if variant.ResolutionHeight != nil {
-			args = append(args, "-filter:v:"+indexS,
-				fmt.Sprintf("scale=trunc(oh*a/2)*2:%d", *variant.ResolutionHeight))
+			filters = append(filters, fmt.Sprintf("scale=trunc(oh*a/2)*2:%d", *variant.ResolutionHeight))
+		}
+		if variant.ToneMap != nil {
+			filters = append(filters, toneMapFilter(*variant.ToneMap))
+			args = append(args,
+				"-color_primaries:v:"+indexS, "bt709",
+				"-color_trc:v:"+indexS, "bt709",
+				"-colorspace:v:"+indexS, "bt709")
+		}
+		if len(filters) > 0 {
+			args = append(args, "-filter:v:"+indexS, strings.Join(filters, ","))
 		}
 		// Bitrate
 		if variant.Bitrate != nil {
 			args = append(args, "-b:v:"+indexS, *variant.Bitrate)
 		}
+		if variant.MaxBitrate != nil {
+			args = append(args, "-maxrate:v:"+indexS, *variant.MaxBitrate)
+		}
+		if variant.BufferSize != nil {
+			args = append(args, "-bufsize:v:"+indexS, *variant.BufferSize)
+		}
 		// CRF
 		if variant.CRF != nil {
-			args = append(args, "-crf", strconv.Itoa(*variant.CRF))
+			args = append(args, "-crf:v:"+indexS, strconv.Itoa(*variant.CRF))
 		}
 		// Profile & Level
 		if variant.Profile != nil && variant.Level != nil {
 			args = append(args,
 				"-profile:v:"+indexS, *variant.Profile,
-				"-level", *variant.Level,
+				"-level:v:"+indexS, *variant.Level,
 			)
 		}
 	}
 	return
 }
 
+// toneMapFilter Returns the filters converting HDR frames to SDR BT.709 with zscale and tonemap:
+// the source is linearized, tone-mapped in BT.709 primaries, then converted back to 8-bit YUV
+func toneMapFilter(t suggest.ToneMapping) string {
+	return fmt.Sprintf("zscale=tin=%s:min=bt2020nc:pin=bt2020:rin=tv:t=linear:npl=100,format=gbrpf32le,"+
+		"zscale=p=bt709,tonemap=tonemap=%s:desat=0,zscale=t=bt709:m=bt709:r=tv,format=yuv420p",
+		t.Transfer, t.Operator)
+}
+
+// hdrArgs Returns the arguments keeping the HDR metadata of copied streams:
+// the MP4 muxer only writes the mastering display, content light level and
+// Dolby Vision boxes in unofficial mode
+func hdrArgs(variants []suggest.VideoVariant) []string {
+	for _, variant := range variants {
+		if variant.Codec == "copy" && len(variant.DynamicRange) > 0 && variant.DynamicRange != suggest.SDR {
+			return []string{"-strict", "unofficial"}
+		}
+	}
+	return nil
+}
+
 func audioConversionArgs(variants []suggest.AudioVariant) (args []string) {
 	for outputIndex, variant := range variants {
 		indexS := strconv.Itoa(outputIndex)
 		// Map & codec
 		args = append(args, "-map", variant.MapInput,
-			"-c:a:"+indexS, variant.Codec,
-			"-g", "60")
+			"-c:a:"+indexS, variant.Codec)
 		if variant.Codec != "copy" {
 			// From: https://stackoverflow.com/a/63995029/3997690
 			args = append(args, "-af", "aresample=async=1:first_pts=0")
@@ -0,0 +1,139 @@
+package converter
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/allezxandre/go-hls-encoder/encryption"
+	"github.com/allezxandre/go-hls-encoder/suggest"
+)
+
+func TestKeyframeInterval(t *testing.T) {
+	for segmentDuration, expected := range map[time.Duration]time.Duration{
+		6 * time.Second:         2 * time.Second,
+		4 * time.Second:         2 * time.Second,
+		5 * time.Second:         5 * time.Second / 3,
+		1500 * time.Millisecond: 1500 * time.Millisecond,
+	} {
+		if interval := keyframeInterval(segmentDuration); interval != expected {
+			t.Errorf("Unexpected key frame interval for %v: %v instead of %v", segmentDuration, interval, expected)
+		}
+	}
+}
+
+func TestLowLatencyKeyframeInterval(t *testing.T) {
+	for _, c := range []struct{ segment, part, expected time.Duration }{
+		{6 * time.Second, time.Second, 2 * time.Second},
+		{5 * time.Second, time.Second, time.Second},
+		{4 * time.Second, 500 * time.Millisecond, 2 * time.Second},
+		{6 * time.Second, 800 * time.Millisecond, 1600 * time.Millisecond},
+	} {
+		if interval := lowLatencyKeyframeInterval(c.segment, c.part); interval != c.expected {
+			t.Errorf("Unexpected key frame interval for parts of %v in %v: %v instead of %v", c.part, c.segment, interval, c.expected)
+		}
+	}
+}
+
+func TestGOPSize(t *testing.T) {
+	for frameRate, expected := range map[float64]int{
+		24000.0 / 1001: 48,
+		25:             50,
+		50:             100,
+		30000.0 / 1001: 60,
+		0:              0,
+	} {
+		if gop := gopSize(frameRate, 2*time.Second); gop != expected {
+			t.Errorf("Unexpected GOP size for %v fps: %d instead of %d", frameRate, gop, expected)
+		}
+	}
+}
+
+func TestHDRArgs(t *testing.T) {
+	variants := []suggest.VideoVariant{{Codec: "copy", DynamicRange: suggest.HDR10}, {Codec: "libx264", DynamicRange: suggest.HDR10}}
+	if args := hdrArgs(variants); len(args) != 2 || args[1] != "unofficial" {
+		t.Error("HDR metadata of the copied stream not kept:", args)
+	}
+	if args := hdrArgs(variants[1:]); len(args) > 0 {
+		t.Error("Unexpected arguments without copied HDR stream:", args)
+	}
+}
+
+func TestToneMapArgs(t *testing.T) {
+	height := 1080
+	args := videoConversionArgs([]suggest.VideoVariant{{
+		MapInput: "0:0", Codec: "libx264", ResolutionHeight: &height,
+		ToneMap: &suggest.ToneMapping{Transfer: "arib-std-b67", Operator: "hable"},
+	}}, keyframeInterval(6*time.Second))
+	joined := strings.Join(args, " ")
+	if !strings.Contains(joined, "-filter:v:0 scale=trunc(oh*a/2)*2:1080,zscale=tin=arib-std-b67:") ||
+		!strings.Contains(joined, "tonemap=tonemap=hable") || !strings.Contains(joined, "-color_trc:v:0 bt709") {
+		t.Error("Unexpected tone-mapping arguments:", joined)
+	}
+}
+
+func TestHLSArguments(t *testing.T) {
+	for _, c := range []struct {
+		options          suggest.PackagingOptions
+		expected, absent []string
+	}{
+		{suggest.PackagingOptions{}, []string{"-hls_time 6 ", "-hls_list_size 0 ", "-hls_segment_type fmp4 ",
+			"-hls_playlist_type vod", "-movflags +frag_keyframe"}, []string{"-hls_flags", "-hls_fmp4_init_filename"}},
+		{suggest.PackagingOptions{SegmentContainer: suggest.MPEGTSSegments, SegmentDuration: 4},
+			[]string{"-hls_time 4 ", "-hls_segment_type mpegts "}, []string{"-movflags", "-hls_fmp4_init_filename"}},
+		{suggest.PackagingOptions{InitFilename: "init_%v.mp4"}, []string{"-hls_fmp4_init_filename init_%v.mp4"}, nil},
+		{suggest.PackagingOptions{SegmentFilename: "video_%v_%d.m4s"}, []string{"-hls_segment_filename out/video_%v_%d.m4s"}, nil},
+		{suggest.PackagingOptions{SingleFile: true, IndependentSegments: true},
+			[]string{"-hls_flags +single_file+independent_segments"}, nil},
+		{suggest.PackagingOptions{PlaylistType: suggest.EventPlaylist}, []string{"-hls_playlist_type event"}, []string{"vod"}},
+	} {
+		joined := strings.Join(hlsArguments(c.options, "out", false), " ")
+		for _, expected := range c.expected {
+			if !strings.Contains(joined, expected) {
+				t.Errorf("Missing %q in the HLS arguments of %+v: %s", expected, c.options, joined)
+			}
+		}
+		for _, absent := range c.absent {
+			if strings.Contains(joined, absent) {
+				t.Errorf("Unexpected %q in the HLS arguments of %+v: %s", absent, c.options, joined)
+			}
+		}
+	}
+}
+
+func TestLiveHLSArguments(t *testing.T) {
+	event := strings.Join(hlsArguments(suggest.PackagingOptions{PlaylistType: suggest.EventPlaylist}, "out", true), " ")
+	if !strings.Contains(event, "-hls_playlist_type event") || !strings.Contains(event, "-hls_flags +omit_endlist") ||
+		strings.Contains(event, "+delete_segments") {
+		t.Error("Unexpected HLS arguments of a live event:", event)
+	}
+	window := strings.Join(hlsArguments(suggest.PackagingOptions{PlaylistType: suggest.LivePlaylist, ListSize: 5}, "out", true), " ")
+	if !strings.Contains(window, "-hls_list_size 5 ") || !strings.Contains(window, "-hls_flags +omit_endlist+delete_segments") ||
+		strings.Contains(window, "-hls_playlist_type") {
+		t.Error("Unexpected HLS arguments of a live sliding window:", window)
+	}
+}
+
+func TestIFrameWatchersHLSArguments(t *testing.T) {
+	defer func(generate bool) { GENERATE_IPLAYLIST = generate }(GENERATE_IPLAYLIST)
+	vod := suggest.PackagingOptions{PlaylistType: suggest.VODPlaylist}
+	GENERATE_IPLAYLIST = false
+	if args := strings.Join(hlsArguments(vod, "out", false), " "); strings.Contains(args, "+temp_file") {
+		t.Error("Unexpected temporary files without I-frame watchers:", args)
+	}
+	GENERATE_IPLAYLIST = true
+	if args := strings.Join(hlsArguments(vod, "out", false), " "); !strings.Contains(args, "-hls_flags +temp_file") {
+		t.Error("The I-frame watchers need temporary files:", args)
+	}
+	vod.Encryption = &suggest.EncryptionOptions{Method: encryption.SampleAES}
+	if args := strings.Join(hlsArguments(vod, "out", false), " "); strings.Contains(args, "+temp_file") {
+		t.Error("Unexpected temporary files without I-frame watchers of encrypted segments:", args)
+	}
+	// Encrypted packages would lose their I-FRAME-ONLY playlists
+	_, err := LaunchConversionContext(context.Background(), "out", "master", "stream", vod,
+		[]suggest.VideoVariant{{MapInput: "0:0", Codec: "copy"}}, nil, nil, "movie.mkv")
+	if err == nil || !strings.Contains(err.Error(), "GENERATE_IPLAYLIST") {
+		t.Error("Encryption with I-FRAME-ONLY playlists should be rejected:", err)
+	}
+}
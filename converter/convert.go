@@ -1,6 +1,7 @@
 package converter
 
 import (
+	"context"
 	"fmt"
 	"github.com/allezxandre/go-hls-encoder/iframe-playlist-generator"
 	"github.com/allezxandre/go-hls-encoder/suggest"
@@ -10,7 +11,9 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
+	"sync"
 	"syscall"
+	"time"
 )
 
 var GENERATE_IPLAYLIST = false
@@ -21,28 +24,61 @@ type Conversion struct {
 	mainCommand                *exec.Cmd
 	SubtitleConversionCommands []SubtitleVariantConversion
 	OutputDirectory            string
+
+	ctx       context.Context
+	packaging suggest.PackagingOptions
+	master    masterPlaylist
+	encoded   bool // Whether the main command succeeded. Set before `wg` is done
+	startTime time.Time
+	wg        sync.WaitGroup // Counts the steps still running
+	packagers sync.WaitGroup // Counts the low-latency packagers still running
+	exited    chan struct{}  // Closed once the main command exited for good
+	watchers  sync.WaitGroup // Counts the I-FRAME-ONLY playlist watchers still running
+	ended     chan struct{}  // Closed once the media playlists are complete
+	live      bool           // Whether an input is live: the main command is restarted when it drops
+	mu        sync.Mutex     // Protects `steps`, `stopped`, `watched` and the running commands
+	steps     []StepResult
+	stopped   bool // Whether Stop was called
+	watched   []iframe_playlist_generator.IFramePlaylist
+	stepsDone chan struct{} // Closed once all steps are done
+	duration  time.Duration // Duration of the inputs, to compute progress. 0 if unknown
+	progress  chan Progress
+	result    *Result
+	done      chan struct{} // Closed once all steps are done and `result` is set
 }
 
+// stopGracePeriod How long FFMPEG commands have to exit after being interrupted
+// before they get killed.
+const stopGracePeriod = 5 * time.Second
+
 // Applies function f to all commands related to the conversion
-func (c Conversion) do(f func(cmd *exec.Cmd)) {
-	f(c.mainCommand)
+// that were started
+func (c *Conversion) do(f func(cmd *exec.Cmd)) {
+	c.mu.Lock()
+	commands := []*exec.Cmd{c.mainCommand}
 	for _, subConv := range c.SubtitleConversionCommands {
-		f(subConv.commands.EncoderCommand)
+		commands = append(commands, subConv.commands.EncoderCommand)
+	}
+	c.mu.Unlock()
+	for _, cmd := range commands {
+		if cmd != nil && cmd.Process != nil {
+			f(cmd)
+		}
 	}
 }
 
-func (c Conversion) Signal(sig syscall.Signal) {
+func (c *Conversion) Signal(sig syscall.Signal) {
 	c.do(func(cmd *exec.Cmd) {
 		cmd.Process.Signal(sig)
 	})
 }
 
-func (c Conversion) SigInt() {
+func (c *Conversion) SigInt() {
 	c.Signal(syscall.SIGINT)
 }
 
 // Exit Kills all remaining ongoing conversion
-func (c Conversion) Exit() {
+func (c *Conversion) Exit() {
 	c.do(func(cmd *exec.Cmd) {
 		if cmd.ProcessState == nil || !cmd.ProcessState.Exited() {
 			// Process is not done
@@ -51,42 +87,247 @@ func (c Conversion) Exit() {
 	})
 }
 
-var hlsSettings = []string{
-	"-f", "hls",
-	"-hls_flags", "+split_by_time",
-	"-hls_time", "6",
-	"-hls_list_size", "0",
-	//"-hls_playlist_type", "event",
-	"-hls_segment_type", "fmp4",
-	"-movflags", "+frag_keyframe",
-	//"-flags", "+cgop",
-	"-g", "60",
-	"-master_pl_name", FFMPEG_MASTER_PLAYLIST,
-	//"-hls_flags", "+single_file",
-	//"-hls_flags", "+independent_segments",
+// Done Returns a channel that is closed once every step of the conversion is over:
+// the main ffmpeg command, the subtitle conversions and their segmenters,
+// and the I-FRAME enrichment.
+func (c *Conversion) Done() <-chan struct{} {
+	return c.done
+}
+
+// Wait Blocks until the conversion is over and returns its result.
+// The error is a ConversionError if any step failed.
+func (c *Conversion) Wait() (*Result, error) {
+	<-c.done
+	return c.result, c.result.Err()
+}
+
+// record Saves the outcome of a step
+func (c *Conversion) record(step Step, name string, err error) {
+	if err != nil {
+		log.Printf("Conversion step %q %v failed: %v\n", step, name, err)
+	}
+	c.mu.Lock()
+	c.steps = append(c.steps, StepResult{Step: step, Name: name, Err: err})
+	c.mu.Unlock()
+}
+
+// stopOnCancel Interrupts all commands if the context of the conversion
+// is done before the conversion, and kills them if they are still running
+// after `stopGracePeriod`.
+func (c *Conversion) stopOnCancel() {
+	select {
+	case <-c.stepsDone:
+		return
+	case <-c.ctx.Done():
+	}
+	log.Println("Conversion interrupted:", c.ctx.Err())
+	c.SigInt()
+	select {
+	case <-c.stepsDone:
+	case <-time.After(stopGracePeriod):
+		c.do(func(cmd *exec.Cmd) {
+			cmd.Process.Kill()
+		})
+	}
+}
+
+// finish Waits for all steps to be over, writes the master playlist (and DASH manifest) if the encode succeeded,
+// then builds the result and closes `done`.
+// If the conversion was interrupted, partial outputs are removed.
+func (c *Conversion) finish(masterFilename string) {
+	c.wg.Wait()
+	close(c.stepsDone)
+	if c.encoded && c.ctx.Err() == nil {
+		failedSubtitles := c.failedSubtitles()
+		err := c.master.write(failedSubtitles)
+		if err != nil {
+			log.Println("An error happened writing the master playlist:", err)
+		}
+		c.record(MasterStep, "", err)
+		if c.packaging.DASH {
+			err = c.master.writeDASH(failedSubtitles)
+			if err != nil {
+				log.Println("An error happened writing the DASH manifest:", err)
+			}
+			c.record(DASHStep, "", err)
+		}
+	}
+	result := &Result{
+		OutputDirectory: c.OutputDirectory,
+		MasterPlaylist:  filepath.Base(masterFilename),
+	}
+	if err := result.collectOutputs(c.startTime); err != nil {
+		log.Println("Cannot list conversion outputs:", err)
+	}
+	if err := c.ctx.Err(); err != nil {
+		result.Interrupted = err
+		result.removePartialOutputs()
+	}
+	c.mu.Lock()
+	result.Steps = append(result.Steps, c.steps...)
+	c.mu.Unlock()
+	c.result = result
+	close(c.progress)
+	close(c.done)
+}
+
+// failedSubtitles Returns the names of the subtitle variants whose conversion failed
+func (c *Conversion) failedSubtitles() map[string]bool {
+	failed := map[string]bool{}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, s := range c.steps {
+		if s.Err != nil && (s.Step == SubtitleStep || s.Step == SegmentStep) {
+			failed[s.Name] = true
+		}
+	}
+	return failed
+}
+
+// hlsArguments Returns the arguments of the HLS muxer for `options`,
+// writing segments to `outputDir`. With a `live` input, playlists are never ended by ffmpeg,
+// which is restarted when the input drops, and segments that leave sliding-window playlists are removed.
+func hlsArguments(options suggest.PackagingOptions, outputDir string, live bool) []string {
+	options = options.WithDefaults()
+	var flags []string
+	segmentDuration, listSize, segmentFilename := options.SegmentDuration, options.ListSize, options.SegmentFilename
+	if options.LowLatency != nil {
+		// ffmpeg writes parts as segments, cut at the exact part duration, and the packagers join them
+		segmentDuration, listSize, segmentFilename = options.LowLatency.PartDuration, 0, options.LowLatency.PartFilename
+		if options.ListSize > 0 {
+			// The parts of the segments in the window are kept, and the ones of a segment more for the packagers
+			partsPerSegment := int(options.SegmentDuration/options.LowLatency.PartDuration + 0.5)
+			listSize = (options.ListSize + 1) * partsPerSegment
+		}
+		flags = append(flags, "+split_by_time", "+temp_file")
+	} else if iframeWatchers(options) {
+		// The I-frame watchers read the playlists and segments while they are written: ffmpeg renames them once complete
+		flags = append(flags, "+temp_file")
+	}
+	if live {
+		flags = append(flags, "+omit_endlist")
+		if options.ListSize > 0 {
+			flags = append(flags, "+delete_segments")
+		}
+	}
+	args := []string{
+		"-f", "hls",
+		"-hls_time", strconv.FormatFloat(segmentDuration, 'f', -1, 64),
+		"-hls_list_size", strconv.Itoa(listSize),
+		"-hls_segment_type", string(options.SegmentContainer),
+		"-master_pl_name", FFMPEG_MASTER_PLAYLIST,
+	}
+	switch options.PlaylistType {
+	case suggest.VODPlaylist:
+		args = append(args, "-hls_playlist_type", "vod")
+	case suggest.EventPlaylist:
+		args = append(args, "-hls_playlist_type", "event")
+	}
+	if options.SegmentContainer == suggest.FMP4Segments {
+		args = append(args, "-movflags", "+frag_keyframe")
+		if len(options.InitFilename) > 0 {
+			args = append(args, "-hls_fmp4_init_filename", options.InitFilename)
+		}
+	}
+	if len(segmentFilename) > 0 {
+		args = append(args, "-hls_segment_filename", filepath.Join(outputDir, segmentFilename))
+	}
+	if options.SingleFile {
+		flags = append(flags, "+single_file")
+	}
+	if options.IndependentSegments {
+		flags = append(flags, "+independent_segments")
+	}
+	if len(flags) > 0 {
+		args = append(args, "-hls_flags", strings.Join(flags, ""))
+	}
+	return args
 }
 
 func ffmpegDefaultArguments() []string {
 	return []string{"-hide_banner", "-y", "-stats", "-loglevel", "warning"}
 }
 
+// LaunchConversion Starts the conversion of `inputs` to HLS in `outputDir`.
+// It returns as soon as all commands are started: use `Wait` or `Done`
+// on the returned Conversion to know when it is over.
 func LaunchConversion(outputDir, masterPlaylistName, streamPlaylistName string,
 	videoVariants []suggest.VideoVariant, audioVariants []suggest.AudioVariant, subtitleVariantsCh <-chan []suggest.SubtitleVariant,
 	inputs ...string) (*Conversion, error) {
+	return LaunchConversionContext(context.Background(), outputDir, masterPlaylistName, streamPlaylistName,
+		suggest.DefaultPackagingOptions(), videoVariants, audioVariants, subtitleVariantsCh, inputs...)
+}
+
+// LaunchPlan Validates `plan`, then starts its conversion in `outputDir`.
+// Plans of live inputs cannot have subtitle variants, see ErrLiveSubtitles.
+// See LaunchConversionContext.
+func LaunchPlan(ctx context.Context, outputDir string, plan *suggest.EncodingPlan) (*Conversion, error) {
+	if err := plan.Validate(); err != nil {
+		return nil, err
+	}
+	for _, input := range plan.Inputs {
+		if IsLiveInput(input) && len(plan.Subtitles) > 0 {
+			return nil, fmt.Errorf("%v: remove the subtitle variants of the plan", ErrLiveSubtitles)
+		}
+	}
+	subtitleVariantsCh := make(chan []suggest.SubtitleVariant, 1)
+	subtitleVariantsCh <- plan.Subtitles
+	return LaunchConversionContext(ctx, outputDir, plan.HLS.MasterPlaylistName, plan.HLS.StreamPlaylistName,
+		plan.Packaging, plan.Video, plan.Audio, subtitleVariantsCh, plan.Inputs...)
+}
+
+// LaunchConversionContext Same as LaunchConversion, but the conversion is stopped
+// when `ctx` is done: every FFMPEG command is interrupted (then killed if it doesn't exit),
+// the WebVTT segmenters and the I-FRAME generation are stopped, and the partial
+// playlists and segments are removed. Log files are kept.
+// Segmenting is configured by `packaging`.
+func LaunchConversionContext(ctx context.Context, outputDir, masterPlaylistName, streamPlaylistName string,
+	packaging suggest.PackagingOptions,
+	videoVariants []suggest.VideoVariant, audioVariants []suggest.AudioVariant, subtitleVariantsCh <-chan []suggest.SubtitleVariant,
+	inputs ...string) (*Conversion, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if err := packaging.Validate(); err != nil {
+		return nil, err
+	}
+	if GENERATE_IPLAYLIST && packaging.Encryption != nil {
+		// The byte ranges of the I-frames change when the segments are encrypted
+		return nil, fmt.Errorf("I-FRAME-ONLY playlists cannot be generated for encrypted segments: unset GENERATE_IPLAYLIST")
+	}
+	packaging = packaging.WithDefaults()
 
 	// Generate FFMPEG command
 	args := ffmpegDefaultArguments()
 	// ... add inputs
+	live := false
 	for _, input := range inputs {
-		args = append(args, "-i", input)
+		args = append(args, inputArguments(input)...)
+		live = live || IsLiveInput(input)
+	}
+	if live && packaging.PlaylistType == suggest.VODPlaylist {
+		return nil, fmt.Errorf("live inputs need %q or %q playlists", suggest.EventPlaylist, suggest.LivePlaylist)
+	}
+	if packaging.LowLatency != nil {
+		for _, variant := range videoVariants {
+			if variant.Codec == "copy" {
+				// Its keyframes follow the source: segments would last longer than the target duration
+				return nil, fmt.Errorf("low-latency playlists cannot copy video variant %q", variant.MapInput)
+			}
+		}
 	}
 	// Additional subtitle inputs will be added later
 
 	// ... add video and audio variants
-	args = append(args, videoConversionArgs(videoVariants)...)
+	interval := keyframeInterval(packaging.TargetDuration())
+	if packaging.LowLatency != nil {
+		interval = lowLatencyKeyframeInterval(packaging.TargetDuration(), packaging.LowLatency.PartTarget())
+	}
+	args = append(args, videoConversionArgs(videoVariants, interval)...)
+	args = append(args, hdrArgs(videoVariants)...)
 	args = append(args, audioConversionArgs(audioVariants)...)
 	// ... add HLS options
-	args = append(args, hlsSettings...)
+	args = append(args, hlsArguments(packaging, outputDir, live)...)
 	// ... add HLS variants mapping
 	args = append(args, "-var_stream_map", variantsMapArg(videoVariants, audioVariants))
 
@@ -96,100 +337,105 @@ func LaunchConversion(outputDir, masterPlaylistName, streamPlaylistName string,
 		return nil, err // FIXME: return better error
 	}
 	outputFile := filepath.Join(outputDir, streamPlaylistName+"_%v.m3u8")
+	if packaging.LowLatency != nil {
+		outputFile = filepath.Join(outputDir, partsPlaylistFilename(streamPlaylistName+"_%v.m3u8"))
+	}
 
 	// HLS options
 	args = append(args, "-max_muxing_queue_size", "1024", outputFile)
 
+	conversion := &Conversion{
+		StreamURLs:      inputs,
+		OutputDirectory: outputDir,
+		ctx:             ctx,
+		packaging:       packaging,
+		startTime:       time.Now(),
+		stepsDone:       make(chan struct{}),
+		exited:          make(chan struct{}),
+		ended:           make(chan struct{}),
+		live:            live,
+		progress:        make(chan Progress, progressBufferSize),
+		done:            make(chan struct{}),
+	}
+	if !live {
+		conversion.duration = inputsDuration(inputs...)
+	}
+
 	// Start video and audio conversion
-	masterCh := make(chan string)
-	cmd, err := callFFmpeg(filepath.Join(outputDir, "conversion.log"), args, masterCh)
+	cmd, logFile, err := conversion.callFFmpeg(filepath.Join(outputDir, "conversion.log"), args)
 	if err != nil {
-		close(masterCh)
 		return nil, err
 	}
+	conversion.mainCommand = cmd
+	if packaging.LowLatency != nil {
+		conversion.startPackagers(streamPlaylistName, len(videoVariants)+len(audioVariants))
+	}
 
 	// Start subtitles conversion
-	subtitleVariants := <-subtitleVariantsCh
-	convertedSubtitles := callSubtitleConversions(subtitleVariants, outputDir)
+	var subtitleVariants []suggest.SubtitleVariant
+	select {
+	case subtitleVariants = <-subtitleVariantsCh:
+	case <-ctx.Done():
+		// No need to start subtitles conversions
+	}
+	convertedSubtitles := conversion.callSubtitleConversions(subtitleVariants, outputDir)
+	conversion.SubtitleConversionCommands = convertedSubtitles
 
-	// Generate master playlist
+	// The master playlist is written once the encode is done, with measured bandwidths
 	masterFilename := filepath.Join(outputDir, masterPlaylistName+".m3u8")
-	masterCh <- masterFilename
-	close(masterCh)
-	// ... open file
-	f, err := os.OpenFile(masterFilename, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
-	if err != nil {
-		panic(err) // FIXME
-	}
-	// .. write
-	f.WriteString("#EXTM3U\n" +
-		"#EXT-X-VERSION:7\n")
-	streamIndex := 0
-	// ... find audio groups
-	var audioGroup *string = nil
-	var subtitlesGroup *string = nil
-	if len(audioVariants) > 0 {
-		audioGroup = &suggest.DefaultAudioGroupID
-		if audioVariants[0].GroupID != nil {
-			audioGroup = audioVariants[0].GroupID
-		}
-	}
-	// ... find subtitles groups
-	if len(convertedSubtitles) > 0 {
-		subtitlesGroup = &suggest.DefaultSubtitlesGroupID
-		if convertedSubtitles[0].Variant.GroupID != nil {
-			subtitlesGroup = convertedSubtitles[0].Variant.GroupID
-		}
-	}
-	// ... write audio
-	streamIndex = len(videoVariants) // Audio playlists start after the last video variant
-	for _, variant := range audioVariants {
-		f.WriteString(variant.Stanza(playlistFilenameForStream(streamPlaylistName, streamIndex)) + "\n")
-		streamIndex += 1
-	}
-	f.WriteString("\n")
-	// ... write subtitles
-	streamIndex = 0 // Subtitle playlists restart at 0
-	for _, c := range convertedSubtitles {
-		fmt.Printf("DEBUG: Adding subtitle %q to Master\n", c.Variant.Name)
-		f.WriteString(c.Variant.Stanza() + "\n")
-		streamIndex += 1
-	}
-	f.WriteString("\n\n")
-	// ... write video variants
-	streamIndex = 0 // Video playlists are the first
-	for _, variant := range videoVariants {
-		vAudioGroup := audioGroup
-		if variant.AudioGroup != nil {
-			vAudioGroup = variant.AudioGroup
-		}
-		vSubtitlesGroup := subtitlesGroup
-		if variant.SubtitleGroup != nil {
-			vAudioGroup = variant.SubtitleGroup
-		}
-		f.WriteString(variant.Stanza(playlistFilenameForStream(streamPlaylistName, streamIndex), vAudioGroup, vSubtitlesGroup) + "\n")
-		streamIndex += 1
-	}
-	f.Close()
-
-	return &Conversion{
-		StreamURLs:                 inputs,
-		mainCommand:                cmd,
-		SubtitleConversionCommands: convertedSubtitles,
-		OutputDirectory:            outputDir,
-	}, nil
+	conversion.master = masterPlaylist{
+		filename:           masterFilename,
+		streamPlaylistName: streamPlaylistName,
+		packaging:          packaging,
+		video:              videoVariants,
+		audio:              audioVariants,
+		subtitles:          convertedSubtitles,
+	}
+	if live {
+		if err := conversion.checkRestart(); err != nil {
+			log.Println("WARNING: The conversion ends if the live input drops:", err)
+		}
+	}
+	if iframeWatchers(packaging) {
+		// I-frames are looked for as segments are written: the playlists are ready with the media playlists
+		conversion.startIFrameWatchers()
+	}
+	if packaging.PlaylistType != suggest.VODPlaylist {
+		// Players can start before the end of the encode: write estimated bandwidths meanwhile
+		if err = conversion.master.write(nil); err != nil {
+			log.Println("An error happened writing the master playlist:", err)
+			conversion.record(MasterStep, "estimated", err)
+		}
+	}
+
+	// Wait for video and audio conversion, then generate I-FRAME-ONLY playlists
+	conversion.wg.Add(1)
+	go func() {
+		defer conversion.wg.Done()
+		conversion.waitMainCommand(cmd, logFile, args)
+	}()
+
+	go conversion.finish(masterFilename)
+	go conversion.stopOnCancel()
+	return conversion, nil
 }
 
 // Launch FFMPEG command on args and returns if it launched succesfully.
-// This function does not wait for FFMPEG to complete.
-func callFFmpeg(logFilename string, args []string, masterCh <-chan string) (*exec.Cmd, error) {
+// This function does not wait for FFMPEG to complete, but reports its progress.
+// The returned logfile should be closed once the command is done.
+func (c *Conversion) callFFmpeg(logFilename string, args []string) (*exec.Cmd, *os.File, error) {
 	logFile, err := os.Create(logFilename)
 	if err != nil {
 		log.Println("Cannot create logfile:", err)
-		return nil, err // FIXME: return better error
+		return nil, nil, err // FIXME: return better error
 	}
 
-	cmd := exec.Command("ffmpeg", args...)
+	cmd := exec.Command("ffmpeg", append(append([]string{}, progressArguments...), args...)...)
+	progressReader, progressWriter, err := progressPipe(cmd)
+	if err != nil {
+		logFile.Close()
+		return nil, nil, err
+	}
 	//Debug
 	fmt.Println("\nDEBUG: Running FFMPEG command:\n \"" + strings.Join(cmd.Args, "\" \"") + "\"")
 	fmt.Println("DEBUG:\tUse \n\t\ttail -f " + logFilename + "\n\n\tto see output.")
@@ -201,41 +447,81 @@ func callFFmpeg(logFilename string, args []string, masterCh <-chan string) (*exe
 	cmd.Stderr = logFile
 
 	err = cmd.Start()
+	progressWriter.Close()
 	if err != nil {
 		log.Println("FFmpeg execution had the following error:", err)
-		return cmd, err // FIXME: return better error
+		logFile.Close()
+		progressReader.Close()
+		return cmd, nil, err // FIXME: return better error
 	}
-	go func() {
-		masterFilename, ok := <-masterCh
-		if !ok {
-			return
+	c.watchProgress(progressReader, EncodeStep, "")
+	return cmd, logFile, nil
+}
+
+// waitMainCommand Waits for the main FFMPEG command to complete, then for the I-FRAME-ONLY playlist watchers and,
+// if it succeeded, encrypts the segments if needed and measures the bandwidths of the media playlists,
+// for the master playlist.
+// With a live input, the command started with `args` is restarted each time its input drops, until the conversion
+// is stopped. See restartPolicy and checkRestart.
+func (c *Conversion) waitMainCommand(cmd *exec.Cmd, logFile *os.File, args []string) {
+	var restarts restartPolicy
+	started := time.Now()
+	err := cmd.Wait()
+	logFile.Close()
+	for restart := 1; c.live && !c.isStopped() && c.ctx.Err() == nil; restart++ {
+		if err = restarts.check(started, err, logFile.Name()); err != nil {
+			break
 		}
-		err := cmd.Wait()
-		if err != nil {
-			log.Println("Error running FFMPEG:", err)
-			return
+		if err = c.checkRestart(); err != nil {
+			err = fmt.Errorf("the live input dropped: %v", err)
+			break
+		}
+		log.Printf("WARNING: The live input of ffmpeg dropped: restarting it in %v\n", LiveRestartDelay)
+		cmd, logFile, err = c.restartMainCommand(restart, args)
+		if err != nil || cmd == nil {
+			break
 		}
+		started = time.Now()
+		err = cmd.Wait()
 		logFile.Close()
-		dir, filename := filepath.Split(masterFilename)
-
-		fmt.Printf("DEBUG: Everything is fine. \n"+
-			"DEBUG: Generating I-FRAME-ONLY playlists on master in directory \"%v\"\n", dir)
-		_, err = iframe_playlist_generator.EnrichPlaylist(dir, filename, dir, FFMPEG_MASTER_PLAYLIST, filename)
-		if err != nil {
-			log.Println("An error happened enriching playlist:", err)
+	}
+	err = c.ignoreStop(err)
+	close(c.exited)
+	c.record(EncodeStep, "", err)
+	// The low-latency playlists are complete once the packagers read the last parts
+	c.packagers.Wait()
+	if c.live && c.packaging.LowLatency == nil && c.ctx.Err() == nil {
+		if endErr := c.endPlaylists(); endErr != nil {
+			log.Println("Cannot end the media playlists:", endErr)
+			c.record(EncodeStep, "endlist", endErr)
 		}
-
-		if GENERATE_IPLAYLIST {
-			err = iframe_playlist_generator.GeneratePlaylist(dir, filename)
-			if err != nil {
-				log.Println("An error happened generating I-FRAME-ONLY playlist:", err)
-			}
-		} else {
-			fmt.Printf("DEBUG: Everything is fine, but we're not generating iFrame Playlist...")
+	}
+	close(c.ended)
+	c.watchers.Wait()
+	if err != nil || c.ctx.Err() != nil || c.failed(PackageStep) || c.failed(EncodeStep) {
+		return
+	}
+	if c.packaging.Encryption != nil {
+		err = c.encrypt()
+		c.record(EncryptStep, "", err)
+		if err != nil {
+			// Some segments may be encrypted already: no master playlist for them
+			log.Println("An error happened encrypting the segments:", err)
+			return
 		}
+	}
+	c.encoded = true
 
-	}()
-	return cmd, nil
+	// Measured bandwidths replace the estimated ones
+	if err = c.master.measure(); err != nil {
+		log.Println("An error happened measuring the media playlists:", err)
+		c.record(MasterStep, "bandwidth", err)
+		return
+	}
+	if iframeWatchers(c.packaging) {
+		// The watchers wrote them along the media playlists
+		c.master.iframes = c.watchedIFrames()
+	}
 }
 
 func playlistFilenameForStream(streamPlaylistName string, index int) string {
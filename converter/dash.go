@@ -0,0 +1,164 @@
+package converter
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/allezxandre/go-hls-encoder/codecs"
+	"github.com/allezxandre/go-hls-encoder/dash"
+	"github.com/allezxandre/go-hls-encoder/webvtt"
+	"github.com/grafov/m3u8"
+)
+
+// dashTimescale The timescale of the segment timelines: milliseconds, as precise as EXTINF durations
+const dashTimescale = 1000
+
+// dashFilename Returns the filename of the DASH manifest of the master playlist at `masterFilename`
+func dashFilename(masterFilename string) string {
+	return strings.TrimSuffix(masterFilename, filepath.Ext(masterFilename)) + ".mpd"
+}
+
+// writeDASH Writes the DASH manifest next to the master playlist, referencing the segments
+// of the media playlists. Subtitle renditions whose name is in `failedSubtitles` are left out,
+// the others are joined into single WebVTT files.
+func (m *masterPlaylist) writeDASH(failedSubtitles map[string]bool) error {
+	mpd, err := m.dashManifest(failedSubtitles)
+	if err != nil {
+		return err
+	}
+	return mpd.WriteFile(dashFilename(m.filename))
+}
+
+// dashManifest Returns the DASH manifest of the encoded media playlists. See writeDASH.
+func (m *masterPlaylist) dashManifest(failedSubtitles map[string]bool) (*dash.MPD, error) {
+	if m.measured == nil {
+		return nil, fmt.Errorf("the bandwidths of the media playlists are unknown")
+	}
+	dir := filepath.Dir(m.filename)
+	period := dash.Period{ID: "0"}
+	var duration time.Duration
+
+	// ... add video: one adaptation set per codec and dynamic range
+	videoSets := map[string]int{} // Index of the adaptation sets in `period`, by codec family and range
+	streamIndex := 0              // Video playlists are the first
+	for _, variant := range m.video {
+		playlistFilename := playlistFilenameForStream(m.streamPlaylistName, streamIndex)
+		segments, d, err := dashSegments(filepath.Join(dir, playlistFilename))
+		if err != nil {
+			return nil, err
+		}
+		if d > duration {
+			duration = d
+		}
+		codec := variant.CodecString()
+		if len(codec) == 0 {
+			codec = codecs.Video(m.ffmpegCodecs[playlistFilename])
+		}
+		key := strings.SplitN(codec, ".", 2)[0] + "|" + variant.VideoRange
+		index, ok := videoSets[key]
+		if !ok {
+			index = len(period.AdaptationSets)
+			videoSets[key] = index
+			period.AdaptationSets = append(period.AdaptationSets, variant.DASHAdaptationSet(index))
+		}
+		representation := variant.DASHRepresentation(strings.TrimSuffix(playlistFilename, ".m3u8"), codec,
+			m.measured[playlistFilename])
+		representation.SegmentList = segments
+		period.AdaptationSets[index].Representations = append(period.AdaptationSets[index].Representations, representation)
+		streamIndex += 1
+	}
+	// ... add audio
+	for _, variant := range m.audio {
+		playlistFilename := playlistFilenameForStream(m.streamPlaylistName, streamIndex)
+		segments, d, err := dashSegments(filepath.Join(dir, playlistFilename))
+		if err != nil {
+			return nil, err
+		}
+		if d > duration {
+			duration = d
+		}
+		set := variant.DASHAdaptationSet(len(period.AdaptationSets), strings.TrimSuffix(playlistFilename, ".m3u8"),
+			m.measured[playlistFilename])
+		if len(set.Representations[0].Codecs) == 0 {
+			log.Printf("WARNING: Unknown codec of audio variant %q in the DASH manifest\n", variant.Name)
+		}
+		set.Representations[0].SegmentList = segments
+		period.AdaptationSets = append(period.AdaptationSets, set)
+		streamIndex += 1
+	}
+	// ... add subtitles, as single WebVTT files
+	for i, c := range m.subtitles {
+		if failedSubtitles[c.Variant.Name] {
+			continue
+		}
+		filename := c.Variant.Name + ".vtt"
+		if err := webvtt.Join(c.Variant.PlaylistName(dir), filepath.Join(dir, filename)); err != nil {
+			return nil, fmt.Errorf("subtitle %q: %v", c.Variant.Name, err)
+		}
+		bandwidth := 0
+		if info, err := os.Stat(filepath.Join(dir, filename)); err == nil && duration > 0 {
+			bandwidth = int(float64(info.Size()*8) / duration.Seconds())
+		}
+		period.AdaptationSets = append(period.AdaptationSets,
+			c.Variant.DASHAdaptationSet(len(period.AdaptationSets), fmt.Sprintf("subtitles_%d", i), filename, bandwidth))
+	}
+
+	return &dash.MPD{
+		Profiles:                  dash.MainProfile,
+		Type:                      "static",
+		MediaPresentationDuration: dash.Duration(duration),
+		MinBufferTime:             dash.Duration(m.packaging.TargetDuration()),
+		Periods:                   []dash.Period{period},
+	}, nil
+}
+
+// dashSegments Returns the segments of the fMP4 media playlist at `playlistPath`, and its duration
+func dashSegments(playlistPath string) (*dash.SegmentList, time.Duration, error) {
+	f, err := os.Open(playlistPath)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer f.Close()
+	p, t, err := m3u8.DecodeFrom(f, true)
+	if err != nil {
+		return nil, 0, err
+	}
+	if t != m3u8.MEDIA {
+		return nil, 0, fmt.Errorf("%s is not a media playlist", playlistPath)
+	}
+	media := p.(*m3u8.MediaPlaylist)
+	if media.Map == nil {
+		return nil, 0, fmt.Errorf("%s has no initialization segment", playlistPath)
+	}
+	list := &dash.SegmentList{
+		Timescale:      dashTimescale,
+		Initialization: &dash.URL{SourceURL: media.Map.URI},
+	}
+	if media.Map.Limit > 0 {
+		list.Initialization.Range = dash.ByteRange(media.Map.Offset, media.Map.Limit)
+	}
+	var durations []time.Duration
+	var total time.Duration
+	for _, segment := range media.Segments {
+		if segment == nil {
+			break // The end of the segments buffer
+		}
+		if segment.Map != nil && segment.Map.URI != media.Map.URI {
+			return nil, 0, fmt.Errorf("%s has several initialization segments", playlistPath)
+		}
+		url := dash.SegmentURL{Media: segment.URI}
+		if segment.Limit > 0 {
+			url.MediaRange = dash.ByteRange(segment.Offset, segment.Limit)
+		}
+		list.SegmentURLs = append(list.SegmentURLs, url)
+		d := time.Duration(segment.Duration * float64(time.Second))
+		durations = append(durations, d)
+		total += d
+	}
+	list.SegmentTimeline = dash.NewSegmentTimeline(durations, dashTimescale)
+	return list, total, nil
+}
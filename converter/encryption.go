@@ -0,0 +1,59 @@
+package converter
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/allezxandre/go-hls-encoder/encryption"
+	"github.com/allezxandre/go-hls-encoder/playlist"
+)
+
+// DefaultKeyProvider Provides the keys of encrypted conversions.
+// When `nil`, keys are read from, or generated in, the output directory: see encryption.FileKeyProvider.
+var DefaultKeyProvider encryption.KeyProvider
+
+// keyProvider Returns the key provider of the conversion
+func (c *Conversion) keyProvider() encryption.KeyProvider {
+	if DefaultKeyProvider != nil {
+		return DefaultKeyProvider
+	}
+	return encryption.FileKeyProvider{Dir: c.OutputDirectory, URIPrefix: c.packaging.Encryption.KeyURIPrefix}
+}
+
+// encrypt Encrypts the segments of the video and audio media playlists,
+// and sets the session key of the master playlist. Subtitles are left clear.
+func (c *Conversion) encrypt() error {
+	options := c.packaging.Encryption
+	provider := c.keyProvider()
+	dir := filepath.Dir(c.master.filename)
+	count := len(c.master.video) + len(c.master.audio)
+	var sessionKey *encryption.Key
+	for streamIndex := 0; streamIndex < count; streamIndex++ {
+		if err := c.ctx.Err(); err != nil {
+			return err
+		}
+		playlistFilename := playlistFilenameForStream(c.master.streamPlaylistName, streamIndex)
+		key, err := encryption.EncryptPlaylist(filepath.Join(dir, playlistFilename), encryption.Options{
+			Method:      options.Method,
+			KeyRotation: options.KeyRotation,
+			Variant:     streamIndex,
+		}, provider)
+		if err != nil {
+			return fmt.Errorf("%s: %v", playlistFilename, err)
+		}
+		if sessionKey == nil {
+			sessionKey = key
+		}
+		c.sendProgress(Progress{
+			Phase:   EncryptStep,
+			Name:    playlistFilename,
+			Percent: 100 * float64(streamIndex+1) / float64(count),
+			Done:    streamIndex+1 == count,
+		})
+	}
+	if sessionKey != nil {
+		// Lets players fetch the first key while they load the master playlist
+		c.master.sessionKeys = []playlist.Key{sessionKey.Tag(options.Method)}
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package converter
+
+import (
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/allezxandre/go-hls-encoder/iframe-playlist-generator"
+	"github.com/allezxandre/go-hls-encoder/suggest"
+)
+
+// iframeWatchInterval How often the I-FRAME-ONLY playlists are updated while the media playlists are written
+const iframeWatchInterval = time.Second
+
+// iframeWatchers Returns whether watchers build the I-FRAME-ONLY playlists of segments packaged with `options`.
+// The generator cannot read encrypted segments: conversions refuse to encrypt them with GENERATE_IPLAYLIST.
+func iframeWatchers(options suggest.PackagingOptions) bool {
+	return GENERATE_IPLAYLIST && options.Encryption == nil
+}
+
+// startIFrameWatchers Starts one I-FRAME-ONLY playlist watcher per video media playlist.
+// They follow the media playlists until they are complete, reporting their progress. Meanwhile,
+// the master playlist announces the I-FRAME-ONLY playlists with the bandwidth of their variant.
+func (c *Conversion) startIFrameWatchers() {
+	dir := filepath.Dir(c.master.filename)
+	for i, uri := range c.master.videoPlaylists() {
+		estimated, _ := strconv.Atoi(c.master.video[i].Bandwidth)
+		c.master.iframes = append(c.master.iframes, iframe_playlist_generator.IFramePlaylist{
+			VariantURI:       uri,
+			URI:              iframe_playlist_generator.IFrameOnlyFilename(uri),
+			Bandwidth:        estimated,
+			AverageBandwidth: estimated,
+		})
+		watcher := &iframe_playlist_generator.Watcher{Dir: dir, VariantURI: uri}
+		watcher.Progress = func(variantURI string, done, total int) {
+			c.sendProgress(Progress{
+				Phase:   IFrameStep,
+				Name:    variantURI,
+				OutTime: watcher.Processed(),
+				Percent: percentOf(watcher.Processed(), c.duration),
+			})
+		}
+		c.watchers.Add(1)
+		go func() {
+			defer c.watchers.Done()
+			p, err := watcher.Run(c.ctx, iframeWatchInterval, c.ended)
+			c.record(IFrameStep, watcher.VariantURI, err)
+			if err != nil {
+				return // The variant is left without I-FRAME-ONLY playlist
+			}
+			c.sendProgress(Progress{Phase: IFrameStep, Name: watcher.VariantURI, OutTime: watcher.Processed(), Percent: 100, Done: true})
+			c.mu.Lock()
+			c.watched = append(c.watched, p)
+			c.mu.Unlock()
+		}()
+	}
+}
+
+// watchedIFrames Returns the I-FRAME-ONLY playlists written by the watchers, in the order of the video variants
+func (c *Conversion) watchedIFrames() []iframe_playlist_generator.IFramePlaylist {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var iframes []iframe_playlist_generator.IFramePlaylist
+	for _, uri := range c.master.videoPlaylists() {
+		for _, p := range c.watched {
+			if p.VariantURI == uri && p.Bandwidth > 0 {
+				iframes = append(iframes, p)
+			}
+		}
+	}
+	return iframes
+}
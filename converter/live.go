@@ -0,0 +1,247 @@
+package converter
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/allezxandre/go-hls-encoder/suggest"
+)
+
+// LiveInputTimeout How long ffmpeg waits for data from a live input before it exits, to be restarted
+var LiveInputTimeout = 10 * time.Second
+
+// LiveRestartDelay How long to wait before restarting the commands whose live input dropped
+var LiveRestartDelay = 2 * time.Second
+
+// LiveMaxRestarts How many times in a row the main command is restarted when it runs less than
+// LiveStableRun each time, before the conversion fails
+var LiveMaxRestarts = 5
+
+// LiveStableRun How long the main command must run for its input to be considered back
+var LiveStableRun = time.Minute
+
+// inputErrors The errors ffmpeg writes to its log when it cannot read its live input any more
+var inputErrors = []string{
+	"Input/output error",
+	"Connection timed out",
+	"Operation timed out",
+	"Connection refused",
+	"Connection reset by peer",
+	"End of file",
+}
+
+// logTailSize How much of the end of the log of a command is looked at for input errors
+const logTailSize = 64 * 1024
+
+// liveSchemes The URL schemes of the live inputs
+var liveSchemes = map[string]bool{"rtmp": true, "rtmps": true, "srt": true, "udp": true, "rtp": true, "tcp": true}
+
+// IsLiveInput Tells whether `input` is the URL of a live stream, e.g. `rtmp://`, `srt://` or `udp://`.
+// Live inputs never end: their conversion lasts until it is stopped.
+func IsLiveInput(input string) bool {
+	u, err := url.Parse(input)
+	return err == nil && liveSchemes[strings.ToLower(u.Scheme)]
+}
+
+// inputArguments Returns the arguments that read `input`. ffmpeg exits when a live input drops.
+func inputArguments(input string) []string {
+	if IsLiveInput(input) {
+		return []string{"-rw_timeout", strconv.FormatInt(int64(LiveInputTimeout/time.Microsecond), 10), "-i", input}
+	}
+	return []string{"-i", input}
+}
+
+// restartArguments Returns the arguments of the main command once restarted after its live input dropped:
+// ffmpeg appends the new segments to the media playlists it wrote, after a discontinuity.
+// It writes the initialization segment of fmp4 playlists again, see checkRestart.
+func restartArguments(args []string) []string {
+	restarted := append([]string{}, args...)
+	for i := 0; i < len(restarted)-1; i++ {
+		if restarted[i] == "-hls_flags" {
+			restarted[i+1] += "+append_list"
+			return restarted
+		}
+	}
+	// The output file is the last argument
+	output := restarted[len(restarted)-1]
+	return append(restarted[:len(restarted)-1], "-hls_flags", "+append_list", output)
+}
+
+// checkRestart Returns why the main command cannot be restarted, if it copies streams to fmp4 segments.
+// ffmpeg writes the initialization segment again under the same name, and its media playlists keep a
+// single EXT-X-MAP: the parameters of copied streams may change once the input is back, and the segments
+// before the discontinuity would be decoded with the new initialization segment. Encoded streams keep the
+// parameters of the plan.
+func (c *Conversion) checkRestart() error {
+	if c.packaging.SegmentContainer != suggest.FMP4Segments {
+		return nil
+	}
+	for _, variant := range c.master.video {
+		if variant.Codec == "copy" {
+			return fmt.Errorf("fmp4 playlists cannot be restarted with copied video variant %q", variant.MapInput)
+		}
+	}
+	for _, variant := range c.master.audio {
+		if variant.Codec == "copy" {
+			return fmt.Errorf("fmp4 playlists cannot be restarted with copied audio variant %q", variant.Name)
+		}
+	}
+	return nil
+}
+
+// restartPolicy Decides whether the main command of a live conversion is restarted once it exits
+type restartPolicy struct {
+	failures int // Restarts in a row after runs shorter than LiveStableRun
+}
+
+// check Returns `nil` if the command that started at `start` and exited with `err`, logging to `logFilename`,
+// is to be restarted: its live input dropped, i.e. it exited cleanly or on an error reading its input,
+// and it did not fail fast more than LiveMaxRestarts times in a row. Otherwise, returns why the conversion fails.
+func (p *restartPolicy) check(start time.Time, err error, logFilename string) error {
+	if err != nil && !inputDropped(logFilename) {
+		return err
+	}
+	if time.Since(start) < LiveStableRun {
+		p.failures++
+	} else {
+		p.failures = 0
+	}
+	if p.failures > LiveMaxRestarts {
+		if err == nil {
+			err = errors.New("exited")
+		}
+		return fmt.Errorf("the live input dropped %d times in a row within %v: %v", p.failures, LiveStableRun, err)
+	}
+	return nil
+}
+
+// inputDropped Tells whether the log of a command reports an error reading its input
+func inputDropped(logFilename string) bool {
+	f, err := os.Open(logFilename)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+	if info, err := f.Stat(); err == nil && info.Size() > logTailSize {
+		f.Seek(-logTailSize, io.SeekEnd)
+	}
+	tail, err := ioutil.ReadAll(f)
+	if err != nil {
+		return false
+	}
+	for _, message := range inputErrors {
+		if bytes.Contains(tail, []byte(message)) {
+			return true
+		}
+	}
+	return false
+}
+
+// playlistTypeTag Returns the EXT-X-PLAYLIST-TYPE of the media playlists written by the converter,
+// "" for sliding-window playlists
+func playlistTypeTag(options suggest.PackagingOptions) string {
+	if options.PlaylistType == suggest.EventPlaylist {
+		return "EVENT"
+	}
+	return ""
+}
+
+// Live Tells whether one of the inputs of the conversion is live. See IsLiveInput.
+func (c *Conversion) Live() bool {
+	return c.live
+}
+
+// Stop Ends the conversion with what was encoded so far: commands are interrupted and
+// not restarted, then the media playlists are ended and the master playlist is written.
+// This is how live conversions end. Unlike cancelling the context, outputs are kept.
+func (c *Conversion) Stop() {
+	c.mu.Lock()
+	c.stopped = true
+	c.mu.Unlock()
+	c.SigInt()
+}
+
+func (c *Conversion) isStopped() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.stopped
+}
+
+// ignoreStop Returns `nil` if `err` is the exit status of a command interrupted by Stop
+func (c *Conversion) ignoreStop(err error) error {
+	if _, ok := err.(*exec.ExitError); ok && c.isStopped() {
+		return nil
+	}
+	return err
+}
+
+// waitRestart Waits for `LiveRestartDelay`. Returns `false` if the conversion
+// was stopped or cancelled meanwhile.
+func (c *Conversion) waitRestart() bool {
+	select {
+	case <-time.After(LiveRestartDelay):
+	case <-c.ctx.Done():
+		return false
+	}
+	return !c.isStopped()
+}
+
+// setCommand Replaces `*cmd` by the restarted `next`, which is interrupted if the conversion was stopped meanwhile
+func (c *Conversion) setCommand(cmd **exec.Cmd, next *exec.Cmd) {
+	c.mu.Lock()
+	*cmd = next
+	stopped := c.stopped
+	c.mu.Unlock()
+	if stopped {
+		next.Process.Signal(syscall.SIGINT)
+	}
+}
+
+// restartMainCommand Restarts the main command after its live input dropped.
+// Returns a `nil` command if the conversion was stopped or cancelled before.
+func (c *Conversion) restartMainCommand(restart int, args []string) (*exec.Cmd, *os.File, error) {
+	if !c.waitRestart() {
+		return nil, nil, nil
+	}
+	logFilename := filepath.Join(c.OutputDirectory, fmt.Sprintf("conversion.restart-%d.log", restart))
+	cmd, logFile, err := c.callFFmpeg(logFilename, restartArguments(args))
+	if err != nil {
+		return nil, nil, err
+	}
+	c.setCommand(&c.mainCommand, cmd)
+	return cmd, logFile, nil
+}
+
+// endPlaylists Ends the media playlists of the main command, which ffmpeg leaves open for live inputs
+func (c *Conversion) endPlaylists() error {
+	for streamIndex := 0; streamIndex < len(c.master.video)+len(c.master.audio); streamIndex++ {
+		playlistFilename := filepath.Join(c.OutputDirectory, playlistFilenameForStream(c.master.streamPlaylistName, streamIndex))
+		f, err := os.OpenFile(playlistFilename, os.O_APPEND|os.O_WRONLY, 0)
+		if os.IsNotExist(err) {
+			log.Println("WARNING: No media playlist was written at", playlistFilename)
+			continue
+		} else if err != nil {
+			return err
+		}
+		_, err = f.WriteString("#EXT-X-ENDLIST\n")
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+package converter
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/allezxandre/go-hls-encoder/suggest"
+)
+
+func TestIsLiveInput(t *testing.T) {
+	for input, expected := range map[string]bool{
+		"rtmp://localhost/live/stream": true,
+		"srt://127.0.0.1:9000":         true,
+		"udp://127.0.0.1:1234":         true,
+		"movie.mkv":                    false,
+		"/videos/movie.mp4":            false,
+		"https://example.com/movie.ts": false,
+	} {
+		if live := IsLiveInput(input); live != expected {
+			t.Errorf("Unexpected IsLiveInput(%q): %v", input, live)
+		}
+	}
+}
+
+func TestRestartArguments(t *testing.T) {
+	args := []string{"-f", "hls", "-hls_flags", "+omit_endlist", "out_%v.m3u8"}
+	restarted := restartArguments(args)
+	if expected := "-f hls -hls_flags +omit_endlist+append_list out_%v.m3u8"; strings.Join(restarted, " ") != expected {
+		t.Errorf("Unexpected restart arguments %q", restarted)
+	}
+	if args[3] != "+omit_endlist" {
+		t.Errorf("The arguments of the first command were modified: %q", args)
+	}
+	restarted = restartArguments([]string{"-f", "hls", "out_%v.m3u8"})
+	if expected := "-f hls -hls_flags +append_list out_%v.m3u8"; strings.Join(restarted, " ") != expected {
+		t.Errorf("Unexpected restart arguments %q", restarted)
+	}
+}
+
+func TestCheckRestart(t *testing.T) {
+	c := &Conversion{
+		packaging: suggest.PackagingOptions{SegmentContainer: suggest.FMP4Segments},
+		master:    masterPlaylist{video: []suggest.VideoVariant{testVideoVariant()}},
+	}
+	if err := c.checkRestart(); err == nil || !strings.Contains(err.Error(), "copied video variant") {
+		t.Error("A copied fmp4 video variant should not be restarted:", err)
+	}
+	c.master.video[0].Codec = "libx264"
+	c.master.audio = []suggest.AudioVariant{{Name: "English", Codec: "copy"}}
+	if err := c.checkRestart(); err == nil || !strings.Contains(err.Error(), "copied audio variant") {
+		t.Error("A copied fmp4 audio variant should not be restarted:", err)
+	}
+	c.master.audio[0].Codec = "aac"
+	if err := c.checkRestart(); err != nil {
+		t.Error("Encoded variants should be restarted:", err)
+	}
+	// MPEG-TS segments carry their parameters
+	c.master.video[0].Codec = "copy"
+	c.packaging.SegmentContainer = suggest.MPEGTSSegments
+	if err := c.checkRestart(); err != nil {
+		t.Error("Copied mpegts variants should be restarted:", err)
+	}
+}
+
+func TestRestartPolicy(t *testing.T) {
+	dir, err := ioutil.TempDir("", "live")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	logs := map[string]string{
+		"dropped.log": "[mpegts @ 0x1] rtmp://localhost/live/stream: Input/output error\n",
+		"failed.log":  "[libx264 @ 0x1] Error initializing output stream 0:0\n",
+	}
+	for name, content := range logs {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	exitErr := exec.Command("false").Run()
+	if exitErr == nil {
+		t.Fatal("Expected an exit error")
+	}
+
+	var p restartPolicy
+	stable := time.Now().Add(-2 * LiveStableRun)
+	if err := p.check(stable, nil, filepath.Join(dir, "failed.log")); err != nil {
+		t.Error("A clean exit should be restarted:", err)
+	}
+	if err := p.check(stable, exitErr, filepath.Join(dir, "dropped.log")); err != nil {
+		t.Error("An input error should be restarted:", err)
+	}
+	if err := p.check(stable, exitErr, filepath.Join(dir, "failed.log")); err != exitErr {
+		t.Error("Other errors should not be restarted:", err)
+	}
+	for i := 0; i < LiveMaxRestarts; i++ {
+		if err := p.check(time.Now(), exitErr, filepath.Join(dir, "dropped.log")); err != nil {
+			t.Fatalf("Fast failure %d should be restarted: %v", i, err)
+		}
+	}
+	if err := p.check(time.Now(), exitErr, filepath.Join(dir, "dropped.log")); err == nil {
+		t.Error("Too many fast failures in a row should not be restarted")
+	}
+}
+
+func TestLaunchPlanLiveSubtitles(t *testing.T) {
+	live := "srt://127.0.0.1:9000"
+	plan := &suggest.EncodingPlan{
+		Inputs:    []string{live},
+		HLS:       suggest.DefaultHLSSettings(),
+		Packaging: suggest.PackagingOptions{PlaylistType: suggest.EventPlaylist},
+		Video:     []suggest.VideoVariant{{MapInput: "0:0", Codec: "copy", Resolution: "1920x1080", Bandwidth: "5000000"}},
+		Subtitles: []suggest.SubtitleVariant{{InputURL: live, StreamIndex: 2, Name: "Subtitle2", OutputIndex: 1}},
+	}
+	if err := plan.Validate(); err != nil {
+		t.Fatal("Invalid test plan:", err)
+	}
+	if _, err := LaunchPlan(context.Background(), "out", plan); err == nil || !strings.Contains(err.Error(), ErrLiveSubtitles.Error()) {
+		t.Error("Subtitles of live inputs should be rejected:", err)
+	}
+}
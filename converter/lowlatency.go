@@ -0,0 +1,80 @@
+package converter
+
+import (
+	"strings"
+	"time"
+
+	"github.com/allezxandre/go-hls-encoder/llhls"
+)
+
+// partsPlaylistSuffix Suffix of the media playlists ffmpeg writes in low-latency mode, whose segments are parts
+const partsPlaylistSuffix = "_parts"
+
+// partsPlaylistFilename Returns the filename of the playlist of the parts of the media playlist `playlistFilename`
+func partsPlaylistFilename(playlistFilename string) string {
+	return strings.TrimSuffix(playlistFilename, ".m3u8") + partsPlaylistSuffix + ".m3u8"
+}
+
+// withoutPartsPlaylists Returns `codecs` by media playlist, from `codecs` by playlist of parts
+func withoutPartsPlaylists(codecs map[string]string) map[string]string {
+	if codecs == nil {
+		return nil
+	}
+	renamed := make(map[string]string, len(codecs))
+	for uri, c := range codecs {
+		renamed[strings.TrimSuffix(uri, partsPlaylistSuffix+".m3u8")+".m3u8"] = c
+	}
+	return renamed
+}
+
+// lowLatencyKeyframeInterval Returns the interval between key frames of low-latency variants:
+// the largest multiple of the part duration that does not exceed `maxKeyframeInterval`
+// and divides the segments in parts. Segments and some parts then start on a key frame.
+func lowLatencyKeyframeInterval(segmentDuration, partDuration time.Duration) time.Duration {
+	parts := int(float64(segmentDuration)/float64(partDuration) + 0.5)
+	for partsPerKeyframe := int(maxKeyframeInterval / partDuration); partsPerKeyframe > 1; partsPerKeyframe-- {
+		if parts%partsPerKeyframe == 0 {
+			return time.Duration(partsPerKeyframe) * partDuration
+		}
+	}
+	return partDuration
+}
+
+// startPackagers Starts one low-latency packager per media playlist of the main command.
+// They end with the main command.
+func (c *Conversion) startPackagers(streamPlaylistName string, streams int) {
+	options := c.packaging.WithDefaults()
+	for streamIndex := 0; streamIndex < streams; streamIndex++ {
+		playlistFilename := playlistFilenameForStream(streamPlaylistName, streamIndex)
+		packager := &llhls.Packager{
+			Dir:             c.OutputDirectory,
+			Source:          partsPlaylistFilename(playlistFilename),
+			Output:          playlistFilename,
+			Variant:         streamIndex,
+			PartFilename:    options.LowLatency.PartFilename,
+			SegmentFilename: options.SegmentFilename,
+			SegmentDuration: options.TargetDuration(),
+			PartDuration:    options.LowLatency.PartTarget(),
+			Type:            playlistTypeTag(options),
+			ListSize:        options.ListSize,
+			DeleteSegments:  c.live && options.ListSize > 0,
+		}
+		c.packagers.Add(1)
+		go func() {
+			defer c.packagers.Done()
+			c.record(PackageStep, packager.Output, packager.Run(c.ctx, c.exited))
+		}()
+	}
+}
+
+// failed Tells whether a `step` failed
+func (c *Conversion) failed(step Step) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, s := range c.steps {
+		if s.Step == step && s.Err != nil {
+			return true
+		}
+	}
+	return false
+}
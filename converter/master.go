@@ -0,0 +1,227 @@
+package converter
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/allezxandre/go-hls-encoder/iframe-playlist-generator"
+	"github.com/allezxandre/go-hls-encoder/playlist"
+	"github.com/allezxandre/go-hls-encoder/suggest"
+	"github.com/grafov/m3u8"
+)
+
+// masterPlaylist Everything needed to write the master playlist of a conversion
+type masterPlaylist struct {
+	filename           string
+	streamPlaylistName string
+	packaging          suggest.PackagingOptions
+	video              []suggest.VideoVariant
+	audio              []suggest.AudioVariant
+	subtitles          []SubtitleVariantConversion
+
+	// Known once the media playlists are encoded
+	measured     map[string]suggest.MeasuredBandwidth // Bandwidths of the media playlists, by filename
+	ffmpegCodecs map[string]string                    // CODECS written by ffmpeg, by filename
+	iframes      []iframe_playlist_generator.IFramePlaylist
+	sessionKeys  []playlist.Key // Keys of the encrypted segments
+}
+
+// model Returns the master playlist. Subtitle renditions whose name is in `failedSubtitles`
+// are left out. Until the media playlists are encoded, bandwidths are estimated.
+func (m *masterPlaylist) model(failedSubtitles map[string]bool) *playlist.Master {
+	master := &playlist.Master{
+		Version:             playlist.DefaultVersion,
+		IndependentSegments: m.packaging.IndependentSegments,
+		SessionKeys:         m.sessionKeys,
+	}
+	// ... find audio groups
+	var audioGroup *string = nil
+	var subtitlesGroup *string = nil
+	if len(m.audio) > 0 {
+		audioGroup = &suggest.DefaultAudioGroupID
+		if m.audio[0].GroupID != nil {
+			audioGroup = m.audio[0].GroupID
+		}
+	}
+	// The DEFAULT and AUTOSELECT attributes follow the HLS rules, however the variants were built
+	audio := append([]suggest.AudioVariant(nil), m.audio...)
+	var subtitles []suggest.SubtitleVariant
+	for _, c := range m.subtitles {
+		if failedSubtitles[c.Variant.Name] {
+			log.Printf("Subtitle %q failed: leaving it out of the master playlist", c.Variant.Name)
+			continue
+		}
+		subtitles = append(subtitles, c.Variant)
+	}
+	suggest.SelectRenditions(audio, subtitles)
+	// ... add audio
+	audioByGroup := map[string][]suggest.AudioVariant{}      // For the codecs of each video variant
+	audioBandwidth := map[string]suggest.MeasuredBandwidth{} // Largest audio bandwidth of each group
+	streamIndex := len(m.video)                              // Audio playlists start after the last video variant
+	for _, variant := range audio {
+		groupID := suggest.DefaultAudioGroupID
+		if variant.GroupID != nil {
+			groupID = *variant.GroupID
+		}
+		audioByGroup[groupID] = append(audioByGroup[groupID], variant)
+		playlistFilename := playlistFilenameForStream(m.streamPlaylistName, streamIndex)
+		if b, ok := m.measured[playlistFilename]; ok {
+			audioBandwidth[groupID] = audioBandwidth[groupID].Max(b)
+		}
+		master.Renditions = append(master.Renditions, variant.Rendition(playlistFilename))
+		streamIndex += 1
+	}
+	// ... add subtitles
+	for _, variant := range subtitles {
+		fmt.Printf("DEBUG: Adding subtitle %q to Master\n", variant.Name)
+		if subtitlesGroup == nil {
+			subtitlesGroup = &suggest.DefaultSubtitlesGroupID
+			if variant.GroupID != nil {
+				subtitlesGroup = variant.GroupID
+			}
+		}
+		master.Renditions = append(master.Renditions, variant.Rendition())
+	}
+	// ... add video variants
+	streamIndex = 0 // Video playlists are the first
+	for _, variant := range m.video {
+		vAudioGroup := audioGroup
+		if variant.AudioGroup != nil {
+			vAudioGroup = variant.AudioGroup
+		}
+		vSubtitlesGroup := subtitlesGroup
+		if variant.SubtitleGroup != nil {
+			vSubtitlesGroup = variant.SubtitleGroup
+		}
+		var vAudio []suggest.AudioVariant
+		var vAudioBandwidth suggest.MeasuredBandwidth
+		if vAudioGroup != nil {
+			vAudio = audioByGroup[*vAudioGroup]
+			vAudioBandwidth = audioBandwidth[*vAudioGroup]
+		}
+		playlistFilename := playlistFilenameForStream(m.streamPlaylistName, streamIndex)
+		codecs := suggest.VariantCodecs(variant, vAudio)
+		if len(codecs) == 0 {
+			codecs = m.ffmpegCodecs[playlistFilename]
+		}
+		// The bandwidth of a variant includes the one of its largest audio rendition
+		var vBandwidth *suggest.MeasuredBandwidth
+		if b, ok := m.measured[playlistFilename]; ok {
+			b = b.Add(vAudioBandwidth)
+			vBandwidth = &b
+		}
+		master.Variants = append(master.Variants,
+			variant.StreamInf(playlistFilename, vAudioGroup, vSubtitlesGroup, codecs, vBandwidth))
+		streamIndex += 1
+	}
+	// ... add I-FRAME-ONLY playlists
+	for _, p := range m.iframes {
+		iframe_playlist_generator.AddIFrameVariant(master, p)
+	}
+	return master
+}
+
+// write Writes the master playlist, leaving out the subtitles in `failedSubtitles`.
+func (m *masterPlaylist) write(failedSubtitles map[string]bool) error {
+	return m.model(failedSubtitles).WriteFile(m.filename)
+}
+
+// measure Measures the bandwidths of the encoded media playlists,
+// and reads the codecs written by ffmpeg.
+func (m *masterPlaylist) measure() error {
+	dir := filepath.Dir(m.filename)
+	measured := map[string]suggest.MeasuredBandwidth{}
+	for streamIndex := 0; streamIndex < len(m.video)+len(m.audio); streamIndex++ {
+		playlistFilename := playlistFilenameForStream(m.streamPlaylistName, streamIndex)
+		b, err := measureBandwidth(filepath.Join(dir, playlistFilename))
+		if err != nil {
+			return err
+		}
+		measured[playlistFilename] = b
+	}
+	m.measured = measured
+	m.ffmpegCodecs = readCodecs(filepath.Join(dir, FFMPEG_MASTER_PLAYLIST))
+	if m.packaging.LowLatency != nil {
+		// ffmpeg's master playlist references the playlists of the parts
+		m.ffmpegCodecs = withoutPartsPlaylists(m.ffmpegCodecs)
+	}
+	return nil
+}
+
+// videoPlaylists Returns the filenames of the media playlists of the video variants
+func (m *masterPlaylist) videoPlaylists() []string {
+	var filenames []string
+	for streamIndex := range m.video {
+		filenames = append(filenames, playlistFilenameForStream(m.streamPlaylistName, streamIndex))
+	}
+	return filenames
+}
+
+// measureBandwidth Returns the peak and average bandwidth of the media playlist
+// at `playlistPath`, from the size and duration of its segments.
+// The peak bandwidth is the one of the largest segment.
+func measureBandwidth(playlistPath string) (b suggest.MeasuredBandwidth, err error) {
+	f, err := os.Open(playlistPath)
+	if err != nil {
+		return b, err
+	}
+	defer f.Close()
+	p, t, err := m3u8.DecodeFrom(f, true)
+	if err != nil {
+		return b, err
+	}
+	if t != m3u8.MEDIA {
+		return b, fmt.Errorf("%s is not a media playlist", playlistPath)
+	}
+	var totalSize int64
+	var totalDuration float64
+	for _, segment := range p.(*m3u8.MediaPlaylist).Segments {
+		if segment == nil {
+			break // The end of the segments buffer
+		}
+		size := segment.Limit // Byte-range, for single files
+		if size <= 0 {
+			info, err := os.Stat(filepath.Join(filepath.Dir(playlistPath), segment.URI))
+			if err != nil {
+				return b, err
+			}
+			size = info.Size()
+		}
+		if segment.Duration <= 0 {
+			continue
+		}
+		if bandwidth := int(float64(size*8) / segment.Duration); bandwidth > b.Peak {
+			b.Peak = bandwidth
+		}
+		totalSize += size
+		totalDuration += segment.Duration
+	}
+	if totalDuration > 0 {
+		b.Average = int(float64(totalSize*8) / totalDuration)
+	}
+	return b, nil
+}
+
+// readCodecs Returns the CODECS of the variants of the master playlist at `playlistPath`,
+// by URI. Returns `nil` if it cannot be read.
+func readCodecs(playlistPath string) map[string]string {
+	f, err := os.Open(playlistPath)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+	p, t, err := m3u8.DecodeFrom(f, true)
+	if err != nil || t != m3u8.MASTER {
+		log.Println("Cannot read the codecs of", playlistPath, err)
+		return nil
+	}
+	codecs := map[string]string{}
+	for _, v := range p.(*m3u8.MasterPlaylist).Variants {
+		if len(v.Codecs) > 0 {
+			codecs[v.URI] = v.Codecs
+		}
+	}
+	return codecs
+}
@@ -0,0 +1,172 @@
+package converter
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/allezxandre/go-hls-encoder/language"
+	"github.com/allezxandre/go-hls-encoder/suggest"
+)
+
+const mediaPlaylist = `#EXTM3U
+#EXT-X-VERSION:7
+#EXT-X-TARGETDURATION:6
+#EXT-X-MEDIA-SEQUENCE:0
+#EXT-X-PLAYLIST-TYPE:VOD
+#EXT-X-MAP:URI="init_0.mp4"
+#EXTINF:6.000000,
+stream_0_0.m4s
+#EXTINF:6.000000,
+stream_0_1.m4s
+#EXTINF:2.000000,
+stream_0_2.m4s
+#EXT-X-ENDLIST
+`
+
+// writeMediaPlaylist Writes `mediaPlaylist` and its segments to a new directory
+func writeMediaPlaylist(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "master")
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]int{
+		"init_0.mp4":     1000,
+		"stream_0_0.m4s": 750000, // 1 Mbit/s
+		"stream_0_1.m4s": 1500000,
+		"stream_0_2.m4s": 250000,
+	}
+	for name, size := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), make([]byte, size), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "stream_0.m3u8"), []byte(mediaPlaylist), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func testVideoVariant() suggest.VideoVariant {
+	return suggest.VideoVariant{
+		MapInput:   "0:0",
+		Codec:      "copy",
+		Codecs:     "avc1.640028",
+		Resolution: "1920x1080",
+		Bandwidth:  "700000",
+		FrameRate:  "24000/1001",
+		VideoRange: "SDR",
+	}
+}
+
+func TestMeasureBandwidth(t *testing.T) {
+	dir := writeMediaPlaylist(t)
+	defer os.RemoveAll(dir)
+
+	b, err := measureBandwidth(filepath.Join(dir, "stream_0.m3u8"))
+	if err != nil {
+		t.Fatal("Cannot measure bandwidth:", err)
+	}
+	if b.Peak != 2000000 || b.Average != 1428571 {
+		t.Errorf("Unexpected bandwidth: %+v", b)
+	}
+
+	m := masterPlaylist{
+		filename:           filepath.Join(dir, "master.m3u8"),
+		streamPlaylistName: "stream",
+		video:              []suggest.VideoVariant{testVideoVariant()},
+	}
+	if err := m.measure(); err != nil {
+		t.Fatal("Cannot measure media playlists:", err)
+	}
+	if err := m.write(nil); err != nil {
+		t.Fatal("Cannot write master playlist:", err)
+	}
+	master, _ := ioutil.ReadFile(m.filename)
+	if !strings.Contains(string(master), "BANDWIDTH=2000000,AVERAGE-BANDWIDTH=1428571,RESOLUTION=1920x1080,"+
+		"FRAME-RATE=23.976,VIDEO-RANGE=SDR,CODECS=\"avc1.640028\"") {
+		t.Error("Unexpected master playlist:", string(master))
+	}
+}
+
+func TestHEVCCopyVideoRange(t *testing.T) {
+	dir := writeMediaPlaylist(t)
+	defer os.RemoveAll(dir)
+	variant := testVideoVariant()
+	variant.Codecs, variant.Resolution, variant.VideoRange = "hvc1.2.4.L153.B0", "3840x2160", "PQ"
+	m := masterPlaylist{
+		filename:           filepath.Join(dir, "master.m3u8"),
+		streamPlaylistName: "stream",
+		video:              []suggest.VideoVariant{variant},
+	}
+	if err := m.measure(); err != nil {
+		t.Fatal("Cannot measure media playlists:", err)
+	}
+	if err := m.write(nil); err != nil {
+		t.Fatal("Cannot write master playlist:", err)
+	}
+	master, _ := ioutil.ReadFile(m.filename)
+	if !strings.Contains(string(master), "VIDEO-RANGE=PQ") {
+		t.Error("Unexpected master playlist of the HDR10 copy:", string(master))
+	}
+}
+
+func TestMasterRenditions(t *testing.T) {
+	// Built by hand, without SelectRenditions
+	m := masterPlaylist{
+		streamPlaylistName: "stream",
+		video:              []suggest.VideoVariant{testVideoVariant()},
+		audio: []suggest.AudioVariant{
+			{MapInput: "0:1", Codec: "aac", Type: suggest.StereoSound, Name: "English", Language: language.English},
+			{MapInput: "0:2", Codec: "aac", Type: suggest.StereoSound, Name: "English 2", Language: language.English},
+		},
+		subtitles: []SubtitleVariantConversion{
+			{Variant: suggest.SubtitleVariant{Name: "en-forced", Language: language.English, Forced: true}},
+			{Variant: suggest.SubtitleVariant{Name: "failed", Language: language.French}},
+		},
+	}
+	master := m.model(map[string]bool{"failed": true})
+	if len(master.Renditions) != 3 {
+		t.Fatal("Unexpected renditions:", master.Renditions)
+	}
+	if audio := master.Renditions[:2]; !audio[0].Default || !audio[0].Autoselect || audio[1].Default || audio[1].Autoselect {
+		t.Errorf("Unexpected DEFAULT and AUTOSELECT audio: %+v", audio)
+	}
+	if forced := master.Renditions[2]; !forced.Default || !forced.Forced {
+		t.Errorf("The forced subtitles of the DEFAULT audio should be DEFAULT: %+v", forced)
+	}
+	if m.audio[0].Default || m.subtitles[0].Variant.Default {
+		t.Error("The variants of the conversion should be left unchanged")
+	}
+}
+
+func TestDASHManifest(t *testing.T) {
+	dir := writeMediaPlaylist(t)
+	defer os.RemoveAll(dir)
+	m := masterPlaylist{
+		filename:           filepath.Join(dir, "master.m3u8"),
+		streamPlaylistName: "stream",
+		video:              []suggest.VideoVariant{testVideoVariant()},
+	}
+	if err := m.measure(); err != nil {
+		t.Fatal("Cannot measure media playlists:", err)
+	}
+	if err := m.writeDASH(nil); err != nil {
+		t.Fatal("Cannot write DASH manifest:", err)
+	}
+	mpd, _ := ioutil.ReadFile(filepath.Join(dir, "master.mpd"))
+	for _, expected := range []string{
+		`<MPD xmlns="urn:mpeg:dash:schema:mpd:2011" profiles="urn:mpeg:dash:profile:isoff-main:2011" type="static" mediaPresentationDuration="PT14S" minBufferTime="PT6S">`,
+		`<Representation id="stream_0" bandwidth="2000000" codecs="avc1.640028" width="1920" height="1080" frameRate="24000/1001">`,
+		`<Initialization sourceURL="init_0.mp4"></Initialization>`,
+		`<S d="6000" r="1"></S>`,
+		`<S d="2000"></S>`,
+		`<SegmentURL media="stream_0_2.m4s"></SegmentURL>`,
+	} {
+		if !strings.Contains(string(mpd), expected) {
+			t.Errorf("Expected %s in DASH manifest:\n%s", expected, mpd)
+		}
+	}
+}
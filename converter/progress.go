@@ -0,0 +1,150 @@
+package converter
+
+import (
+	"bufio"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/allezxandre/go-hls-encoder/probe"
+)
+
+// Progress A progress update of one of the phases of a conversion.
+type Progress struct {
+	Phase   Step   // EncodeStep, SubtitleStep, EncryptStep or IFrameStep
+	Name    string // Subtitle variant name, or variant playlist for EncryptStep and IFrameStep
+	OutTime time.Duration
+	Speed   float64 // Encoding speed relative to real-time. 0 if unknown
+	FPS     float64 // 0 if unknown
+	Bitrate string  // As reported by ffmpeg, e.g. "1043.2kbits/s"
+	Percent float64 // Between 0 and 100, or -1 if the total duration is unknown
+	Done    bool    // True for the last update of the phase
+}
+
+// progressArguments Makes ffmpeg write its progress to file descriptor 3.
+// See `progressPipe`.
+var progressArguments = []string{"-progress", "pipe:3"}
+
+// progressBufferSize The number of updates that can be waiting
+// on the progress channel before new ones are dropped.
+const progressBufferSize = 64
+
+// Progress Returns a channel on which progress updates are sent during the conversion.
+// Updates are dropped if the channel is not read fast enough.
+// The channel is closed when the conversion is over.
+func (c *Conversion) Progress() <-chan Progress {
+	return c.progress
+}
+
+// sendProgress Sends the update on the progress channel without blocking
+func (c *Conversion) sendProgress(p Progress) {
+	select {
+	case c.progress <- p:
+	default:
+	}
+}
+
+// progressPipe Adds a pipe to `cmd` as its file descriptor 3, so that it can be used
+// with `progressArguments`. The write end must be closed once the command is started.
+func progressPipe(cmd *exec.Cmd) (r, w *os.File, err error) {
+	r, w, err = os.Pipe()
+	if err != nil {
+		return nil, nil, err
+	}
+	cmd.ExtraFiles = append(cmd.ExtraFiles, w)
+	return r, w, nil
+}
+
+// watchProgress Reads ffmpeg progress reports from `r` until it is closed.
+func (c *Conversion) watchProgress(r io.ReadCloser, phase Step, name string) {
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		defer r.Close()
+		err := readProgress(r, func(p Progress) {
+			p.Phase = phase
+			p.Name = name
+			p.Percent = percentOf(p.OutTime, c.duration)
+			if p.Done {
+				p.Percent = 100
+			}
+			c.sendProgress(p)
+		})
+		if err != nil {
+			log.Println("Cannot read progress of", phase, name+":", err)
+		}
+	}()
+}
+
+// readProgress Parses the key=value blocks written by `ffmpeg -progress`,
+// and calls `f` at the end of each block.
+func readProgress(r io.Reader, f func(p Progress)) error {
+	scanner := bufio.NewScanner(r)
+	var current Progress
+	for scanner.Scan() {
+		parts := strings.SplitN(strings.TrimSpace(scanner.Text()), "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		key, value := parts[0], strings.TrimSpace(parts[1])
+		switch key {
+		case "out_time_us":
+			if us, err := strconv.ParseInt(value, 10, 64); err == nil {
+				current.OutTime = time.Duration(us) * time.Microsecond
+			}
+		case "fps":
+			current.FPS, _ = strconv.ParseFloat(value, 64)
+		case "bitrate":
+			current.Bitrate = value
+		case "speed":
+			current.Speed, _ = strconv.ParseFloat(strings.TrimSuffix(value, "x"), 64)
+		case "progress":
+			// Last key of a block
+			current.Done = value == "end"
+			f(current)
+			current = Progress{}
+		}
+	}
+	return scanner.Err()
+}
+
+// percentOf Returns the percentage of `total` that `t` represents,
+// or -1 if `total` is unknown.
+func percentOf(t, total time.Duration) float64 {
+	if total <= 0 {
+		return -1
+	}
+	percent := 100 * float64(t) / float64(total)
+	if percent > 100 {
+		percent = 100
+	}
+	return percent
+}
+
+// inputsDuration Returns the duration of the longest input,
+// as reported by `probe.ProbeFormat.DurationSeconds`, or 0 if unknown.
+func inputsDuration(inputs ...string) time.Duration {
+	probes, err := probe.GetProbeData(inputs...)
+	if err != nil {
+		log.Println("Cannot probe inputs for their duration:", err)
+		return 0
+	}
+	var longest time.Duration
+	for _, p := range probes {
+		if p.Format == nil {
+			continue
+		}
+		seconds, err := strconv.ParseFloat(p.Format.DurationSeconds, 64)
+		if err != nil {
+			continue
+		}
+		if d := time.Duration(seconds * float64(time.Second)); d > longest {
+			longest = d
+		}
+	}
+	return longest
+}
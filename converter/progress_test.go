@@ -0,0 +1,60 @@
+package converter
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+const progressOutput = `frame=240
+fps=47.90
+stream_0_0_q=28.0
+bitrate=1043.2kbits/s
+total_size=1310768
+out_time_us=10052000
+out_time_ms=10052000
+out_time=00:00:10.052000
+dup_frames=0
+drop_frames=0
+speed=2.01x
+progress=continue
+frame=300
+fps=N/A
+bitrate=N/A
+out_time_us=12500000
+speed=N/A
+progress=end
+`
+
+func TestReadProgress(t *testing.T) {
+	var updates []Progress
+	err := readProgress(strings.NewReader(progressOutput), func(p Progress) {
+		updates = append(updates, p)
+	})
+	if err != nil {
+		t.Error("Cannot read progress:", err)
+	}
+	if len(updates) != 2 {
+		t.Fatal("Unexpected number of updates:", len(updates))
+	}
+	first := updates[0]
+	if first.OutTime != 10052*time.Millisecond {
+		t.Error("Wrong out time. Expected 10.052s, got", first.OutTime)
+	}
+	if first.FPS != 47.9 || first.Speed != 2.01 || first.Bitrate != "1043.2kbits/s" {
+		t.Error("Wrong values:", first)
+	}
+	if first.Done {
+		t.Error("First update should not be the last")
+	}
+	last := updates[1]
+	if !last.Done || last.Speed != 0 || last.FPS != 0 {
+		t.Error("Wrong values for last update:", last)
+	}
+	if p := percentOf(last.OutTime, 25*time.Second); p != 50 {
+		t.Error("Wrong percentage. Expected 50, got", p)
+	}
+	if p := percentOf(last.OutTime, 0); p != -1 {
+		t.Error("Percentage should be unknown, got", p)
+	}
+}
@@ -0,0 +1,131 @@
+package converter
+
+import (
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Step Identifies one of the tasks a conversion is made of.
+type Step string
+
+const (
+	EncodeStep   Step = "encode"   // The main ffmpeg command (video & audio)
+	MasterStep   Step = "master"   // Writing the master playlist
+	SubtitleStep Step = "subtitle" // A subtitle ffmpeg command
+	SegmentStep  Step = "segment"  // A WebVTT segmenter
+	IFrameStep   Step = "iframe"   // Playlist enrichment & I-FRAME-ONLY playlists generation
+	EncryptStep  Step = "encrypt"  // Encryption of the video and audio segments
+	DASHStep     Step = "dash"     // Writing the DASH manifest
+	PackageStep  Step = "package"  // A low-latency packager, joining the parts of a media playlist
+)
+
+// StepResult The outcome of a single step of a conversion.
+type StepResult struct {
+	Step Step
+	Name string // Name of the variant the step relates to, if any
+	Err  error  // `nil` if the step succeeded
+}
+
+func (s StepResult) String() string {
+	name := string(s.Step)
+	if len(s.Name) > 0 {
+		name += " " + s.Name
+	}
+	if s.Err != nil {
+		return name + ": " + s.Err.Error()
+	}
+	return name + ": ok"
+}
+
+// ConversionError Lists all the steps of a conversion that failed.
+type ConversionError []StepResult
+
+func (e ConversionError) Error() string {
+	messages := make([]string, 0, len(e))
+	for _, s := range e {
+		messages = append(messages, s.String())
+	}
+	return "conversion failed: " + strings.Join(messages, "; ")
+}
+
+// Result Describes everything a conversion produced once all of its steps are done.
+// All paths are relative to `OutputDirectory`.
+type Result struct {
+	OutputDirectory string
+	MasterPlaylist  string
+	Playlists       []string // All playlists, including the master playlist and the DASH manifest
+	Segments        []string // Media segments, initialization sections included
+	Keys            []string // Key files written by the default key provider
+	LogFiles        []string
+	Steps           []StepResult // Every step that ran, in order of completion
+	Interrupted     error        // The context error, if the conversion was cancelled or timed out
+}
+
+// Err Returns the context error if the conversion was interrupted,
+// a ConversionError if any step failed, `nil` otherwise.
+func (r *Result) Err() error {
+	if r.Interrupted != nil {
+		return r.Interrupted
+	}
+	var failed ConversionError
+	for _, s := range r.Steps {
+		if s.Err != nil {
+			failed = append(failed, s)
+		}
+	}
+	if len(failed) > 0 {
+		return failed
+	}
+	return nil
+}
+
+// collectOutputs Fills the result lists with the files of
+// the output directory that were written after `since`.
+func (r *Result) collectOutputs(since time.Time) error {
+	// Some filesystems only have a 1-second precision
+	since = since.Truncate(time.Second)
+	infos, err := ioutil.ReadDir(r.OutputDirectory)
+	if err != nil {
+		return err
+	}
+	for _, info := range infos {
+		if info.IsDir() || info.ModTime().Before(since) {
+			continue
+		}
+		name := info.Name()
+		switch strings.ToLower(filepath.Ext(name)) {
+		case ".m3u8", ".mpd":
+			r.Playlists = append(r.Playlists, name)
+		case ".log":
+			r.LogFiles = append(r.LogFiles, name)
+		case ".ts", ".m4s", ".mp4", ".vtt", ".aac":
+			r.Segments = append(r.Segments, name)
+		case ".key":
+			r.Keys = append(r.Keys, name)
+		}
+	}
+	sort.Strings(r.Playlists)
+	sort.Strings(r.Segments)
+	sort.Strings(r.Keys)
+	sort.Strings(r.LogFiles)
+	return nil
+}
+
+// removePartialOutputs Removes the playlists, segments and keys of the result.
+// Log files are kept.
+func (r *Result) removePartialOutputs() {
+	for _, name := range append(append(r.Playlists, r.Segments...), r.Keys...) {
+		if err := os.Remove(filepath.Join(r.OutputDirectory, name)); err != nil && !os.IsNotExist(err) {
+			log.Println("Cannot remove partial output:", err)
+		}
+	}
+	r.MasterPlaylist = ""
+	r.Playlists = nil
+	r.Segments = nil
+	r.Keys = nil
+}
@@ -1,7 +1,10 @@
 package converter
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os/exec"
 
 	"github.com/allezxandre/go-hls-encoder/suggest"
@@ -16,7 +19,9 @@ import (
 type subtitleConversionCommand struct {
 	EncoderCommand  *exec.Cmd
 	OutputDir, Name string
-	Logfile         *os.File // The logfile to use, or Nil to use Stderr
+	TargetDuration  time.Duration // Target duration of the segments
+	PartDuration    time.Duration // Target duration of the parts of low-latency playlists, 0 for regular playlists
+	Logfile         *os.File      // The logfile to use, or Nil to use Stderr
 }
 
 type SubtitleVariantConversion struct {
@@ -24,9 +29,9 @@ type SubtitleVariantConversion struct {
 	commands *subtitleConversionCommand
 }
 
-// start Starts the conversion of the subtitles in a new goroutine,
-// and returns a channel that will be closed when the conversion is done.
-func (sCmds subtitleConversionCommand) start() error {
+// start Starts the conversion of the subtitles and returns a pipe
+// that outputs the converted WebVTT, and one that outputs progress reports.
+func (sCmds subtitleConversionCommand) start() (webvttPipe, progressPipeReader io.ReadCloser, err error) {
 	// Pipe Stderr to logfile
 	if sCmds.Logfile != nil {
 		sCmds.EncoderCommand.Stderr = sCmds.Logfile
@@ -34,38 +39,79 @@ func (sCmds subtitleConversionCommand) start() error {
 		sCmds.EncoderCommand.Stderr = os.Stderr
 	}
 	// Pipe Stdout to segmenter
-	webvttPipe, err := sCmds.EncoderCommand.StdoutPipe()
+	webvttPipe, err = sCmds.EncoderCommand.StdoutPipe()
 	if err != nil {
-		return err
+		return nil, nil, err
+	}
+	// Pipe progress
+	progressReader, progressWriter, err := progressPipe(sCmds.EncoderCommand)
+	if err != nil {
+		return nil, nil, err
 	}
 
 	// Debug
 	fmt.Println("\nDEBUG: FFMPEG Subtitle command:\n \"" + strings.Join(sCmds.EncoderCommand.Args, "\" \""))
 
-	// Launch segmenter
-	go webvtt.Segment(webvttPipe, 6*time.Second, sCmds.OutputDir, sCmds.Name)
-
 	err = sCmds.EncoderCommand.Start()
+	progressWriter.Close()
 	if err != nil {
-		return err
+		progressReader.Close()
+		return nil, nil, err
 	}
 
-	if err != nil {
-		return err
-	}
+	return webvttPipe, progressReader, nil
+}
 
-	return nil
+// segmentAndWait Segments the output of the subtitle conversion,
+// then waits for the conversion to complete.
+func (sCmds subtitleConversionCommand) segmentAndWait(ctx context.Context, webvttPipe io.Reader) (segmentErr, encodeErr error) {
+	if sCmds.PartDuration > 0 {
+		segmentErr = webvtt.SegmentPartsContext(ctx, webvttPipe, sCmds.TargetDuration, sCmds.PartDuration, sCmds.OutputDir, sCmds.Name)
+	} else {
+		segmentErr = webvtt.SegmentContext(ctx, webvttPipe, sCmds.TargetDuration, sCmds.OutputDir, sCmds.Name)
+	}
+	encodeErr = sCmds.EncoderCommand.Wait()
+	if sCmds.Logfile != nil {
+		sCmds.Logfile.Close()
+	}
+	return
 }
 
+// ErrLiveSubtitles Is recorded for the subtitle variants of live inputs, which are not converted:
+// their own ffmpeg command would open the live input a second time, which unicast inputs do not allow
+var ErrLiveSubtitles = errors.New("subtitles of live inputs are not supported")
+
 // callSubtitleConversions Starts all subtitle conversions asynchroneously.
-func callSubtitleConversions(variants []suggest.SubtitleVariant, outputDir string) (conversions []SubtitleVariantConversion) {
+func (c *Conversion) callSubtitleConversions(variants []suggest.SubtitleVariant, outputDir string) (conversions []SubtitleVariantConversion) {
+	if c.live {
+		for _, v := range variants {
+			log.Println("WARNING: Subtitle variant", v.Name, "not converted:", ErrLiveSubtitles)
+			c.record(SubtitleStep, v.Name, ErrLiveSubtitles)
+		}
+		return nil
+	}
 	for _, v := range variants {
-		cmds := convertSubtitle(v, outputDir)
-		err := cmds.start()
+		cmds := convertSubtitle(v, outputDir, c.packaging.TargetDuration())
+		if c.packaging.LowLatency != nil {
+			cmds.PartDuration = c.packaging.LowLatency.PartTarget()
+		}
+		webvttPipe, progressReader, err := cmds.start()
 		if err != nil {
 			log.Println("Cannot convert subtitle variant", v.Name, "\nError:", err)
+			if cmds.Logfile != nil {
+				cmds.Logfile.Close()
+			}
+			c.record(SubtitleStep, v.Name, err)
 			continue
 		}
+		c.watchProgress(progressReader, SubtitleStep, v.Name)
+		c.wg.Add(1)
+		go func(name string) {
+			defer c.wg.Done()
+			segmentErr, encodeErr := cmds.segmentAndWait(c.ctx, webvttPipe)
+			c.record(SegmentStep, name, segmentErr)
+			c.record(SubtitleStep, name, encodeErr)
+		}(v.Name)
 		conversions = append(conversions, SubtitleVariantConversion{
 			Variant:  v,
 			commands: &cmds,
@@ -74,9 +120,10 @@ func callSubtitleConversions(variants []suggest.SubtitleVariant, outputDir strin
 	return
 }
 
-func convertSubtitle(variant suggest.SubtitleVariant, outputDir string) subtitleConversionCommand {
+// convertSubtitle Returns the command converting `variant` to WebVTT
+func convertSubtitle(variant suggest.SubtitleVariant, outputDir string, targetDuration time.Duration) subtitleConversionCommand {
 	// Subtitle encoding // TODO: issue a ticket on FFMPEG: you can't encode & segment with the same command
-	args := ffmpegDefaultArguments()
+	args := append(ffmpegDefaultArguments(), progressArguments...)
 	// Add input
 	args = append(args, "-i", variant.InputURL)
 	// Map & codec
@@ -87,7 +134,7 @@ func convertSubtitle(variant suggest.SubtitleVariant, outputDir string) subtitle
 	encode := exec.Command("ffmpeg", args...)
 
 	// Set output file
-	logFilename := filepath.Join(outputDir, fmt.Sprint("conversion-%s.log", variant.Name))
+	logFilename := filepath.Join(outputDir, fmt.Sprintf("conversion-%s.log", variant.Name))
 	logFile, err := os.Create(logFilename)
 	if err != nil {
 		log.Println("Cannot create logfile for subtitle conversion command:", err)
@@ -99,6 +146,7 @@ func convertSubtitle(variant suggest.SubtitleVariant, outputDir string) subtitle
 		EncoderCommand: encode,
 		OutputDir:      outputDir,
 		Name:           variant.Name,
+		TargetDuration: targetDuration,
 		Logfile:        logFile,
 	}
 
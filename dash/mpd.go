@@ -0,0 +1,173 @@
+// Package dash models MPEG-DASH manifests (MPD) of on-demand presentations,
+// so that the fragmented MP4 segments of an HLS package can be served to DASH players too.
+// See ISO/IEC 23009-1 and the DASH-IF interoperability points.
+package dash
+
+import (
+	"encoding/xml"
+	"io"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// MainProfile The profile of the manifests, whose segments are listed one by one: see SegmentList
+const MainProfile = "urn:mpeg:dash:profile:isoff-main:2011"
+
+// Schemes of the descriptors
+const (
+	RoleScheme                 = "urn:mpeg:dash:role:2011"
+	AudioChannelScheme         = "urn:mpeg:dash:23003:3:audio_channel_configuration:2011"
+	TransferCharacteristicsURI = "urn:mpeg:mpegB:cicp:TransferCharacteristics"
+)
+
+// MPD A media presentation description
+type MPD struct {
+	XMLName                   xml.Name `xml:"urn:mpeg:dash:schema:mpd:2011 MPD"`
+	Profiles                  string   `xml:"profiles,attr"`
+	Type                      string   `xml:"type,attr"` // "static" for on-demand presentations
+	MediaPresentationDuration Duration `xml:"mediaPresentationDuration,attr"`
+	MinBufferTime             Duration `xml:"minBufferTime,attr"`
+	Periods                   []Period `xml:"Period"`
+}
+
+// Period A part of the presentation with the same adaptation sets
+type Period struct {
+	ID             string          `xml:"id,attr,omitempty"`
+	AdaptationSets []AdaptationSet `xml:"AdaptationSet"`
+}
+
+// AdaptationSet Representations players can switch between, e.g. the video variants of the same codec
+type AdaptationSet struct {
+	ID                  int              `xml:"id,attr"`
+	ContentType         string           `xml:"contentType,attr,omitempty"` // "video", "audio" or "text"
+	MimeType            string           `xml:"mimeType,attr"`
+	Lang                string           `xml:"lang,attr,omitempty"` // BCP 47 tag
+	SegmentAlignment    bool             `xml:"segmentAlignment,attr,omitempty"`
+	StartWithSAP        int              `xml:"startWithSAP,attr,omitempty"`
+	EssentialProperties []Descriptor     `xml:"EssentialProperty"` // Players that do not know them skip the set
+	Label               string           `xml:"Label,omitempty"`
+	Accessibility       []Descriptor     `xml:"Accessibility"`
+	Roles               []Descriptor     `xml:"Role"`
+	Representations     []Representation `xml:"Representation"`
+}
+
+// Representation An encoded version of the content
+type Representation struct {
+	ID                        string       `xml:"id,attr"`
+	Bandwidth                 int          `xml:"bandwidth,attr"` // Peak bitrate, in bits/s
+	Codecs                    string       `xml:"codecs,attr,omitempty"`
+	Width                     int          `xml:"width,attr,omitempty"`
+	Height                    int          `xml:"height,attr,omitempty"`
+	FrameRate                 string       `xml:"frameRate,attr,omitempty"` // e.g. "24000/1001"
+	AudioChannelConfiguration []Descriptor `xml:"AudioChannelConfiguration"`
+	BaseURL                   string       `xml:"BaseURL,omitempty"` // File of the representation, for side-loaded subtitles and single files
+	SegmentList               *SegmentList `xml:"SegmentList"`
+}
+
+// Descriptor A property of an adaptation set or representation, identified by its scheme
+type Descriptor struct {
+	SchemeIDURI string `xml:"schemeIdUri,attr"`
+	Value       string `xml:"value,attr,omitempty"`
+}
+
+// SegmentList The segments of a representation, listed one by one
+type SegmentList struct {
+	Timescale       int              `xml:"timescale,attr,omitempty"`
+	Initialization  *URL             `xml:"Initialization"`
+	SegmentTimeline *SegmentTimeline `xml:"SegmentTimeline"`
+	SegmentURLs     []SegmentURL     `xml:"SegmentURL"`
+}
+
+// URL The initialization segment of a representation
+type URL struct {
+	SourceURL string `xml:"sourceURL,attr,omitempty"`
+	Range     string `xml:"range,attr,omitempty"` // Byte range, e.g. "0-719"
+}
+
+// SegmentURL A media segment
+type SegmentURL struct {
+	Media      string `xml:"media,attr,omitempty"`
+	MediaRange string `xml:"mediaRange,attr,omitempty"` // Byte range, e.g. "720-50119"
+}
+
+// SegmentTimeline The durations of the segments
+type SegmentTimeline struct {
+	S []S `xml:"S"`
+}
+
+// S Segments of the same duration following each other
+type S struct {
+	D uint64 `xml:"d,attr"`           // Duration, in the timescale of the segment list
+	R int    `xml:"r,attr,omitempty"` // Number of segments after the first one
+}
+
+// Duration An xs:duration, e.g. "PT6S"
+type Duration time.Duration
+
+// MarshalXMLAttr Writes the duration in seconds, to the millisecond
+func (d Duration) MarshalXMLAttr(name xml.Name) (xml.Attr, error) {
+	seconds := time.Duration(d).Round(time.Millisecond).Seconds()
+	return xml.Attr{Name: name, Value: "PT" + strconv.FormatFloat(seconds, 'f', -1, 64) + "S"}, nil
+}
+
+// ByteRange Returns the byte range of `length` bytes at `offset`, e.g. "720-50119"
+func ByteRange(offset, length int64) string {
+	return strconv.FormatInt(offset, 10) + "-" + strconv.FormatInt(offset+length-1, 10)
+}
+
+// NewSegmentTimeline Returns the timeline of segments of `durations`, in `timescale` units per second.
+// Segment start times are rounded, not their durations, so that the timeline does not drift.
+func NewSegmentTimeline(durations []time.Duration, timescale int) *SegmentTimeline {
+	timeline := &SegmentTimeline{}
+	var elapsed time.Duration
+	start := uint64(0)
+	for _, d := range durations {
+		elapsed += d
+		end := uint64(math.Round(elapsed.Seconds() * float64(timescale)))
+		duration := end - start
+		start = end
+		if last := len(timeline.S) - 1; last >= 0 && timeline.S[last].D == duration {
+			timeline.S[last].R++
+		} else {
+			timeline.S = append(timeline.S, S{D: duration})
+		}
+	}
+	return timeline
+}
+
+// Encode Writes the manifest to `w`
+func (m *MPD) Encode(w io.Writer) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	e := xml.NewEncoder(w)
+	e.Indent("", "  ")
+	if err := e.Encode(m); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// WriteFile Writes the manifest to `filename`. The file is replaced atomically,
+// so that players never read a partial manifest.
+func (m *MPD) WriteFile(filename string) error {
+	f, err := ioutil.TempFile(filepath.Dir(filename), "."+filepath.Base(filename))
+	if err != nil {
+		return err
+	}
+	if err := m.Encode(f); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	return os.Rename(f.Name(), filename)
+}
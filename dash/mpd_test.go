@@ -0,0 +1,23 @@
+package dash
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestNewSegmentTimeline(t *testing.T) {
+	// 6.0006s segments: rounding their durations would drift by 0.6ms per segment
+	d := 6000600 * time.Microsecond
+	timeline := NewSegmentTimeline([]time.Duration{d, d, d, d, 2 * time.Second}, 1000)
+	expected := []S{{D: 6001}, {D: 6000}, {D: 6001}, {D: 6000}, {D: 2000}}
+	if !reflect.DeepEqual(timeline.S, expected) {
+		t.Errorf("Unexpected timeline %+v", timeline.S)
+	}
+
+	timeline = NewSegmentTimeline([]time.Duration{6 * time.Second, 6 * time.Second, 6 * time.Second, time.Second}, 1000)
+	expected = []S{{D: 6000, R: 2}, {D: 1000}}
+	if !reflect.DeepEqual(timeline.S, expected) {
+		t.Errorf("Unexpected timeline %+v", timeline.S)
+	}
+}
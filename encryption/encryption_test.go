@@ -0,0 +1,361 @@
+package encryption
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/binary"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func testKey() *Key {
+	key := &Key{URI: "key_0.key"}
+	copy(key.Value[:], "0123456789abcdef")
+	return key
+}
+
+// decryptSegment Reverts EncryptSegment
+func decryptSegment(t *testing.T, data []byte, key *Key, iv []byte) []byte {
+	block, err := aes.NewCipher(key.Value[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data)%aes.BlockSize != 0 {
+		t.Fatal("Encrypted segment size is not a multiple of the block size:", len(data))
+	}
+	decrypted := append([]byte{}, data...)
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(decrypted, decrypted)
+	return decrypted[:len(decrypted)-int(decrypted[len(decrypted)-1])]
+}
+
+func TestEncryptSegment(t *testing.T) {
+	key := testKey()
+	for _, size := range []int{0, 15, 16, 1000} {
+		data := bytes.Repeat([]byte{0x47}, size)
+		encrypted, err := EncryptSegment(data, key, sequenceIV(3))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if decrypted := decryptSegment(t, encrypted, key, sequenceIV(3)); !bytes.Equal(decrypted, data) {
+			t.Errorf("Segment of %d bytes changed after a round trip", size)
+		}
+	}
+}
+
+func TestEncryptPlaylist(t *testing.T) {
+	dir, err := ioutil.TempDir("", "encryption")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	segments := map[string][]byte{}
+	media := "#EXTM3U\n#EXT-X-VERSION:3\n#EXT-X-TARGETDURATION:6\n#EXT-X-MEDIA-SEQUENCE:0\n#EXT-X-PLAYLIST-TYPE:VOD\n"
+	for i, name := range []string{"stream_0_0.ts", "stream_0_1.ts", "stream_0_2.ts"} {
+		segments[name] = bytes.Repeat([]byte{byte(i)}, 188*(i+1))
+		if err := ioutil.WriteFile(filepath.Join(dir, name), segments[name], 0600); err != nil {
+			t.Fatal(err)
+		}
+		media += "#EXTINF:6.000000,\n" + name + "\n"
+	}
+	media += "#EXT-X-ENDLIST\n"
+	filename := filepath.Join(dir, "stream_0.m3u8")
+	if err := ioutil.WriteFile(filename, []byte(media), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	provider := FileKeyProvider{Dir: dir, URIPrefix: "https://keys.example.com/"}
+	first, err := EncryptPlaylist(filename, Options{Method: AES128, KeyRotation: 2}, provider)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first == nil || first.URI != "https://keys.example.com/key_0.key" {
+		t.Fatalf("Unexpected first key %+v", first)
+	}
+	data, _ := ioutil.ReadFile(filename)
+	lines := strings.Split(string(data), "\n")
+	expected := map[int]string{
+		5:  `#EXT-X-KEY:METHOD=AES-128,URI="https://keys.example.com/key_0.key"`,
+		10: `#EXT-X-KEY:METHOD=AES-128,URI="https://keys.example.com/key_1.key"`,
+	}
+	for i, line := range expected {
+		if len(lines) <= i || lines[i] != line {
+			t.Errorf("Expected line %d to be %s, got playlist:\n%s", i, line, data)
+		}
+	}
+	if strings.Count(string(data), "#EXT-X-KEY") != 2 {
+		t.Errorf("Expected 2 keys in playlist:\n%s", data)
+	}
+
+	// Segments decrypt with the key of their period and their media sequence number
+	for i, name := range []string{"stream_0_0.ts", "stream_0_1.ts", "stream_0_2.ts"} {
+		key, err := provider.Key(i / 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		encrypted, _ := ioutil.ReadFile(filepath.Join(dir, name))
+		if !bytes.Equal(decryptSegment(t, encrypted, key, sequenceIV(i)), segments[name]) {
+			t.Errorf("Segment %s does not decrypt", name)
+		}
+	}
+
+	if _, err := EncryptPlaylist(filename, Options{Method: AES128}, provider); err == nil {
+		t.Error("Encrypting a playlist twice should fail")
+	}
+}
+
+// testInit Returns a fragmented MP4 initialization segment with an AVC track of ID 1
+func testInit() []byte {
+	avc1 := &box{typ: "avc1", header: make([]byte, 78), children: []*box{
+		{typ: "avcC", header: []byte{1, 0x64, 0, 0x1f, 0xff, 0xe0, 0}},
+	}}
+	moov := &box{typ: "moov", children: []*box{
+		{typ: "trak", children: []*box{
+			{typ: "tkhd", header: fullBox(0, 3, uint32(0), uint32(0), uint32(1), make([]byte, 68))},
+			{typ: "mdia", children: []*box{
+				{typ: "hdlr", header: fullBox(0, 0, uint32(0), []byte("vide"), make([]byte, 13))},
+				{typ: "minf", children: []*box{
+					{typ: "stbl", children: []*box{
+						{typ: "stsd", header: fullBox(0, 0, uint32(1)), children: []*box{avc1}},
+					}},
+				}},
+			}},
+		}},
+		{typ: "mvex", children: []*box{
+			{typ: "trex", header: fullBox(0, 0, uint32(1), uint32(1), uint32(0), uint32(0), uint32(0))},
+		}},
+	}}
+	var buf bytes.Buffer
+	(&box{typ: "ftyp", header: []byte("iso6\x00\x00\x00\x00")}).write(&buf, nil)
+	moov.write(&buf, nil)
+	return buf.Bytes()
+}
+
+// testSamples Returns AVC samples with 4-byte NAL unit lengths: a parameter set and an IDR slice, then a slice
+func testSamples() [][]byte {
+	nal := func(header byte, size int) []byte {
+		n := make([]byte, 4+size)
+		binary.BigEndian.PutUint32(n, uint32(size))
+		n[4] = header
+		for i := 5; i < len(n); i++ {
+			n[i] = byte(i * 7)
+		}
+		return n
+	}
+	return [][]byte{
+		append(nal(0x67, 10), nal(0x65, 200)...),
+		nal(0x41, 90),
+	}
+}
+
+// testSegment Returns a media segment made of one 'moof' box and the 'mdat' box of `samples`
+func testSegment(samples [][]byte) []byte {
+	trun := &box{typ: "trun"}
+	fields := []interface{}{uint32(len(samples)), int32(0)}
+	var mdat []byte
+	for _, s := range samples {
+		fields = append(fields, uint32(len(s)))
+		mdat = append(mdat, s...)
+	}
+	trun.header = fullBox(0, trunDataOffset|trunSampleSize, fields...)
+	moof := &box{typ: "moof", children: []*box{
+		{typ: "mfhd", header: fullBox(0, 0, uint32(1))},
+		{typ: "traf", children: []*box{
+			{typ: "tfhd", header: fullBox(0, tfhdDefaultBaseIsMoof, uint32(1))},
+			{typ: "tfdt", header: fullBox(0, 0, uint32(0))},
+			trun,
+		}},
+	}}
+	binary.BigEndian.PutUint32(trun.header[8:], uint32(moof.size()+8))
+	var buf bytes.Buffer
+	moof.write(&buf, nil)
+	(&box{typ: "mdat", header: mdat}).write(&buf, nil)
+	return buf.Bytes()
+}
+
+func TestProtectSegment(t *testing.T) {
+	key := testKey()
+	samples := testSamples()
+	init, err := ProtectInit(testInit(), SampleAESCTR, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(init, []byte("encv")) || !bytes.Contains(init, []byte("tenc")) || !bytes.Contains(init, []byte("frma")) {
+		t.Error("The sample entry was not protected")
+	}
+	protected, err := ProtectSegment(testInit(), testSegment(samples), SampleAESCTR, key, 0x01000002)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	boxes, err := parseBoxes(protected)
+	if err != nil || len(boxes) != 2 {
+		t.Fatal("Cannot parse the protected segment:", err)
+	}
+	moof, mdat := boxes[0], boxes[1]
+	traf := moof.child("traf")
+	trun, senc, saio := traf.child("trun"), traf.child("senc"), traf.child("saio")
+	if senc == nil || saio == nil || traf.child("saiz") == nil {
+		t.Fatal("Missing auxiliary information boxes")
+	}
+	if offset := binary.BigEndian.Uint32(trun.header[8:]); int(offset) != moof.size()+8 {
+		t.Errorf("Data offset is %d instead of %d", offset, moof.size()+8)
+	}
+	// The 'saio' offset is relative to the 'moof' box, and points to the first IV
+	saioOffset := int(binary.BigEndian.Uint32(saio.header[8:]))
+	if !bytes.Equal(protected[saioOffset:saioOffset+cencIVSize], senc.header[8:8+cencIVSize]) {
+		t.Error("The 'saio' offset does not point to the first IV")
+	}
+
+	// Samples decrypt with the IVs and subsamples of the 'senc' box
+	block, _ := aes.NewCipher(key.Value[:])
+	info, data := senc.header[8:], mdat.header
+	for i, sample := range samples {
+		decrypted := append([]byte{}, data[:len(sample)]...)
+		data = data[len(sample):]
+		if bytes.Equal(decrypted, sample) {
+			t.Errorf("Sample %d is not encrypted", i)
+		}
+		iv := append(append([]byte{}, info[:cencIVSize]...), make([]byte, 8)...)
+		stream := cipher.NewCTR(block, iv)
+		count := int(binary.BigEndian.Uint16(info[cencIVSize:]))
+		info = info[cencIVSize+2:]
+		offset := 0
+		for j := 0; j < count; j++ {
+			clear, encrypted := int(binary.BigEndian.Uint16(info)), int(binary.BigEndian.Uint32(info[2:]))
+			info = info[6:]
+			offset += clear
+			stream.XORKeyStream(decrypted[offset:offset+encrypted], decrypted[offset:offset+encrypted])
+			offset += encrypted
+		}
+		if offset != len(sample) {
+			t.Errorf("Subsamples of sample %d cover %d bytes instead of %d", i, offset, len(sample))
+		}
+		if !bytes.Equal(decrypted, sample) {
+			t.Errorf("Sample %d does not decrypt", i)
+		}
+	}
+}
+
+// decryptSamples Decrypts the samples of the single track fragment of `segment` with the key of its 'seig'
+// sample group, which must be one of `keys`
+func decryptSamples(t *testing.T, segment []byte, samples [][]byte, keys []*Key) [][]byte {
+	boxes, err := parseBoxes(segment)
+	if err != nil || len(boxes) != 2 {
+		t.Fatal("Cannot parse the protected segment:", err)
+	}
+	traf, mdat := boxes[0].child("traf"), boxes[1]
+	sbgp, sgpd, senc := traf.child("sbgp"), traf.child("sgpd"), traf.child("senc")
+	if sbgp == nil || sgpd == nil || senc == nil {
+		t.Fatal("Missing sample group or auxiliary information boxes")
+	}
+	if string(sbgp.header[4:8]) != "seig" || binary.BigEndian.Uint32(sbgp.header[12:]) != uint32(len(samples)) ||
+		binary.BigEndian.Uint32(sbgp.header[16:]) != seigGroupIndex {
+		t.Fatal("The samples are not mapped to the 'seig' group of the fragment")
+	}
+	entry := sgpd.header[16:] // Full box header, grouping type, default length and entry count
+	if string(sgpd.header[4:8]) != "seig" || entry[2] != 1 {
+		t.Fatal("Unexpected 'seig' group", sgpd.header)
+	}
+	var key *Key
+	for _, k := range keys {
+		if bytes.Equal(k.ID[:], entry[4:4+KeySize]) {
+			key = k
+		}
+	}
+	if key == nil {
+		t.Fatal("Unknown key ID in the 'seig' group")
+	}
+	ivSize := int(entry[3])
+	var constantIV []byte
+	if ivSize == 0 {
+		constantIV = entry[4+KeySize+1 : 4+KeySize+1+int(entry[4+KeySize])]
+	}
+
+	block, _ := aes.NewCipher(key.Value[:])
+	info, data := senc.header[8:], mdat.header
+	var decrypted [][]byte
+	for _, sample := range samples {
+		d := append([]byte{}, data[:len(sample)]...)
+		data = data[len(sample):]
+		iv := append(append([]byte{}, info[:ivSize]...), make([]byte, KeySize-ivSize)...)
+		stream := cipher.NewCTR(block, iv)
+		count := int(binary.BigEndian.Uint16(info[ivSize:]))
+		info = info[ivSize+2:]
+		offset := 0
+		for j := 0; j < count; j++ {
+			clear, encrypted := int(binary.BigEndian.Uint16(info)), int(binary.BigEndian.Uint32(info[2:]))
+			info = info[6:]
+			offset += clear
+			r := d[offset : offset+encrypted]
+			if constantIV == nil {
+				stream.XORKeyStream(r, r)
+			} else {
+				// 'cbcs': the first block of every 10 is encrypted, from the constant IV in each subsample
+				mode := cipher.NewCBCDecrypter(block, constantIV)
+				for i := 0; i+aes.BlockSize <= len(r); i += aes.BlockSize * (cbcsCryptBlocks + cbcsSkipBlocks) {
+					mode.CryptBlocks(r[i:i+aes.BlockSize], r[i:i+aes.BlockSize])
+				}
+			}
+			offset += encrypted
+		}
+		decrypted = append(decrypted, d)
+	}
+	return decrypted
+}
+
+func TestSampleEncryptionKeyRotation(t *testing.T) {
+	for _, method := range []Method{SampleAES, SampleAESCTR} {
+		dir, err := ioutil.TempDir("", "encryption")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.RemoveAll(dir)
+		samples := testSamples()
+		media := "#EXTM3U\n#EXT-X-VERSION:7\n#EXT-X-TARGETDURATION:6\n#EXT-X-MEDIA-SEQUENCE:0\n#EXT-X-PLAYLIST-TYPE:VOD\n" +
+			"#EXT-X-MAP:URI=\"init_0.mp4\"\n"
+		files := map[string][]byte{"init_0.mp4": testInit()}
+		for _, name := range []string{"stream_0_0.m4s", "stream_0_1.m4s", "stream_0_2.m4s"} {
+			files[name] = testSegment(samples)
+			media += "#EXTINF:6.000000,\n" + name + "\n"
+		}
+		files["stream_0.m3u8"] = []byte(media + "#EXT-X-ENDLIST\n")
+		for name, data := range files {
+			if err := ioutil.WriteFile(filepath.Join(dir, name), data, 0600); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		provider := FileKeyProvider{Dir: dir}
+		if _, err := EncryptPlaylist(filepath.Join(dir, "stream_0.m3u8"), Options{Method: method, KeyRotation: 2}, provider); err != nil {
+			t.Fatal(method, err)
+		}
+		var keys []*Key
+		for period := 0; period < 2; period++ {
+			key, err := provider.Key(period)
+			if err != nil {
+				t.Fatal(err)
+			}
+			keys = append(keys, key)
+		}
+		// The initialization segment only knows the first key
+		init, _ := ioutil.ReadFile(filepath.Join(dir, "init_0.mp4"))
+		if !bytes.Contains(init, keys[0].ID[:]) || bytes.Contains(init, keys[1].ID[:]) {
+			t.Errorf("%s: unexpected key IDs in the initialization segment", method)
+		}
+		for i, name := range []string{"stream_0_0.m4s", "stream_0_2.m4s"} {
+			segment, _ := ioutil.ReadFile(filepath.Join(dir, name))
+			if !bytes.Contains(segment, keys[i].ID[:]) {
+				t.Errorf("%s: %s does not announce key %d", method, name, i)
+			}
+			for j, decrypted := range decryptSamples(t, segment, samples, keys) {
+				if !bytes.Equal(decrypted, samples[j]) {
+					t.Errorf("%s: sample %d of %s does not decrypt", method, j, name)
+				}
+			}
+		}
+	}
+}
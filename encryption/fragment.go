@@ -0,0 +1,408 @@
+package encryption
+
+import (
+	"bytes"
+	"crypto/aes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// Flags of the 'tfhd' and 'trun' boxes
+const (
+	tfhdBaseDataOffset       = 0x1
+	tfhdSampleDescription    = 0x2
+	tfhdDefaultDuration      = 0x8
+	tfhdDefaultSize          = 0x10
+	tfhdDefaultFlags         = 0x20
+	tfhdDefaultBaseIsMoof    = 0x20000
+	trunDataOffset           = 0x1
+	trunFirstSampleFlags     = 0x4
+	trunSampleDuration       = 0x100
+	trunSampleSize           = 0x200
+	trunSampleFlags          = 0x400
+	trunSampleCompositionOff = 0x800
+	sencSubsamples           = 0x2
+	seigGroupIndex           = 0x10001 // The first group description of the track fragment, not of the track
+)
+
+// topBox A top-level box of a media segment, and where it was
+type topBox struct {
+	typ        string
+	start, end int
+	moof       *box // Parsed and protected 'moof' boxes only
+	delta      int  // How much larger the 'moof' box got
+}
+
+// trackRun The samples of a 'trun' box, and where its data offset is written
+type trackRun struct {
+	header     []byte // Payload of the 'trun' box
+	dataOffset int    // Offset of the data offset in `header`, or -1 if the box has none
+	sizes      []uint32
+}
+
+// ProtectSegment Encrypts the samples of a fragmented MP4 media segment, whose initialization segment
+// is `init`, and adds their auxiliary information to its track fragments ('senc', 'saiz' and 'saio').
+// `ivPrefix` must be unique for every segment of every variant encrypted with `key`:
+// it is the start of the IVs of the 'cenc' samples.
+func ProtectSegment(init, data []byte, method Method, key *Key, ivPrefix uint32) ([]byte, error) {
+	// Sample entries are read from the clear initialization segment
+	_, tracks, err := protectInit(init, method, key)
+	if err != nil {
+		return nil, err
+	}
+	return protectSegment(data, tracks, method, key, ivPrefix, false)
+}
+
+// protectSegment Encrypts the samples of a media segment like ProtectSegment. If `sampleGroups`, the track
+// fragments get a 'seig' sample group with the key ID and constant IV of `key`, which replace those of the
+// 'tenc' boxes of the initialization segment: this is how the segments of the other rotation periods are protected.
+func protectSegment(data []byte, tracks map[uint32]*track, method Method, key *Key, ivPrefix uint32, sampleGroups bool) ([]byte, error) {
+	schemeType, err := scheme(method)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key.Value[:])
+	if err != nil {
+		return nil, err
+	}
+	encrypter := &sampleEncrypter{schemeType: schemeType, key: key, block: block, ivPrefix: ivPrefix, sampleGroups: sampleGroups}
+	data = append([]byte{}, data...) // Encrypted in place
+	var top []*topBox
+	for offset := 0; offset < len(data); {
+		size, _, typ, err := boxHeader(data[offset:])
+		if err != nil {
+			return nil, err
+		}
+		top = append(top, &topBox{typ: typ, start: offset, end: offset + size})
+		offset += size
+	}
+
+	// Samples are encrypted in their 'mdat', and the 'moof' boxes get their auxiliary information
+	shift := 0 // How much later the current 'moof' box starts once protected
+	for _, b := range top {
+		if b.typ != "moof" {
+			continue
+		}
+		moofs, err := parseBoxes(data[b.start:b.end])
+		if err != nil {
+			return nil, err
+		}
+		b.moof = moofs[0]
+		if b.delta, err = protectMoof(data, b.moof, b.start, shift, tracks, encrypter); err != nil {
+			return nil, err
+		}
+		shift += b.delta
+	}
+
+	var buf bytes.Buffer
+	for i, b := range top {
+		switch b.typ {
+		case "moof":
+			b.moof.write(&buf, nil)
+		case "sidx":
+			sidx, err := protectSidx(data[b.start:b.end], top[i+1:])
+			if err != nil {
+				return nil, err
+			}
+			buf.Write(sidx)
+		default:
+			buf.Write(data[b.start:b.end])
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// protectMoof Encrypts the samples of the track fragments of `moof`, which is at `start` in `data`,
+// and adds their auxiliary information. Once protected, the box starts `shift` bytes later.
+// Returns how much larger the box got.
+func protectMoof(data []byte, moof *box, start, shift int, tracks map[uint32]*track, e *sampleEncrypter) (int, error) {
+	type fragment struct {
+		traf       *box
+		tfhd       []byte
+		runs       []*trackRun
+		base       int  // Base data offset in the original data
+		explicit   bool // If true, the base data offset is written in the 'tfhd' box
+		senc, saio *box
+		added      []*box // Boxes added to the track fragment
+	}
+	var fragments []*fragment
+	end := start + moof.size()
+	nextBase := start // Without base data offset, the data of track fragments follow each other
+	trafIndex := -1
+	for _, traf := range moof.children {
+		if traf.typ != "traf" {
+			continue
+		}
+		trafIndex++
+		if traf.child("senc") != nil || traf.child("saiz") != nil {
+			return 0, errors.New("the segment is already encrypted")
+		}
+		tfhd := traf.child("tfhd")
+		if tfhd == nil || len(tfhd.header) < 8 {
+			return 0, errTruncated
+		}
+		_, flags, _ := versionAndFlags(tfhd.header)
+		// Fragments of other tracks are kept clear, but their data moves too
+		t, protected := tracks[binary.BigEndian.Uint32(tfhd.header[4:])]
+		f := &fragment{traf: traf, tfhd: tfhd.header, base: nextBase}
+		fragments = append(fragments, f)
+		if trafIndex == 0 || flags&tfhdDefaultBaseIsMoof != 0 {
+			f.base = start
+		}
+		fieldOffset := 8
+		var defaultSize uint32
+		if protected {
+			defaultSize = t.defaultSize
+		}
+		if flags&tfhdBaseDataOffset != 0 {
+			if len(tfhd.header) < fieldOffset+8 {
+				return 0, errTruncated
+			}
+			f.base, f.explicit = int(binary.BigEndian.Uint64(tfhd.header[fieldOffset:])), true
+			fieldOffset += 8
+		}
+		for _, flag := range []uint32{tfhdSampleDescription, tfhdDefaultDuration} {
+			if flags&flag != 0 {
+				fieldOffset += 4
+			}
+		}
+		if flags&tfhdDefaultSize != 0 {
+			if len(tfhd.header) < fieldOffset+4 {
+				return 0, errTruncated
+			}
+			defaultSize = binary.BigEndian.Uint32(tfhd.header[fieldOffset:])
+		}
+
+		// Samples
+		var infos []sampleInfo
+		position := f.base
+		for _, trun := range traf.children {
+			if trun.typ != "trun" {
+				continue
+			}
+			run, err := parseTrackRun(trun.header, defaultSize)
+			if err != nil {
+				return 0, err
+			}
+			if run.dataOffset >= 0 {
+				position = f.base + int(int32(binary.BigEndian.Uint32(run.header[run.dataOffset:])))
+			}
+			for _, size := range run.sizes {
+				if !protected {
+					position += int(size)
+					continue
+				}
+				if position < 0 || position+int(size) > len(data) {
+					return 0, fmt.Errorf("track %d: sample out of the segment", t.id)
+				}
+				info, err := e.encrypt(data[position:position+int(size)], t)
+				if err != nil {
+					return 0, fmt.Errorf("track %d: %v", t.id, err)
+				}
+				infos = append(infos, info)
+				position += int(size)
+			}
+			f.runs = append(f.runs, run)
+		}
+		nextBase = position
+		if !protected {
+			continue
+		}
+		if e.sampleGroups && len(infos) > 0 {
+			f.added = append(f.added, e.sampleGroup(traf, len(infos), t)...)
+		}
+		f.senc, f.saio = auxiliaryInformation(traf, infos)
+		if f.senc != nil {
+			f.added = append(f.added, traf.child("saiz"), f.saio, f.senc)
+		}
+	}
+
+	// The data after the 'moof' box moves by `shift` and `delta`, and base data offsets
+	// move with what they point to: the 'moof' box, or the data after it
+	delta := 0
+	for _, f := range fragments {
+		for _, b := range f.added {
+			delta += b.size()
+		}
+	}
+	baseMove := func(f *fragment) int {
+		if f.base >= end {
+			return shift + delta
+		}
+		return shift
+	}
+	for _, f := range fragments {
+		if f.explicit {
+			binary.BigEndian.PutUint64(f.tfhd[8:], uint64(f.base+baseMove(f)))
+		}
+		for _, run := range f.runs {
+			if run.dataOffset >= 0 {
+				offset := int(int32(binary.BigEndian.Uint32(run.header[run.dataOffset:])))
+				binary.BigEndian.PutUint32(run.header[run.dataOffset:], uint32(int32(offset+shift+delta-baseMove(f))))
+			}
+		}
+	}
+	// 'saio' offsets point to the first sample of the 'senc' boxes, relative to the base data offset
+	offsets := map[*box]int{}
+	var buf bytes.Buffer
+	moof.write(&buf, offsets)
+	for _, f := range fragments {
+		if f.senc == nil {
+			continue
+		}
+		sencData := start + shift + offsets[f.senc] + 8 + 8 // Box header, full box header and sample count
+		sencData -= f.base + baseMove(f)
+		if sencData < 0 {
+			return 0, errors.New("the base data offset of a track fragment is after its auxiliary information")
+		}
+		f.saio.header = fullBox(0, 0, uint32(1), uint32(sencData))
+	}
+	return delta, nil
+}
+
+// parseTrackRun Returns the sample sizes of a 'trun' box
+func parseTrackRun(header []byte, defaultSize uint32) (*trackRun, error) {
+	_, flags, err := versionAndFlags(header)
+	if err != nil || len(header) < 8 {
+		return nil, errTruncated
+	}
+	run := &trackRun{header: header, dataOffset: -1}
+	count := int(binary.BigEndian.Uint32(header[4:]))
+	offset := 8
+	if flags&trunDataOffset != 0 {
+		run.dataOffset = offset
+		offset += 4
+	}
+	if flags&trunFirstSampleFlags != 0 {
+		offset += 4
+	}
+	fieldSize := 0
+	for _, flag := range []uint32{trunSampleDuration, trunSampleSize, trunSampleFlags, trunSampleCompositionOff} {
+		if flags&flag != 0 {
+			fieldSize += 4
+		}
+	}
+	if len(header) < offset+count*fieldSize {
+		return nil, errTruncated
+	}
+	sizeOffset := 0
+	if flags&trunSampleDuration != 0 {
+		sizeOffset = 4
+	}
+	for i := 0; i < count; i++ {
+		size := defaultSize
+		if flags&trunSampleSize != 0 {
+			size = binary.BigEndian.Uint32(header[offset+i*fieldSize+sizeOffset:])
+		}
+		run.sizes = append(run.sizes, size)
+	}
+	return run, nil
+}
+
+// auxiliaryInformation Adds the 'senc', 'saiz' and 'saio' boxes of `infos` to `traf`.
+// The offset of the 'saio' box is set once the 'moof' box is written.
+// Returns `nil` boxes if the samples have no auxiliary information, e.g. 'cbcs' audio.
+func auxiliaryInformation(traf *box, infos []sampleInfo) (senc, saio *box) {
+	withSubsamples := false
+	for _, info := range infos {
+		withSubsamples = withSubsamples || len(info.subsamples) > 0
+	}
+	var sencData bytes.Buffer
+	sizes := make([]byte, len(infos))
+	sameSize := true
+	for i, info := range infos {
+		sencData.Write(info.iv)
+		if withSubsamples {
+			binary.Write(&sencData, binary.BigEndian, uint16(len(info.subsamples)))
+			for _, s := range info.subsamples {
+				binary.Write(&sencData, binary.BigEndian, uint16(s.clear))
+				binary.Write(&sencData, binary.BigEndian, uint32(s.protected))
+			}
+		}
+		sizes[i] = byte(info.size(withSubsamples))
+		sameSize = sameSize && sizes[i] == sizes[0]
+	}
+	if sencData.Len() == 0 {
+		return nil, nil
+	}
+	var flags uint32
+	if withSubsamples {
+		flags = sencSubsamples
+	}
+	senc = &box{typ: "senc", header: fullBox(0, flags, uint32(len(infos)), sencData.Bytes())}
+	saiz := &box{typ: "saiz", header: fullBox(0, 0, sizes[0], uint32(len(infos)))}
+	if !sameSize {
+		saiz.header = fullBox(0, 0, byte(0), uint32(len(infos)), sizes)
+	}
+	saio = &box{typ: "saio", header: fullBox(0, 0, uint32(1), uint32(0))}
+	traf.children = append(traf.children, saiz, saio, senc)
+	return senc, saio
+}
+
+// sampleGroup Adds to `traf` the 'seig' sample group of its `count` samples, which describes the key
+// of the encrypter. Returns the boxes added.
+func (e *sampleEncrypter) sampleGroup(traf *box, count int, t *track) []*box {
+	entry := encryptionInfo(e.schemeType, e.key, t.video)
+	sbgp := &box{typ: "sbgp", header: fullBox(0, 0, []byte("seig"), uint32(1), uint32(count), uint32(seigGroupIndex))}
+	sgpd := &box{typ: "sgpd", header: fullBox(1, 0, []byte("seig"), uint32(len(entry)), uint32(1), entry)}
+	traf.children = append(traf.children, sbgp, sgpd)
+	return []*box{sbgp, sgpd}
+}
+
+// protectSidx Returns the 'sidx' box `data` whose referenced sizes include the growth
+// of the protected 'moof' boxes of `following`, the boxes after it
+func protectSidx(data []byte, following []*topBox) ([]byte, error) {
+	sidx := append([]byte{}, data...)
+	_, headerSize, _, err := boxHeader(sidx)
+	if err != nil {
+		return nil, err
+	}
+	payload := sidx[headerSize:]
+	version, _, err := versionAndFlags(payload)
+	if err != nil {
+		return nil, err
+	}
+	offset := 12 // Full box header, reference ID and timescale
+	var firstOffset uint64
+	if version == 0 {
+		if len(payload) < offset+8 {
+			return nil, errTruncated
+		}
+		firstOffset = uint64(binary.BigEndian.Uint32(payload[offset+4:]))
+		offset += 8
+	} else {
+		if len(payload) < offset+16 {
+			return nil, errTruncated
+		}
+		firstOffset = binary.BigEndian.Uint64(payload[offset+8:])
+		offset += 16
+	}
+	if len(payload) < offset+4 {
+		return nil, errTruncated
+	}
+	count := int(binary.BigEndian.Uint16(payload[offset+2:]))
+	offset += 4
+	if len(payload) < offset+12*count {
+		return nil, errTruncated
+	}
+	if len(following) == 0 {
+		return sidx, nil
+	}
+	// References follow each other, starting `firstOffset` bytes after the 'sidx' box
+	referenceStart := following[0].start + int(firstOffset)
+	for i := 0; i < count; i++ {
+		field := payload[offset+12*i:]
+		reference := binary.BigEndian.Uint32(field)
+		size := int(reference & 0x7fffffff)
+		delta := 0
+		for _, b := range following {
+			if b.start >= referenceStart && b.start < referenceStart+size {
+				delta += b.delta
+			}
+		}
+		binary.BigEndian.PutUint32(field, reference&0x80000000|uint32(size+delta))
+		referenceStart += size
+	}
+	return sidx, nil
+}
@@ -0,0 +1,112 @@
+// Package encryption protects HLS media segments: full-segment AES-128 for MPEG-TS,
+// and ISO common encryption of the samples of fragmented MP4 ('cbcs' for SAMPLE-AES,
+// 'cenc' for SAMPLE-AES-CTR). Keys come from a KeyProvider and can rotate every few segments.
+// See https://tools.ietf.org/html/rfc8216#section-4.3.2.4
+package encryption
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/allezxandre/go-hls-encoder/playlist"
+)
+
+// Method The METHOD of an EXT-X-KEY tag
+type Method string
+
+const (
+	None         Method = ""
+	AES128       Method = "AES-128"        // Full segments, AES-128-CBC with PKCS7 padding. MPEG-TS
+	SampleAES    Method = "SAMPLE-AES"     // ISO common encryption 'cbcs' scheme. Fragmented MP4
+	SampleAESCTR Method = "SAMPLE-AES-CTR" // ISO common encryption 'cenc' scheme. Fragmented MP4
+)
+
+// KeySize The size of AES-128 keys, key IDs and IVs
+const KeySize = 16
+
+// Key A content key and how players get it
+type Key struct {
+	Value [KeySize]byte
+	ID    [KeySize]byte // Key ID written in MP4 files
+	IV    []byte        // Optional IV of KeySize bytes. See Method for how it is used when `nil`
+	URI   string        // Where players get the key. Required
+
+	// Optional, for keys that are not the raw 16 bytes, e.g. FairPlay's "com.apple.streamingkeydelivery"
+	KeyFormat         string
+	KeyFormatVersions string
+}
+
+// Tag Returns the EXT-X-KEY, or EXT-X-SESSION-KEY, attributes of the key for `method`
+func (k *Key) Tag(method Method) playlist.Key {
+	t := playlist.Key{
+		Method:            string(method),
+		URI:               k.URI,
+		KeyFormat:         k.KeyFormat,
+		KeyFormatVersions: k.KeyFormatVersions,
+	}
+	if len(k.IV) > 0 {
+		t.IV = "0x" + hex.EncodeToString(k.IV)
+	}
+	return t
+}
+
+// constantIV Returns the IV of the key, or one derived from it
+func (k *Key) constantIV() []byte {
+	if len(k.IV) == KeySize {
+		return k.IV
+	}
+	sum := sha256.Sum256(append([]byte("iv"), k.Value[:]...))
+	return sum[:KeySize]
+}
+
+// KeyProvider Provides the keys of an encoded package
+type KeyProvider interface {
+	// Key Returns the key of the `period`-th rotation period, starting from 0.
+	// All the variants of a package use the same key for the same period.
+	Key(period int) (*Key, error)
+}
+
+// FileKeyProvider A KeyProvider of keys stored as 16-byte files in a directory, e.g. "key_0.key".
+// Missing keys are randomly generated and written there.
+type FileKeyProvider struct {
+	Dir       string // Directory of the key files
+	URIPrefix string // Prefix of the key URIs, e.g. "https://keys.example.com/movie/". Empty for relative URIs
+}
+
+// KeyFilename Returns the filename of the key of rotation period `period`
+func KeyFilename(period int) string {
+	return fmt.Sprintf("key_%d.key", period)
+}
+
+// Key Reads the key file of `period`, or generates it
+func (p FileKeyProvider) Key(period int) (*Key, error) {
+	filename := filepath.Join(p.Dir, KeyFilename(period))
+	value, err := ioutil.ReadFile(filename)
+	if os.IsNotExist(err) {
+		value = make([]byte, KeySize)
+		if _, err = rand.Read(value); err != nil {
+			return nil, err
+		}
+		err = ioutil.WriteFile(filename, value, 0600)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(value) != KeySize {
+		return nil, fmt.Errorf("key file %s is not %d bytes long", filename, KeySize)
+	}
+	key := &Key{URI: strings.TrimSuffix(p.URIPrefix, "/") + "/" + KeyFilename(period)}
+	if len(p.URIPrefix) == 0 {
+		key.URI = KeyFilename(period)
+	}
+	copy(key.Value[:], value)
+	id := sha256.Sum256(append([]byte("kid"), value...))
+	copy(key.ID[:], id[:KeySize])
+	return key, nil
+}
@@ -0,0 +1,156 @@
+package encryption
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+var errTruncated = errors.New("truncated MP4 box")
+
+// box An MP4 box. The boxes we go through are parsed into their children,
+// the others are kept as they are.
+type box struct {
+	typ      string
+	header   []byte // Fields preceding the children of containers, or the whole payload of other boxes
+	children []*box
+}
+
+// containers The size of the fields preceding the children of the container boxes we go through.
+// Sample entries are containers too, see childrenOffset.
+var containers = map[string]int{
+	"moov": 0, "trak": 0, "mdia": 0, "minf": 0, "stbl": 0, "mvex": 0, "moof": 0, "traf": 0,
+	"sinf": 0, "schi": 0, "stsd": 8,
+	"avc1": 78, "avc3": 78, "hvc1": 78, "hev1": 78, "dvh1": 78, "dvhe": 78, "encv": 78,
+	"mp4a": 28, "ac-3": 28, "ec-3": 28, "enca": 28,
+}
+
+// childrenOffset Returns the offset of the children in the payload of a container
+func childrenOffset(typ string, payload []byte) int {
+	offset := containers[typ]
+	if offset == 28 && len(payload) >= 10 {
+		// QuickTime sound sample descriptions have more fields
+		switch binary.BigEndian.Uint16(payload[8:]) {
+		case 1:
+			offset += 16
+		case 2:
+			offset += 36
+		}
+	}
+	return offset
+}
+
+// boxHeader Reads the size and type of the box at the start of `data`.
+// Returns the size of the box and of its header.
+func boxHeader(data []byte) (size, headerSize int, typ string, err error) {
+	if len(data) < 8 {
+		return 0, 0, "", errTruncated
+	}
+	size, headerSize, typ = int(binary.BigEndian.Uint32(data)), 8, string(data[4:8])
+	switch size {
+	case 0: // Up to the end of the file
+		size = len(data)
+	case 1:
+		if len(data) < 16 {
+			return 0, 0, "", errTruncated
+		}
+		size, headerSize = int(binary.BigEndian.Uint64(data[8:])), 16
+	}
+	if size < headerSize || size > len(data) {
+		return 0, 0, "", errTruncated
+	}
+	return
+}
+
+// parseBoxes Parses the boxes of `data`
+func parseBoxes(data []byte) ([]*box, error) {
+	var boxes []*box
+	for len(data) > 0 {
+		size, headerSize, typ, err := boxHeader(data)
+		if err != nil {
+			return nil, err
+		}
+		payload := data[headerSize:size]
+		b := &box{typ: typ, header: payload}
+		if _, ok := containers[typ]; ok {
+			offset := childrenOffset(typ, payload)
+			if offset > len(payload) {
+				return nil, errTruncated
+			}
+			b.header = payload[:offset]
+			if b.children, err = parseBoxes(payload[offset:]); err != nil {
+				return nil, fmt.Errorf("%s: %v", typ, err)
+			}
+		}
+		boxes = append(boxes, b)
+		data = data[size:]
+	}
+	return boxes, nil
+}
+
+// size Returns the size of the box once written
+func (b *box) size() int {
+	size := 8 + len(b.header)
+	for _, c := range b.children {
+		size += c.size()
+	}
+	return size
+}
+
+// write Writes the box to `buf`. If not `nil`, `offsets` records the offset of every box written.
+func (b *box) write(buf *bytes.Buffer, offsets map[*box]int) {
+	if offsets != nil {
+		offsets[b] = buf.Len()
+	}
+	var header [8]byte
+	binary.BigEndian.PutUint32(header[:], uint32(b.size()))
+	copy(header[4:], b.typ)
+	buf.Write(header[:])
+	buf.Write(b.header)
+	for _, c := range b.children {
+		c.write(buf, offsets)
+	}
+}
+
+// child Returns the first child of type `typ`, or `nil`
+func (b *box) child(typ string) *box {
+	for _, c := range b.children {
+		if c.typ == typ {
+			return c
+		}
+	}
+	return nil
+}
+
+// path Returns the first descendant at `path`, e.g. "mdia", "minf", "stbl", or `nil`
+func (b *box) path(path ...string) *box {
+	for _, typ := range path {
+		if b = b.child(typ); b == nil {
+			return nil
+		}
+	}
+	return b
+}
+
+// fullBox Returns the payload of a full box: its version, flags, and the fields that follow
+func fullBox(version byte, flags uint32, fields ...interface{}) []byte {
+	return boxFields(append([]interface{}{uint32(version)<<24 | flags&0xffffff}, fields...)...)
+}
+
+// boxFields Returns the big-endian fields of a box payload
+func boxFields(fields ...interface{}) []byte {
+	var buf bytes.Buffer
+	for _, f := range fields {
+		binary.Write(&buf, binary.BigEndian, f)
+	}
+	return buf.Bytes()
+}
+
+// versionAndFlags Returns the version and flags of a full box
+func versionAndFlags(payload []byte) (byte, uint32, error) {
+	if len(payload) < 4 {
+		return 0, 0, errTruncated
+	}
+	return payload[0], binary.BigEndian.Uint32(payload) & 0xffffff, nil
+}
@@ -0,0 +1,208 @@
+package encryption
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Options How EncryptPlaylist protects a media playlist
+type Options struct {
+	Method      Method
+	KeyRotation int // Number of segments per key. 0 to use a single key
+	Variant     int // Index of the variant among those sharing the keys. Makes the 'cenc' IVs unique
+}
+
+var (
+	matchMapURI  = regexp.MustCompile(`^#EXT-X-MAP:.*URI="([^"]*)"`)
+	errByteRange = errors.New("cannot encrypt segments addressed with byte-ranges")
+)
+
+// EncryptPlaylist Encrypts the segments of the media playlist at `filename` in place,
+// and adds the EXT-X-KEY tags of their keys to it. Keys come from `provider`:
+// the n-th segment uses the key of period n / KeyRotation.
+// Returns the key of the first segment, for the EXT-X-SESSION-KEY of the master playlist,
+// or `nil` if the playlist has no segment.
+func EncryptPlaylist(filename string, options Options, provider KeyProvider) (*Key, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	dir := filepath.Dir(filename)
+	keys := map[int]*Key{}
+	key := func(period int) (*Key, error) {
+		if k, ok := keys[period]; ok {
+			return k, nil
+		}
+		k, err := provider.Key(period)
+		if err != nil {
+			return nil, fmt.Errorf("key %d: %v", period, err)
+		}
+		keys[period] = k
+		return k, nil
+	}
+
+	var lines []string
+	mediaSequence, segment, version := 0, 0, 0
+	currentPeriod := -1
+	var init []byte                       // Clear initialization segment of the following segments
+	var tracks map[uint32]*track          // Its tracks
+	protectedInits := map[string][]byte{} // Protected initialization segments, by URI
+	segmentTags := 0                      // Index in `lines` where the EXT-X-KEY of the next segment goes
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case strings.HasPrefix(line, "#EXT-X-VERSION:"):
+			version, _ = strconv.Atoi(strings.TrimPrefix(line, "#EXT-X-VERSION:"))
+		case strings.HasPrefix(line, "#EXT-X-MEDIA-SEQUENCE:"):
+			mediaSequence, _ = strconv.Atoi(strings.TrimPrefix(line, "#EXT-X-MEDIA-SEQUENCE:"))
+		case strings.HasPrefix(line, "#EXT-X-BYTERANGE"), strings.Contains(line, "BYTERANGE="):
+			return nil, errByteRange
+		case strings.HasPrefix(line, "#EXT-X-KEY:"):
+			return nil, errors.New("the playlist is already encrypted")
+		case strings.HasPrefix(line, "#EXT-X-MAP:"):
+			m := matchMapURI.FindStringSubmatch(line)
+			if m == nil {
+				return nil, fmt.Errorf("invalid tag %s", line)
+			}
+			if options.Method == AES128 {
+				return nil, errors.New("AES-128 initialization segments are not supported: use SAMPLE-AES")
+			}
+			if init, err = ioutil.ReadFile(filepath.Join(dir, m[1])); err != nil {
+				return nil, err
+			}
+			first, err := key(0)
+			if err != nil {
+				return nil, err
+			}
+			protected, t, err := protectInit(init, options.Method, first)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %v", m[1], err)
+			}
+			protectedInits[m[1]], tracks = protected, t
+		case strings.HasPrefix(line, "#EXTINF:"):
+			segmentTags = len(lines)
+		}
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			lines = append(lines, line)
+			continue
+		}
+
+		// A segment: its key comes first
+		if strings.Contains(line, "://") {
+			return nil, fmt.Errorf("cannot encrypt remote segment %s", line)
+		}
+		period := 0
+		if options.KeyRotation > 0 {
+			period = segment / options.KeyRotation
+		}
+		k, err := key(period)
+		if err != nil {
+			return nil, err
+		}
+		if period != currentPeriod {
+			tag := k.Tag(options.Method).KeyTag()
+			lines = append(lines[:segmentTags], append([]string{tag}, lines[segmentTags:]...)...)
+			currentPeriod = period
+		}
+		if err := encryptFile(filepath.Join(dir, line), options, k, init, tracks, mediaSequence+segment); err != nil {
+			return nil, fmt.Errorf("%s: %v", line, err)
+		}
+		lines = append(lines, line)
+		segmentTags = len(lines)
+		segment++
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if segment == 0 {
+		return nil, nil
+	}
+
+	// Initialization segments are only written once all segments are encrypted
+	for uri, protected := range protectedInits {
+		if err := writeFile(filepath.Join(dir, uri), protected); err != nil {
+			return nil, err
+		}
+	}
+	if minimum := minimumVersion(options.Method, keys[0]); version < minimum {
+		for i, line := range lines {
+			if strings.HasPrefix(line, "#EXT-X-VERSION:") {
+				lines[i] = "#EXT-X-VERSION:" + strconv.Itoa(minimum)
+			}
+		}
+	}
+	if err := writeFile(filename, []byte(strings.Join(lines, "\n")+"\n")); err != nil {
+		return nil, err
+	}
+	return keys[0], nil
+}
+
+// encryptFile Encrypts the segment at `path`, whose media sequence number is `sequence`
+func encryptFile(path string, options Options, key *Key, init []byte, tracks map[uint32]*track, sequence int) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var encrypted []byte
+	switch options.Method {
+	case AES128:
+		iv := key.IV
+		if len(iv) == 0 {
+			iv = sequenceIV(sequence)
+		}
+		encrypted, err = EncryptSegment(data, key, iv)
+	case SampleAES, SampleAESCTR:
+		if tracks == nil {
+			return errors.New("sample encryption needs fragmented MP4 segments")
+		}
+		// Unique for the variant and the segment, see ProtectSegment
+		ivPrefix := uint32(options.Variant&0xff)<<24 | uint32(sequence&0xffffff)
+		// The initialization segment only has the key of the first period
+		encrypted, err = protectSegment(data, tracks, options.Method, key, ivPrefix, options.KeyRotation > 0)
+	default:
+		err = fmt.Errorf("unknown encryption method %q", options.Method)
+	}
+	if err != nil {
+		return err
+	}
+	return writeFile(path, encrypted)
+}
+
+// minimumVersion Returns the EXT-X-VERSION that the keys of `method` require
+func minimumVersion(method Method, key *Key) int {
+	switch {
+	case method != AES128 || len(key.KeyFormat) > 0 || len(key.KeyFormatVersions) > 0:
+		return 5
+	case len(key.IV) > 0:
+		return 2
+	default:
+		return 1
+	}
+}
+
+// writeFile Replaces the file at `filename` atomically
+func writeFile(filename string, data []byte) error {
+	f, err := ioutil.TempFile(filepath.Dir(filename), "."+filepath.Base(filename))
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	return os.Rename(f.Name(), filename)
+}
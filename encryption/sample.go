@@ -0,0 +1,305 @@
+package encryption
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// clearLeader The number of bytes left in clear at the start of the video NAL units, so that their
+// header and slice header can be parsed without the key. Same as Apple's SAMPLE-AES for MPEG-TS.
+const clearLeader = 32
+
+// cbcsCryptBlocks, cbcsSkipBlocks The 'cbcs' pattern of video samples: 1 encrypted block out of 10
+const (
+	cbcsCryptBlocks = 1
+	cbcsSkipBlocks  = 9
+)
+
+// cencIVSize The size of the per-sample IVs of the 'cenc' scheme
+const cencIVSize = 8
+
+var errUnsupportedSample = errors.New("unsupported sample description for sample encryption")
+
+// track What protecting the samples of a track needs to know, from its initialization segment
+type track struct {
+	id          uint32
+	video       bool
+	nalLength   int // Size of the lengths of the NAL units of AVC and HEVC samples
+	hevc        bool
+	defaultSize uint32 // Default sample size of the track fragments
+}
+
+// scheme Returns the ISO common encryption scheme of `method`
+func scheme(method Method) (string, error) {
+	switch method {
+	case SampleAES:
+		return "cbcs", nil
+	case SampleAESCTR:
+		return "cenc", nil
+	}
+	return "", fmt.Errorf("%q is not a sample encryption method", method)
+}
+
+// ProtectInit Marks the audio and video tracks of a fragmented MP4 initialization segment as encrypted
+// with `key`: their sample entries become 'encv' or 'enca', with a 'sinf' box describing the scheme.
+func ProtectInit(data []byte, method Method, key *Key) ([]byte, error) {
+	protected, _, err := protectInit(data, method, key)
+	return protected, err
+}
+
+func protectInit(data []byte, method Method, key *Key) ([]byte, map[uint32]*track, error) {
+	schemeType, err := scheme(method)
+	if err != nil {
+		return nil, nil, err
+	}
+	boxes, err := parseBoxes(data)
+	if err != nil {
+		return nil, nil, err
+	}
+	tracks := map[uint32]*track{}
+	var buf bytes.Buffer
+	for _, b := range boxes {
+		if b.typ == "moov" {
+			if err := protectMoov(b, schemeType, key, tracks); err != nil {
+				return nil, nil, err
+			}
+		}
+		b.write(&buf, nil)
+	}
+	if len(tracks) == 0 {
+		return nil, nil, errors.New("no audio or video track to protect")
+	}
+	return buf.Bytes(), tracks, nil
+}
+
+func protectMoov(moov *box, schemeType string, key *Key, tracks map[uint32]*track) error {
+	defaultSizes := map[uint32]uint32{}
+	if mvex := moov.child("mvex"); mvex != nil {
+		for _, trex := range mvex.children {
+			if trex.typ == "trex" && len(trex.header) >= 24 {
+				defaultSizes[binary.BigEndian.Uint32(trex.header[4:])] = binary.BigEndian.Uint32(trex.header[16:])
+			}
+		}
+	}
+	for _, trak := range moov.children {
+		if trak.typ != "trak" {
+			continue
+		}
+		tkhd, hdlr := trak.child("tkhd"), trak.path("mdia", "hdlr")
+		stsd := trak.path("mdia", "minf", "stbl", "stsd")
+		if tkhd == nil || hdlr == nil || stsd == nil || len(hdlr.header) < 12 {
+			return errTruncated
+		}
+		handler := string(hdlr.header[8:12])
+		if handler != "vide" && handler != "soun" {
+			continue
+		}
+		version, _, err := versionAndFlags(tkhd.header)
+		if err != nil {
+			return err
+		}
+		idOffset := 12
+		if version == 1 {
+			idOffset = 20
+		}
+		if len(tkhd.header) < idOffset+4 {
+			return errTruncated
+		}
+		t := &track{id: binary.BigEndian.Uint32(tkhd.header[idOffset:]), video: handler == "vide"}
+		t.defaultSize = defaultSizes[t.id]
+		for _, entry := range stsd.children {
+			if err := protectSampleEntry(entry, t, schemeType, key); err != nil {
+				return fmt.Errorf("track %d: %v", t.id, err)
+			}
+		}
+		tracks[t.id] = t
+	}
+	return nil
+}
+
+// protectSampleEntry Turns a sample entry into an encrypted one
+func protectSampleEntry(entry *box, t *track, schemeType string, key *Key) error {
+	if _, ok := containers[entry.typ]; !ok || entry.typ == "encv" || entry.typ == "enca" {
+		return fmt.Errorf("%v %q", errUnsupportedSample, entry.typ)
+	}
+	if t.video {
+		if avcC := entry.child("avcC"); avcC != nil && len(avcC.header) > 4 {
+			t.nalLength = int(avcC.header[4]&3) + 1
+		} else if hvcC := entry.child("hvcC"); hvcC != nil && len(hvcC.header) > 21 {
+			t.nalLength, t.hevc = int(hvcC.header[21]&3)+1, true
+		} else {
+			return fmt.Errorf("%v %q", errUnsupportedSample, entry.typ)
+		}
+	}
+	var version byte // The 'cbcs' pattern needs version 1
+	if schemeType == "cbcs" {
+		version = 1
+	}
+	sinf := &box{typ: "sinf", children: []*box{
+		{typ: "frma", header: []byte(entry.typ)},
+		{typ: "schm", header: fullBox(0, 0, []byte(schemeType), uint32(0x10000))},
+		{typ: "schi", children: []*box{{typ: "tenc", header: fullBox(version, 0, encryptionInfo(schemeType, key, t.video))}}},
+	}}
+	entry.children = append(entry.children, sinf)
+	if t.video {
+		entry.typ = "encv"
+	} else {
+		entry.typ = "enca"
+	}
+	return nil
+}
+
+// encryptionInfo Returns how the samples of a track are protected with `key`, as written in 'tenc' boxes
+// and 'seig' sample group entries: the pattern, the per-sample IV size, the key ID and the constant IV
+func encryptionInfo(schemeType string, key *Key, video bool) []byte {
+	if schemeType == "cbcs" {
+		var pattern byte
+		if video {
+			pattern = cbcsCryptBlocks<<4 | cbcsSkipBlocks
+		}
+		return boxFields(byte(0), pattern, byte(1), byte(0), key.ID, byte(KeySize), key.constantIV())
+	}
+	return boxFields(byte(0), byte(0), byte(1), byte(cencIVSize), key.ID)
+}
+
+// subsample A clear then protected range of a sample
+type subsample struct {
+	clear     int
+	protected int
+}
+
+// sampleInfo The auxiliary information of an encrypted sample, written in the 'senc' box
+type sampleInfo struct {
+	iv         []byte
+	subsamples []subsample
+}
+
+func (s sampleInfo) size(withSubsamples bool) int {
+	size := len(s.iv)
+	if withSubsamples {
+		size += 2 + 6*len(s.subsamples)
+	}
+	return size
+}
+
+// sampleEncrypter Encrypts the samples of a segment
+type sampleEncrypter struct {
+	schemeType   string
+	key          *Key
+	block        cipher.Block
+	ivPrefix     uint32 // Makes the 'cenc' IVs unique for the key, see ProtectSegment
+	count        uint32 // Samples encrypted so far in the segment
+	sampleGroups bool   // If true, track fragments describe their key in a 'seig' sample group
+}
+
+// encrypt Encrypts `sample` in place and returns its auxiliary information
+func (e *sampleEncrypter) encrypt(sample []byte, t *track) (info sampleInfo, err error) {
+	if t.video {
+		if info.subsamples, err = videoSubsamples(sample, t, e.schemeType == "cenc"); err != nil {
+			return info, err
+		}
+	}
+	switch e.schemeType {
+	case "cenc":
+		info.iv = make([]byte, cencIVSize)
+		binary.BigEndian.PutUint32(info.iv, e.ivPrefix)
+		binary.BigEndian.PutUint32(info.iv[4:], (t.id&0xff)<<24|e.count&0xffffff)
+		e.count++
+		counter := append(append([]byte{}, info.iv...), make([]byte, KeySize-cencIVSize)...)
+		stream := cipher.NewCTR(e.block, counter)
+		// The protected ranges of a sample are encrypted as one stream
+		for _, r := range protectedRanges(sample, info.subsamples) {
+			stream.XORKeyStream(r, r)
+		}
+	case "cbcs":
+		for _, r := range protectedRanges(sample, info.subsamples) {
+			// The constant IV applies to each subsample, and audio samples are fully encrypted
+			crypt, skip := cbcsCryptBlocks, cbcsSkipBlocks
+			if !t.video {
+				crypt, skip = 1, 0
+			}
+			mode := cipher.NewCBCEncrypter(e.block, e.key.constantIV())
+			for i := 0; i+aes.BlockSize <= len(r); i += aes.BlockSize {
+				if (i/aes.BlockSize)%(crypt+skip) < crypt {
+					mode.CryptBlocks(r[i:i+aes.BlockSize], r[i:i+aes.BlockSize])
+				}
+			}
+		}
+	}
+	return info, nil
+}
+
+// protectedRanges Returns the protected ranges of `sample`. Without subsamples, the whole sample is protected.
+func protectedRanges(sample []byte, subsamples []subsample) [][]byte {
+	if len(subsamples) == 0 {
+		return [][]byte{sample}
+	}
+	var ranges [][]byte
+	offset := 0
+	for _, s := range subsamples {
+		offset += s.clear
+		if s.protected > 0 {
+			ranges = append(ranges, sample[offset:offset+s.protected])
+		}
+		offset += s.protected
+	}
+	return ranges
+}
+
+// videoSubsamples Splits an AVC or HEVC sample in subsamples: the NAL unit lengths, the non-VCL NAL units
+// and the first bytes of the VCL ones are clear. If `aligned`, protected ranges are a multiple of the block size.
+func videoSubsamples(sample []byte, t *track, aligned bool) ([]subsample, error) {
+	nalLength := t.nalLength
+	var subsamples []subsample
+	clear := 0
+	add := func(protected int) {
+		for clear > 0xffff {
+			subsamples = append(subsamples, subsample{clear: 0xffff})
+			clear -= 0xffff
+		}
+		subsamples = append(subsamples, subsample{clear: clear, protected: protected})
+		clear = 0
+	}
+	for offset := 0; offset < len(sample); {
+		if offset+nalLength > len(sample) {
+			return nil, errTruncated
+		}
+		size := 0
+		for _, b := range sample[offset : offset+nalLength] {
+			size = size<<8 | int(b)
+		}
+		offset += nalLength
+		if size == 0 || offset+size > len(sample) {
+			return nil, fmt.Errorf("invalid NAL unit length %d", size)
+		}
+		protected := 0
+		if isVCL(sample[offset], t.hevc) && size > clearLeader {
+			protected = size - clearLeader
+			if aligned {
+				protected -= protected % aes.BlockSize
+			}
+		}
+		clear += nalLength + size - protected
+		if protected > 0 {
+			add(protected)
+		}
+		offset += size
+	}
+	if clear > 0 {
+		add(0)
+	}
+	return subsamples, nil
+}
+
+// isVCL Returns true if the NAL unit starting with `header` holds coded picture data
+func isVCL(header byte, hevc bool) bool {
+	if hevc {
+		return header>>1&0x3f < 32
+	}
+	nalType := header & 0x1f
+	return nalType >= 1 && nalType <= 5
+}
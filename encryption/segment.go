@@ -0,0 +1,28 @@
+package encryption
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/binary"
+)
+
+// sequenceIV Returns the IV of a segment encrypted with AES-128 whose key has no IV:
+// its media sequence number, as a big-endian 128-bit integer
+func sequenceIV(sequence int) []byte {
+	iv := make([]byte, KeySize)
+	binary.BigEndian.PutUint64(iv[8:], uint64(sequence))
+	return iv
+}
+
+// EncryptSegment Encrypts a whole segment with AES-128-CBC and PKCS7 padding, for METHOD=AES-128
+func EncryptSegment(data []byte, key *Key, iv []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key.Value[:])
+	if err != nil {
+		return nil, err
+	}
+	padding := aes.BlockSize - len(data)%aes.BlockSize
+	encrypted := append(append([]byte{}, data...), bytes.Repeat([]byte{byte(padding)}, padding)...)
+	cipher.NewCBCEncrypter(block, iv).CryptBlocks(encrypted, encrypted)
+	return encrypted, nil
+}
@@ -7,4 +7,5 @@ replace gitlab.com/joutube/joutube-server => ../../../gitlab.com/joutube/joutube
 require (
 	github.com/grafov/m3u8 v0.11.1
 	gitlab.com/joutube/joutube-server v0.0.0-00010101000000-000000000000
+	gopkg.in/yaml.v2 v2.4.0
 )
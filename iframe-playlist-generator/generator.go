@@ -1,86 +1,152 @@
 package iframe_playlist_generator
 
 import (
+	"context"
 	"errors"
 	"log"
 	"os"
 	"path/filepath"
 
 	"fmt"
+	"github.com/allezxandre/go-hls-encoder/codecs"
+	"github.com/allezxandre/go-hls-encoder/playlist"
 	"github.com/grafov/m3u8"
 )
 
+// EnrichPlaylist Updates the bandwidths of the variants of the master playlist `masterFilename`
+// with the ones of the master playlist `infoFilename`, as well as their codecs if unknown,
+// and writes the result to `newName` in `dirMaster`.
 func EnrichPlaylist(dirMaster, masterFilename, dirInfo, infoFilename, newName string) (string, error) {
 	// Open Playlist to "enrich"
-	inFileFullPath := filepath.Join(dirMaster, masterFilename)
-	p, _, t, err := variantsFromMaster(inFileFullPath)
-	if err != nil {
-		return "", err
-	}
-	if t != m3u8.MASTER {
-		log.Println("Cannot Enrich playlist", masterFilename, "as it is not a Master Playlist. Type:", t)
+	pMaster, err := playlist.ReadMaster(filepath.Join(dirMaster, masterFilename))
+	if err == playlist.ErrNotMaster {
+		log.Println("Cannot Enrich playlist", masterFilename, "as it is not a Master Playlist")
 		return "", nil
-	}
-	pMaster := p.(*m3u8.MasterPlaylist)
-	// Open other playlist
-	inFileFullPath = filepath.Join(dirInfo, infoFilename)
-	p_, _, t, err := variantsFromMaster(inFileFullPath)
-	if err != nil {
+	} else if err != nil {
 		return "", err
 	}
-	if t != m3u8.MASTER {
-		log.Println("Cannot Enrich playlist", masterFilename, "as it is not a Master Playlist. Type:", t)
+	// Open other playlist
+	pInfo, err := playlist.ReadMaster(filepath.Join(dirInfo, infoFilename))
+	if err == playlist.ErrNotMaster {
+		log.Println("Cannot Enrich playlist", masterFilename, "with", infoFilename, "as it is not a Master Playlist")
 		return "", nil
+	} else if err != nil {
+		return "", err
 	}
-	pInfo := p_.(*m3u8.MasterPlaylist)
 
 	// Use info from pInfo to enrich pMaster
-	for _, v := range pMaster.Variants {
-		updateVariant(pInfo, v)
+	for i := range pMaster.Variants {
+		updateVariant(pInfo, &pMaster.Variants[i])
 	}
 
 	// Write playlist
-	return writePlaylistToFile(pMaster, dirMaster, newName)
+	return newName, pMaster.WriteFile(filepath.Join(dirMaster, newName))
 }
 
-func updateVariant(playlistWithInfo *m3u8.MasterPlaylist, v *m3u8.Variant) {
-	for _, v_ := range playlistWithInfo.Variants {
-		if v_.URI == v.URI {
-			// Update v with v_
-			v.VariantParams.Bandwidth = v_.VariantParams.Bandwidth
-			if len(v_.VariantParams.Codecs) > 0 {
-				v.VariantParams.Codecs = v_.VariantParams.Codecs
-			}
-			break
+func updateVariant(playlistWithInfo *playlist.Master, v *playlist.Variant) {
+	if v_ := playlistWithInfo.Variant(v.URI); v_ != nil {
+		// Update v with v_
+		v.Bandwidth = v_.Bandwidth
+		v.AverageBandwidth = v_.AverageBandwidth
+		// Codecs computed when writing the master playlist are more accurate
+		if len(v.Codecs) == 0 && len(v_.Codecs) > 0 {
+			v.Codecs = v_.Codecs
 		}
 	}
 }
 
+// ProgressFunc Is called each time a segment of a variant has been processed.
+type ProgressFunc func(variantURI string, done, total int)
+
+// IFramePlaylist An I-FRAME-ONLY playlist generated for a variant
+type IFramePlaylist struct {
+	VariantURI       string // The media playlist of the variant
+	URI              string // The I-FRAME-ONLY playlist
+	Bandwidth        int    // Bitrate of the largest I-frame, in bits/s
+	AverageBandwidth int    // Bitrate of all I-frames, in bits/s
+}
+
 func GeneratePlaylist(dir, inFile string) error {
-	// Retrieve variants
+	return GeneratePlaylistContext(context.Background(), dir, inFile, nil)
+}
+
+// GeneratePlaylistContext Same as GeneratePlaylist, but stops
+// as soon as `ctx` is done, and reports progress to `progress` if not `nil`.
+// If `inFile` is a master playlist, the I-FRAME-ONLY playlists are added to it.
+func GeneratePlaylistContext(ctx context.Context, dir, inFile string, progress ProgressFunc) error {
 	inFileFullPath := filepath.Join(dir, inFile)
-	_, variants, t, err := variantsFromMaster(inFileFullPath)
+	master, err := playlist.ReadMaster(inFileFullPath)
+	if err == playlist.ErrNotMaster {
+		// A single media playlist
+		_, err := GenerateIFramePlaylists(ctx, dir, []string{inFile}, progress)
+		return err
+	} else if err != nil {
+		return err
+	}
+
+	// Generate and add i-frame only playlists
+	var variantURIs []string
+	for _, v := range master.Variants {
+		variantURIs = append(variantURIs, v.URI)
+	}
+	iframePlaylists, err := GenerateIFramePlaylists(ctx, dir, variantURIs, progress)
 	if err != nil {
 		return err
 	}
+	for _, p := range iframePlaylists {
+		AddIFrameVariant(master, p)
+	}
+	log.Println("DEBUG: I-FRAME-GENERATION Done")
+	return master.WriteFile(inFileFullPath)
+}
 
-	// File for writing
-	var f *os.File = nil
-	if t == m3u8.MASTER {
-		f, err = os.OpenFile(inFileFullPath, os.O_APPEND|os.O_WRONLY, 0600)
-		if err != nil {
-			return err
+// AddIFrameVariant Adds the I-FRAME-ONLY playlist `p` to `master`, with the attributes
+// of its variant. An I-frame stream with the same URI is replaced.
+func AddIFrameVariant(master *playlist.Master, p IFramePlaylist) {
+	iframeVariant := playlist.IFrameVariant{
+		URI:              p.URI,
+		Bandwidth:        p.Bandwidth,
+		AverageBandwidth: p.AverageBandwidth,
+	}
+	if v := master.Variant(p.VariantURI); v != nil {
+		iframeVariant.Codecs = codecs.Video(v.Codecs)
+		iframeVariant.Resolution = v.Resolution
+		iframeVariant.HDCPLevel = v.HDCPLevel
+		iframeVariant.VideoRange = v.VideoRange
+		iframeVariant.Supplemental = v.Supplemental
+		iframeVariant.Video = v.Video
+	}
+	for i, existing := range master.IFrameVariants {
+		if existing.URI == p.URI {
+			master.IFrameVariants[i] = iframeVariant
+			return
 		}
-		defer f.Close()
 	}
+	master.IFrameVariants = append(master.IFrameVariants, iframeVariant)
+}
 
+// GenerateIFramePlaylists Generates the I-FRAME-ONLY playlists of the media playlists
+// at `variantURIs`, relative to `dir`. Variants that fail are skipped.
+// Stops as soon as `ctx` is done, and reports progress to `progress` if not `nil`.
+func GenerateIFramePlaylists(ctx context.Context, dir string, variantURIs []string, progress ProgressFunc) ([]IFramePlaylist, error) {
 	// Fill variants chunks
+	var variants []*m3u8.Variant
+	for _, uri := range variantURIs {
+		variants = append(variants, &m3u8.Variant{URI: uri})
+	}
 	fillVariants(dir, variants...)
 
 	// Generate and write i-frame only playlists
+	var generated []IFramePlaylist
 	for _, variant := range variants {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
 		// Generate playlist
-		iframePlaylist, err := iframePlaylistForVariant(dir, variant)
+		iframePlaylist, err := iframePlaylistForVariant(ctx, dir, variant, progress)
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
 		if err != nil {
 			log.Println("Cannot generate I-FRAMES-ONLY playlist for variant \""+variant.URI+
 				"\"... Carrying on with the others anyway. \n\tError:", err)
@@ -89,30 +155,45 @@ func GeneratePlaylist(dir, inFile string) error {
 		log.Println("DEBUG: Writing playlist")
 		// Write to new file
 		iframePlaylist.TargetDuration -= 1
-		iframeFilename, err := writePlaylistToFile(iframePlaylist, dir, iframeOnlyFilename(variant.URI))
+		iframeFilename, err := writePlaylistToFile(iframePlaylist, dir, IFrameOnlyFilename(variant.URI))
 		if err != nil {
 			log.Println("Cannot write I-FRAMES-ONLY playlist to file \""+variant.URI+
 				"\"... Carrying on with the others anyway. \n\tError:", err)
 			continue
 		}
-		log.Println("DEBUG: Appending playlists to master?")
-		// Append to master, if master
-		if t == m3u8.MASTER {
-			log.Println("DEBUG:        -> yes")
-			_, err := f.WriteString(fmt.Sprintf(
-				"#EXT-X-I-FRAME-STREAM-INF:BANDWIDTH=%d,URI=\"%v\"\n",
-				int(variant.Bandwidth)/10, iframeFilename))
-			// TODO: To determine bandwidth, instead of dividing by 10, sum the size of all iFrames and divide by duration
-			if err != nil {
-				log.Println("Error writing to master:", err)
-			}
-		} else {
-			log.Println("DEBUG:        -> NO??? Something's wrong dude...")
-		}
+		bandwidth, average := iframeBandwidth(iframePlaylist)
+		generated = append(generated, IFramePlaylist{
+			VariantURI:       variant.URI,
+			URI:              iframeFilename,
+			Bandwidth:        bandwidth,
+			AverageBandwidth: average,
+		})
 	}
+	return generated, nil
+}
 
-	log.Println("DEBUG: I-FRAME-GENERATION Done")
-	return nil
+// iframeBandwidth Returns the peak and average bitrate of an I-FRAME-ONLY playlist:
+// each I-frame is considered as a segment lasting until the next one.
+func iframeBandwidth(p *m3u8.MediaPlaylist) (peak, average int) {
+	var totalSize int64
+	var totalDuration float64
+	for _, segment := range p.Segments {
+		if segment == nil {
+			break
+		}
+		if segment.Duration <= 0 {
+			continue
+		}
+		if bandwidth := int(float64(segment.Limit*8) / segment.Duration); bandwidth > peak {
+			peak = bandwidth
+		}
+		totalSize += segment.Limit
+		totalDuration += segment.Duration
+	}
+	if totalDuration > 0 {
+		average = int(float64(totalSize*8) / totalDuration)
+	}
+	return
 }
 
 // variantsFromMaster Returns a slice of variants to use
@@ -168,14 +249,14 @@ func fillVariants(dir string, variants ...*m3u8.Variant) {
 
 // iframePlaylistForVariant Generates an I-FRAMES-ONLY media playlist
 // for the provided variant.
-func iframePlaylistForVariant(dir string, variant *m3u8.Variant) (*m3u8.MediaPlaylist, error) {
+func iframePlaylistForVariant(ctx context.Context, dir string, variant *m3u8.Variant, progress ProgressFunc) (*m3u8.MediaPlaylist, error) {
 	if variant.Chunklist == nil {
 		return nil, errors.New("`nil` chunklist for variant \"" + variant.URI + "\"")
 	}
 
 	// Loop through segments of variant to find key frames
 	var entries []*IFrameEntry
-	nbSegmts := len(variant.Chunklist.Segments)
+	nbSegmts := int(variant.Chunklist.Count())
 	initFilename := ""
 	var initSize uint = 0
 	for i, segment := range variant.Chunklist.Segments {
@@ -187,13 +268,16 @@ func iframePlaylistForVariant(dir string, variant *m3u8.Variant) (*m3u8.MediaPla
 			fi, _ := os.Stat(initFilename)
 			initSize = uint(fi.Size())
 		}
-		entriesPartial, err := iframeEntryForSegment(initFilename, initSize, filepath.Join(dir, segment.URI))
+		entriesPartial, err := iframeEntryForSegment(ctx, initFilename, initSize, filepath.Join(dir, segment.URI))
 		if err != nil {
 			log.Println("DEBUG: Error running iframeEntryForSegment on", filepath.Join(dir, segment.URI))
 			return nil, err
 		}
 		entries = append(entries, entriesPartial...)
 		fmt.Printf("DEBUG: EXT-I-Frame Progress: %d/%d\n", i, nbSegmts)
+		if progress != nil {
+			progress(variant.URI, i+1, nbSegmts)
+		}
 	}
 
 	// Generate playlist from entries
@@ -217,8 +301,8 @@ type IFrameEntry struct {
 }
 
 // iframeEntryForSegment Looks for all IFrames packets position and size/duration.
-func iframeEntryForSegment(initURI string, initSize uint, segmentURI string) ([]*IFrameEntry, error) {
-	packets, err := probePackets(initURI, segmentURI)
+func iframeEntryForSegment(ctx context.Context, initURI string, initSize uint, segmentURI string) ([]*IFrameEntry, error) {
+	packets, err := probePackets(ctx, initURI, segmentURI)
 	if err != nil {
 		return nil, err
 	}
@@ -228,7 +312,7 @@ func iframeEntryForSegment(initURI string, initSize uint, segmentURI string) ([]
 
 	nbPkts := len(packets)
 	for i, p := range packets {
-		if p.isFromKeyFrame() {
+		if p.IsKeyFrame() {
 			// Save last entry
 			if lastEntry != nil {
 				entries = append(entries, lastEntry)
@@ -263,7 +347,8 @@ func iframeEntryForSegment(initURI string, initSize uint, segmentURI string) ([]
 	return entries, nil
 }
 
-func iframeOnlyFilename(originalName string) (newName string) {
+// IFrameOnlyFilename Returns the filename of the I-FRAME-ONLY playlist of the media playlist `originalName`
+func IFrameOnlyFilename(originalName string) (newName string) {
 	extName := filepath.Ext(originalName)
 	bName := originalName[:len(originalName)-len(extName)]
 	newName = bName + "_I-FRAME-ONLY" + extName
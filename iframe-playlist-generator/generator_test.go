@@ -1,23 +1,34 @@
 package iframe_playlist_generator
 
 import (
+	"context"
 	"github.com/grafov/m3u8"
 	"log"
 	"math"
+	"os"
+	"path/filepath"
 	"testing"
+
+	"github.com/allezxandre/go-hls-encoder/probe"
 )
 
 var eps = 0.001 // Comparison precision
 
+// TestMain Probes the fixtures with the native prober, so that the tests do not need ffprobe
+func TestMain(m *testing.M) {
+	probe.DefaultProber = probe.Native{}
+	os.Exit(m.Run())
+}
+
 func TestFFprobe1(t *testing.T) {
-	_, err := probePackets("", "tests/bigbuckbunny-400k.m3u8")
+	_, err := probePackets(context.Background(), "", "tests/bigbuckbunny-150k-00001.ts")
 	if err != nil {
 		t.Error("Cannot probe file:", err)
 	}
 }
 
 func TestFFprobe2(t *testing.T) {
-	_, err := probePackets("", "tests/bigbuckbunny-400k-00004.ts")
+	_, err := probePackets(context.Background(), "", "tests/bigbuckbunny-400k-00004.ts")
 	if err != nil {
 		t.Error("Cannot probe file:", err)
 	}
@@ -44,7 +55,7 @@ func TestVariantsFromMaster(t *testing.T) {
 
 func TestIFramePlaylistSegment1(t *testing.T) {
 	segmentURI := "tests/bigbuckbunny-400k-00001.ts"
-	p, err := iframeEntryForSegment("", 0, segmentURI)
+	p, err := iframeEntryForSegment(context.Background(), "", 0, segmentURI)
 	if err != nil {
 		t.Error("Error running iframeEntryForSegment:", err)
 		return
@@ -58,7 +69,7 @@ func TestIFramePlaylistSegment1(t *testing.T) {
 	}
 	actualFirstFrame := p[0]
 	expectedFirstFrame := &IFrameEntry{
-		SegmentURI:     segmentURI,
+		SegmentURI:     filepath.Base(segmentURI), // Relative to the playlist
 		PacketPosition: 3008,
 		PacketSize:     376,
 		Duration:       9.08,
@@ -87,7 +98,7 @@ func TestIFramePlaylistSegment1(t *testing.T) {
 
 func TestIFramePlaylistSegment4(t *testing.T) {
 	segmentURI := "tests/bigbuckbunny-400k-00004.ts"
-	p, err := iframeEntryForSegment("", 0, segmentURI)
+	p, err := iframeEntryForSegment(context.Background(), "", 0, segmentURI)
 	if err != nil {
 		t.Error("Error running iframeEntryForSegment:", err)
 		return
@@ -101,7 +112,7 @@ func TestIFramePlaylistSegment4(t *testing.T) {
 	}
 	actualFirstFrame := p[1]
 	expectedFirstFrame := &IFrameEntry{
-		SegmentURI:     segmentURI,
+		SegmentURI:     filepath.Base(segmentURI), // Relative to the playlist
 		PacketPosition: 28388,
 		PacketSize:     4888,
 		Duration:       0.04,
@@ -133,13 +144,29 @@ func TestPlaylistForVariant(t *testing.T) {
 	_, variants, _, _ := variantsFromMaster(masterFile)
 	dir := "tests/"
 	fillVariants(dir, variants...)
-	p, err := iframePlaylistForVariant(dir, variants[0])
+	p, err := iframePlaylistForVariant(context.Background(), dir, variants[0], nil)
 	if err != nil {
 		t.Error("Cannot run `iframePlaylistForVariant`", err)
 		return
 	}
-	if len(p.Segments) != 17 {
+	if len(p.Segments) != 26 { // As in tests/bigbuckbunny-400k-iframes.m3u8
 		t.Error("Unexpected number of segments:", len(p.Segments))
 		return
 	}
+	f, err := os.Open("tests/bigbuckbunny-400k-iframes.m3u8")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	expected, _ := m3u8.NewMediaPlaylist(0, 1)
+	if err := expected.DecodeFrom(f, true); err != nil {
+		t.Fatal(err)
+	}
+	for i, segment := range p.Segments {
+		e := expected.Segments[i]
+		// The sizes of the fixture are not whole MPEG-TS packets
+		if segment.URI != e.URI || segment.Offset != e.Offset {
+			t.Errorf("Unexpected I-frame %d: %s @%d, expected %s @%d", i, segment.URI, segment.Offset, e.URI, e.Offset)
+		}
+	}
 }
@@ -0,0 +1,219 @@
+package iframe_playlist_generator
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/grafov/m3u8"
+)
+
+// Watcher Builds the I-FRAME-ONLY playlist of a media playlist while it is being written:
+// the I-frames of each segment are looked for as soon as the segment is listed.
+// The I-FRAME-ONLY playlist follows the window, the discontinuities and the end of the media playlist.
+type Watcher struct {
+	Dir        string       // Directory of the playlists and segments
+	VariantURI string       // The media playlist, relative to Dir
+	Progress   ProgressFunc // Called after each segment if not `nil`, with the number of segments listed so far
+
+	segments []watchedSegment // The segments of the media playlist, with their I-frames
+	next     uint64           // Media sequence number of the next segment to process
+	removed  uint64           // Number of I-frames that left the playlist
+	duration time.Duration    // Duration of the segments processed so far
+	initURI  string           // Initialization section of the segments, relative to Dir
+	initSize uint
+	playlist *m3u8.MediaPlaylist // The I-FRAME-ONLY playlist written last
+}
+
+type watchedSegment struct {
+	sequence      uint64
+	discontinuity bool
+	entries       []*IFrameEntry
+}
+
+// Run Updates the I-FRAME-ONLY playlist every `interval`, until the media playlist ends or `ctx` is done.
+// Once `stop` is closed, the playlist is updated a last time: it ends if the media playlist did.
+func (w *Watcher) Run(ctx context.Context, interval time.Duration, stop <-chan struct{}) (IFramePlaylist, error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		ended, err := w.Update(ctx)
+		if err != nil || ended {
+			return w.Result(), err
+		}
+		select {
+		case <-ctx.Done():
+			return w.Result(), ctx.Err()
+		case <-stop:
+			_, err := w.Update(ctx)
+			return w.Result(), err
+		case <-ticker.C:
+		}
+	}
+}
+
+// Update Looks for the I-frames of the segments added to the media playlist since the last update,
+// and rewrites the I-FRAME-ONLY playlist if it changed. Returns `true` once the media playlist has ended.
+// A media playlist that cannot be read, e.g. while ffmpeg writes it, and the segments it lists that are not
+// written yet, are looked for again at the next update.
+func (w *Watcher) Update(ctx context.Context) (bool, error) {
+	f, err := os.Open(filepath.Join(w.Dir, w.VariantURI))
+	if os.IsNotExist(err) {
+		return false, nil // Not written yet
+	} else if err != nil {
+		return false, err
+	}
+	source, _ := m3u8.NewMediaPlaylist(0, 1)
+	err = source.DecodeFrom(f, true)
+	f.Close()
+	if err != nil {
+		return false, nil // Partially written
+	}
+
+	ended := source.Closed // Unless a segment is not written yet
+	changed := false
+	// Segments that left the media playlist leave the I-FRAME-ONLY playlist
+	for len(w.segments) > 0 && w.segments[0].sequence < source.SeqNo {
+		w.removed += uint64(len(w.segments[0].entries))
+		w.segments = w.segments[1:]
+		changed = true
+	}
+	listed := int(source.Count())
+	for i, segment := range source.Segments {
+		if segment == nil {
+			break // The end of the segments buffer
+		}
+		sequence := source.SeqNo + uint64(i)
+		initURI := w.initURI
+		if segment.Map != nil {
+			initURI = segment.Map.URI
+		}
+		if segment.Discontinuity && sequence >= w.next {
+			// ffmpeg was restarted and wrote the initialization section again
+			w.initURI = ""
+		}
+		if len(initURI) > 0 {
+			if err := w.readInit(initURI); os.IsNotExist(err) {
+				ended = false
+				break
+			} else if err != nil {
+				return false, err
+			}
+		}
+		if sequence < w.next {
+			continue
+		}
+		segmentFilename := filepath.Join(w.Dir, segment.URI)
+		if _, err := os.Stat(segmentFilename); os.IsNotExist(err) {
+			ended = false // Not written yet, or already removed from a sliding window
+			break
+		}
+		initFilename := ""
+		if len(w.initURI) > 0 {
+			initFilename = filepath.Join(w.Dir, w.initURI)
+		}
+		entries, err := iframeEntryForSegment(ctx, initFilename, w.initSize, segmentFilename)
+		if err != nil {
+			return false, err
+		}
+		w.segments = append(w.segments, watchedSegment{
+			sequence:      sequence,
+			discontinuity: segment.Discontinuity,
+			entries:       entries,
+		})
+		w.next = sequence + 1
+		w.duration += time.Duration(segment.Duration * float64(time.Second))
+		changed = true
+		if w.Progress != nil {
+			w.Progress(w.VariantURI, i+1, listed)
+		}
+	}
+	if !changed && !ended {
+		return false, nil
+	}
+	w.playlist = w.iframePlaylist(source, ended)
+	if err := writePlaylistAtomic(w.playlist, filepath.Join(w.Dir, IFrameOnlyFilename(w.VariantURI))); err != nil {
+		return false, err
+	}
+	return ended, nil
+}
+
+// readInit Reads the size of the initialization section, which precedes the segments when probed
+func (w *Watcher) readInit(uri string) error {
+	if uri == w.initURI {
+		return nil
+	}
+	info, err := os.Stat(filepath.Join(w.Dir, uri))
+	if err != nil {
+		return err
+	}
+	w.initURI, w.initSize = uri, uint(info.Size())
+	return nil
+}
+
+// iframePlaylist Returns the I-FRAME-ONLY playlist of the segments, with the attributes of `source`.
+// It is ended if `ended`.
+func (w *Watcher) iframePlaylist(source *m3u8.MediaPlaylist, ended bool) *m3u8.MediaPlaylist {
+	count := 1
+	for _, segment := range w.segments {
+		count += len(segment.entries)
+	}
+	p, _ := m3u8.NewMediaPlaylist(0, uint(count))
+	p.SetIframeOnly()
+	p.MediaType = source.MediaType
+	p.SeqNo = w.removed
+	p.DiscontinuitySeq = source.DiscontinuitySeq
+	p.Map = source.Map
+	for _, segment := range w.segments {
+		for i, entry := range segment.entries {
+			p.Append(entry.SegmentURI, entry.Duration, "")
+			p.SetRange(int64(entry.PacketSize), int64(entry.PacketPosition))
+			if i == 0 && segment.discontinuity {
+				p.SetDiscontinuity()
+			}
+		}
+	}
+	p.TargetDuration = source.TargetDuration
+	if ended {
+		p.Close()
+	}
+	return p
+}
+
+// Processed Returns the duration of the segments whose I-frames were found so far
+func (w *Watcher) Processed() time.Duration {
+	return w.duration
+}
+
+// Result Returns the I-FRAME-ONLY playlist written last, with its bandwidths
+func (w *Watcher) Result() IFramePlaylist {
+	result := IFramePlaylist{
+		VariantURI: w.VariantURI,
+		URI:        IFrameOnlyFilename(w.VariantURI),
+	}
+	if w.playlist != nil {
+		result.Bandwidth, result.AverageBandwidth = iframeBandwidth(w.playlist)
+	}
+	return result
+}
+
+// writePlaylistAtomic Writes the playlist to a temporary file renamed to `filename`,
+// so that players never read a partial playlist
+func writePlaylistAtomic(p m3u8.Playlist, filename string) error {
+	f, err := ioutil.TempFile(filepath.Dir(filename), "."+filepath.Base(filename))
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(p.Encode().Bytes()); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	return os.Rename(f.Name(), filename)
+}
@@ -0,0 +1,127 @@
+package iframe_playlist_generator
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/allezxandre/go-hls-encoder/probe"
+	"github.com/grafov/m3u8"
+)
+
+func TestWatcher(t *testing.T) {
+	defer func(prober probe.Prober) { probe.DefaultProber = prober }(probe.DefaultProber)
+	probe.DefaultProber = probe.Native{} // Without ffprobe
+
+	dir, err := ioutil.TempDir("", "watcher")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	copySegment := func(name string) {
+		data, err := ioutil.ReadFile(filepath.Join("tests", name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(filepath.Join(dir, name), data, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeMedia := func(content string) {
+		if err := ioutil.WriteFile(filepath.Join(dir, "media.m3u8"), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	media := func(sequence int, lines ...string) string {
+		header := []string{"#EXTM3U", "#EXT-X-VERSION:3", "#EXT-X-TARGETDURATION:10", "#EXT-X-MEDIA-SEQUENCE:" + strconv.Itoa(sequence)}
+		return strings.Join(append(header, lines...), "\n") + "\n"
+	}
+	iframesFilename := filepath.Join(dir, "media_I-FRAME-ONLY.m3u8")
+	readIFrames := func() *m3u8.MediaPlaylist {
+		f, err := os.Open(iframesFilename)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		p, _ := m3u8.NewMediaPlaylist(0, 1)
+		if err := p.DecodeFrom(f, true); err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+	copySegment("bigbuckbunny-400k-00001.ts")
+	copySegment("bigbuckbunny-400k-00002.ts")
+
+	w := &Watcher{Dir: dir, VariantURI: "media.m3u8"}
+	if ended, err := w.Update(context.Background()); err != nil || ended {
+		t.Fatal("Unexpected update before the media playlist is written:", ended, err)
+	}
+	// ffmpeg is writing the playlist
+	writeMedia(strings.TrimSuffix(media(0, "#EXTINF:10.0000,", "bigbuckbunny-400k-00001.ts", "#EXTINF:10.0000,"), ".0000,\n"))
+	if ended, err := w.Update(context.Background()); err != nil || ended {
+		t.Fatal("Unexpected update of a partially written media playlist:", ended, err)
+	}
+	if _, err := os.Stat(iframesFilename); !os.IsNotExist(err) {
+		t.Error("Unexpected I-FRAME-ONLY playlist of a partially written media playlist:", err)
+	}
+
+	// The third segment is listed but not written yet
+	writeMedia(media(0, "#EXTINF:10.0000,", "bigbuckbunny-400k-00001.ts", "#EXTINF:10.0000,", "bigbuckbunny-400k-00002.ts",
+		"#EXTINF:10.0000,", "bigbuckbunny-400k-00003.ts"))
+	if ended, err := w.Update(context.Background()); err != nil || ended {
+		t.Fatal("Unexpected update of the first segments:", ended, err)
+	}
+	if len(w.segments) != 2 || w.next != 2 {
+		t.Fatal("Unexpected segments processed before the third is written:", len(w.segments), w.next)
+	}
+	first := uint64(len(w.segments[0].entries))
+	if p := readIFrames(); p.Count() <= 2 || p.Closed || p.SeqNo != 0 {
+		t.Error("Unexpected I-FRAME-ONLY playlist after the first segments:", p.Count(), p.Closed, p.SeqNo)
+	}
+
+	// The first segment leaves the sliding window
+	copySegment("bigbuckbunny-400k-00003.ts")
+	writeMedia(media(1, "#EXTINF:10.0000,", "bigbuckbunny-400k-00002.ts", "#EXTINF:10.0000,", "bigbuckbunny-400k-00003.ts"))
+	if ended, err := w.Update(context.Background()); err != nil || ended {
+		t.Fatal("Unexpected update of the sliding window:", ended, err)
+	}
+	if w.removed != first {
+		t.Errorf("%d I-frames removed, expected the %d of the first segment", w.removed, first)
+	}
+	p := readIFrames()
+	if p.SeqNo != first || p.Closed {
+		t.Error("Unexpected I-FRAME-ONLY playlist after the sliding window:", p.SeqNo, p.Closed)
+	}
+	if p.Segments[0].URI != "bigbuckbunny-400k-00002.ts" || p.Segments[p.Count()-1].URI != "bigbuckbunny-400k-00003.ts" {
+		t.Error("Unexpected I-frame segments in the window:", p.Segments[0].URI, p.Segments[p.Count()-1].URI)
+	}
+
+	// The playlist ends before its last segment is written
+	ending := media(1, "#EXTINF:10.0000,", "bigbuckbunny-400k-00002.ts", "#EXTINF:10.0000,", "bigbuckbunny-400k-00003.ts",
+		"#EXTINF:10.0000,", "bigbuckbunny-400k-00004.ts", "#EXT-X-ENDLIST")
+	writeMedia(ending)
+	if ended, err := w.Update(context.Background()); err != nil || ended {
+		t.Fatal("Unexpected end before the last segment is written:", ended, err)
+	}
+	if p := readIFrames(); p.Closed {
+		t.Error("Unexpected I-FRAME-ONLY playlist ended before its last segment")
+	}
+	copySegment("bigbuckbunny-400k-00004.ts")
+	if ended, err := w.Update(context.Background()); err != nil || !ended {
+		t.Fatal("Unexpected update of the last segment:", ended, err)
+	}
+	p = readIFrames()
+	if !p.Closed || p.SeqNo != first {
+		t.Error("Unexpected I-FRAME-ONLY playlist once ended:", p.Closed, p.SeqNo)
+	}
+	if p.Segments[0].URI != "bigbuckbunny-400k-00002.ts" || p.Segments[p.Count()-1].URI != "bigbuckbunny-400k-00004.ts" {
+		t.Error("Unexpected I-frame segments:", p.Segments[0].URI, p.Segments[p.Count()-1].URI)
+	}
+	if result := w.Result(); result.URI != "media_I-FRAME-ONLY.m3u8" || result.Bandwidth == 0 {
+		t.Errorf("Unexpected result %+v", result)
+	}
+}
@@ -1,11 +1,18 @@
 package input
 
-type Language string
+import "github.com/allezxandre/go-hls-encoder/language"
+
+// Language A BCP 47 language tag. See the language package.
+type Language = language.Tag
 
 const ( // https://tools.ietf.org/html/rfc5646
-	Unknown         Language = ""
-	FrenchLanguage  Language = "fr"
-	QuebecLanguage  Language = "fr-CA"
-	TrueFrench      Language = "fr-FR"
-	EnglishLanguage Language = "en"
+	Unknown = language.Unknown
+	// Deprecated: use language.French
+	FrenchLanguage = language.French
+	// Deprecated: use language.CanadianFrench
+	QuebecLanguage = language.CanadianFrench
+	// Deprecated: use language.EuropeanFrench
+	TrueFrench = language.EuropeanFrench
+	// Deprecated: use language.English
+	EnglishLanguage = language.English
 )
@@ -7,7 +7,7 @@ echo "== Installing dependencies"
 apt update
 apt -y install autoconf automake build-essential libass-dev libfreetype6-dev \
   libtheora-dev libtool libvorbis-dev pkg-config texinfo zlib1g-dev \
-  yasm wget cmake mercurial libx264-dev
+  yasm wget cmake mercurial libx264-dev libzimg-dev
 
 rm -rf $HOME/.ffmpeg_sources && mkdir -p $HOME/.ffmpeg_sources
 rm -rf $HOME/.ffmpeg_build && mkdir -p $HOME/.ffmpeg_build
@@ -59,6 +59,7 @@ PATH="$HOME/.bin:$PATH" PKG_CONFIG_PATH="$HOME/.ffmpeg_build/lib/pkgconfig" ./co
   --enable-libvorbis \
   --enable-libx264 \
   --enable-libx265 \
+  --enable-libzimg \
   --enable-nonfree
 PATH="$HOME/.bin:$PATH" make
 make install
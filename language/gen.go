@@ -0,0 +1,227 @@
+// +build ignore
+
+// gen.go Generates tables.go from Debian's iso-codes package:
+//
+//	go run gen.go -iso-codes /usr/share/iso-codes/json -locales /usr/share/locale
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"log"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// englishNames Replaces the names of iso-codes that are not display names
+var englishNames = map[string]string{
+	"bn": "Bangla",
+	"el": "Greek",
+	"ia": "Interlingua",
+	"nb": "Norwegian Bokmål",
+	"nd": "North Ndebele",
+	"nn": "Norwegian Nynorsk",
+	"nr": "South Ndebele",
+	"oc": "Occitan",
+	"st": "Southern Sotho",
+	"to": "Tongan",
+}
+
+// nativeNames Replaces or completes the names of the languages in their own translation
+var nativeNames = map[string]string{
+	"az": "Azərbaycan",
+	"bo": "བོད་སྐད་",
+	"bs": "Bosanski",
+	"co": "Corsu",
+	"en": "English",
+	"fo": "Føroyskt",
+	"fy": "Frysk",
+	"gd": "Gàidhlig",
+	"ha": "Hausa",
+	"ht": "Kreyòl ayisyen",
+	"hy": "Հայերեն",
+	"ig": "Igbo",
+	"jv": "Jawa",
+	"ka": "ქართული",
+	"kk": "Қазақ",
+	"km": "ខ្មែរ",
+	"ku": "Kurdî",
+	"ky": "Кыргызча",
+	"la": "Latina",
+	"lb": "Lëtzebuergesch",
+	"ln": "Lingála",
+	"lo": "ລາວ",
+	"mg": "Malagasy",
+	"mi": "Māori",
+	"my": "မြန်မာ",
+	"nb": "Norsk bokmål",
+	"ne": "नेपाली",
+	"nn": "Norsk nynorsk",
+	"no": "Norsk",
+	"oc": "Occitan",
+	"ps": "پښتو",
+	"qu": "Runasimi",
+	"rm": "Rumantsch",
+	"sd": "سنڌي",
+	"si": "සිංහල",
+	"sm": "Gagana Samoa",
+	"sn": "chiShona",
+	"so": "Soomaali",
+	"st": "Sesotho",
+	"su": "Basa Sunda",
+	"sw": "Kiswahili",
+	"tg": "Тоҷикӣ",
+	"tk": "Türkmen dili",
+	"tl": "Tagalog",
+	"tn": "Setswana",
+	"ug": "ئۇيغۇرچە",
+	"ur": "اردو",
+	"uz": "Oʻzbek",
+	"wo": "Wolof",
+	"xh": "isiXhosa",
+	"yi": "ייִדיש",
+	"yo": "Èdè Yorùbá",
+	"zh": "中文",
+	"zu": "isiZulu",
+}
+
+type isoLanguage struct {
+	Alpha2        string `json:"alpha_2"`
+	Alpha3        string `json:"alpha_3"`
+	Bibliographic string `json:"bibliographic"`
+	Name          string `json:"name"`
+	CommonName    string `json:"common_name"`
+}
+
+type isoRegion struct {
+	Alpha2     string `json:"alpha_2"`
+	Name       string `json:"name"`
+	CommonName string `json:"common_name"`
+}
+
+func main() {
+	isoCodes := flag.String("iso-codes", "/usr/share/iso-codes/json", "Directory of the iso-codes JSON files")
+	locales := flag.String("locales", "/usr/share/locale", "Directory of the iso-codes translations")
+	output := flag.String("o", "tables.go", "Output file")
+	flag.Parse()
+
+	var languages map[string][]isoLanguage
+	readJSON(filepath.Join(*isoCodes, "iso_639-2.json"), &languages)
+	var regions map[string][]isoRegion
+	readJSON(filepath.Join(*isoCodes, "iso_3166-1.json"), &regions)
+
+	var b bytes.Buffer
+	fmt.Fprintln(&b, "// Code generated by gen.go from Debian's iso-codes. DO NOT EDIT.")
+	fmt.Fprintln(&b)
+	fmt.Fprintln(&b, "package language")
+	fmt.Fprintln(&b)
+	fmt.Fprintln(&b, "// iso639 The ISO 639-2 languages, sorted by their ISO 639-2/T code")
+	fmt.Fprintln(&b, "var iso639 = []Language{")
+	entries := languages["639-2"]
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Alpha3 < entries[j].Alpha3 })
+	for _, l := range entries {
+		if strings.Contains(l.Alpha3, "-") { // Reserved ranges, e.g. qaa-qtz
+			continue
+		}
+		name := displayName(l.Name)
+		if len(l.CommonName) > 0 {
+			name = l.CommonName
+		}
+		if n, ok := englishNames[l.Alpha2]; ok {
+			name = n
+		}
+		var native string
+		if len(l.Alpha2) > 0 {
+			// The name of a language in its own translation
+			if translations, err := readMO(filepath.Join(*locales, l.Alpha2, "LC_MESSAGES", "iso_639-2.mo")); err == nil {
+				if t, ok := translations[l.Name]; ok {
+					native = title(displayName(t))
+				}
+			}
+		}
+		if n, ok := nativeNames[l.Alpha2]; ok {
+			native = n
+		}
+		fmt.Fprintf(&b, "\t{Alpha2: %q, Alpha3: %q, Bibliographic: %q, Name: %q, Native: %q},\n",
+			l.Alpha2, l.Alpha3, l.Bibliographic, name, native)
+	}
+	fmt.Fprintln(&b, "}")
+	fmt.Fprintln(&b)
+	fmt.Fprintln(&b, "// regionNames The English names of the ISO 3166-1 regions")
+	fmt.Fprintln(&b, "var regionNames = map[string]string{")
+	for _, r := range regions["3166-1"] {
+		name := r.Name
+		if len(r.CommonName) > 0 {
+			name = r.CommonName
+		}
+		fmt.Fprintf(&b, "\t%q: %q,\n", r.Alpha2, name)
+	}
+	fmt.Fprintln(&b, "}")
+
+	source, err := format.Source(b.Bytes())
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := ioutil.WriteFile(*output, source, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// displayName Returns the first of the alternative names of iso-codes,
+// e.g. "Spanish" for "Spanish; Castilian"
+func displayName(name string) string {
+	return strings.TrimSpace(strings.SplitN(name, ";", 2)[0])
+}
+
+// title Capitalizes the first letter of `name`
+func title(name string) string {
+	r, size := utf8.DecodeRuneInString(name)
+	return string(unicode.ToUpper(r)) + name[size:]
+}
+
+func readJSON(filename string, v interface{}) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		log.Fatal(filename, ": ", err)
+	}
+}
+
+// readMO Reads the translations of a gettext .mo file
+func readMO(filename string) (map[string]string, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < 20 {
+		return nil, errors.New("invalid .mo file " + filename)
+	}
+	var order binary.ByteOrder = binary.LittleEndian
+	if order.Uint32(data) != 0x950412de {
+		order = binary.BigEndian
+	}
+	count, originals, translations := order.Uint32(data[8:]), order.Uint32(data[12:]), order.Uint32(data[16:])
+	str := func(table, i uint32) string {
+		entry := table + 8*i
+		length, offset := order.Uint32(data[entry:]), order.Uint32(data[entry+4:])
+		return string(data[offset : offset+length])
+	}
+	messages := make(map[string]string, count)
+	for i := uint32(0); i < count; i++ {
+		if t := str(translations, i); len(t) > 0 {
+			messages[str(originals, i)] = t
+		}
+	}
+	return messages, nil
+}
@@ -0,0 +1,350 @@
+// Package language models languages as BCP 47 tags (RFC 5646), built from the ISO 639
+// language codes and ISO 3166-1 region codes, and gives their display names.
+//
+// tables.go is generated from Debian's iso-codes with `go run gen.go`.
+package language
+
+import (
+	"errors"
+	"strings"
+	"unicode"
+)
+
+// ErrInvalidTag Is returned when a language tag cannot be parsed
+var ErrInvalidTag = errors.New("invalid language tag")
+
+//go:generate go run gen.go
+
+// Language An ISO 639-2 language
+type Language struct {
+	Alpha2        string // ISO 639-1 code, e.g. "fr". Empty if the language has none
+	Alpha3        string // ISO 639-2/T code, e.g. "fra"
+	Bibliographic string // ISO 639-2/B code when it differs from Alpha3, e.g. "fre"
+	Name          string // English name
+	Native        string // Name in the language itself. Empty if unknown
+}
+
+// Code Returns the code of the language in BCP 47 tags: its ISO 639-1 code if any
+func (l *Language) Code() string {
+	if len(l.Alpha2) > 0 {
+		return l.Alpha2
+	}
+	return l.Alpha3
+}
+
+// Tag A BCP 47 language tag, e.g. "fr" or "fr-CA". See Parse.
+type Tag string
+
+// Unknown The tag of streams without language
+const Unknown Tag = ""
+
+// Tags of the languages and dialects of our catalog
+const (
+	English              Tag = "en"
+	AmericanEnglish      Tag = "en-US"
+	BritishEnglish       Tag = "en-GB"
+	French               Tag = "fr"
+	CanadianFrench       Tag = "fr-CA"
+	EuropeanFrench       Tag = "fr-FR"
+	German               Tag = "de"
+	Spanish              Tag = "es"
+	EuropeanSpanish      Tag = "es-ES"
+	LatinAmericanSpanish Tag = "es-419"
+	Italian              Tag = "it"
+	Portuguese           Tag = "pt"
+	BrazilianPortuguese  Tag = "pt-BR"
+	Japanese             Tag = "ja"
+	Korean               Tag = "ko"
+	Chinese              Tag = "zh"
+	Arabic               Tag = "ar"
+	Russian              Tag = "ru"
+)
+
+// deprecatedCodes ISO 639-1 codes that were replaced
+var deprecatedCodes = map[string]string{"iw": "he", "in": "id", "ji": "yi", "jw": "jv", "mo": "ro"}
+
+// byCode The languages of iso639, by ISO 639-1, 639-2/T and 639-2/B code
+var byCode = map[string]*Language{}
+
+// byName The languages that have an ISO 639-1 code, by lowercase English, native and translated names
+var byName = map[string]Tag{}
+
+func init() {
+	for i := range iso639 {
+		l := &iso639[i]
+		for _, code := range []string{l.Alpha2, l.Alpha3, l.Bibliographic} {
+			if len(code) > 0 {
+				byCode[code] = l
+			}
+		}
+	}
+	for _, names := range displayNames {
+		for code, name := range names {
+			byName[strings.ToLower(name)] = Tag(code)
+		}
+	}
+	for _, l := range iso639 {
+		if len(l.Alpha2) == 0 {
+			continue
+		}
+		for _, name := range []string{l.Name, l.Native} {
+			if len(name) > 0 {
+				byName[strings.ToLower(name)] = Tag(l.Alpha2)
+			}
+		}
+	}
+}
+
+// Lookup Returns the language with the ISO 639-1, 639-2/T or 639-2/B code `code`, case insensitive
+func Lookup(code string) (*Language, bool) {
+	code = strings.ToLower(code)
+	if replacement, ok := deprecatedCodes[code]; ok {
+		code = replacement
+	}
+	l, ok := byCode[code]
+	return l, ok
+}
+
+// Parse Parses a language tag or an ISO 639 code, e.g. "fr-ca", "fr_CA", "fre" or "fra",
+// into its canonical form: the ISO 639-1 code of the language if it has one, followed
+// by its title-case script and upper-case region, e.g. "zh-Hant-TW".
+// Unknown ISO 3166-1 regions and ill-formed subtags are invalid. "und" and "" are Unknown.
+func Parse(s string) (Tag, error) {
+	subtags := strings.FieldsFunc(strings.TrimSpace(s), func(r rune) bool { return r == '-' || r == '_' })
+	if len(subtags) == 0 {
+		return Unknown, nil
+	}
+	l, ok := Lookup(subtags[0])
+	if !ok {
+		return Unknown, ErrInvalidTag
+	}
+	if l.Alpha3 == "und" {
+		return Unknown, nil
+	}
+	parts := []string{l.Code()}
+	rest := subtags[1:]
+	if len(rest) > 0 && len(rest[0]) == 4 && isLetters(rest[0]) {
+		parts = append(parts, strings.Title(strings.ToLower(rest[0])))
+		rest = rest[1:]
+	}
+	if len(rest) > 0 && isRegion(rest[0]) {
+		region, err := parseRegion(rest[0])
+		if err != nil {
+			return Unknown, err
+		}
+		parts = append(parts, region)
+		rest = rest[1:]
+	}
+	if !wellFormedTail(rest) {
+		return Unknown, ErrInvalidTag
+	}
+	for _, subtag := range rest {
+		parts = append(parts, strings.ToLower(subtag))
+	}
+	return Tag(strings.Join(parts, "-")), nil
+}
+
+// wellFormedTail Tells whether the subtags following the region are well-formed
+// variants, then extensions and private use subtags, each introduced by a singleton
+func wellFormedTail(subtags []string) bool {
+	singleton := ""
+	extended := false // Whether the current extension has a subtag
+	for _, subtag := range subtags {
+		if len(subtag) > 8 || !isAlphanumeric(subtag) {
+			return false
+		}
+		switch {
+		case strings.EqualFold(singleton, "x"):
+			extended = true // Private use subtags are free
+		case len(subtag) == 1:
+			if len(singleton) > 0 && !extended {
+				return false
+			}
+			singleton, extended = subtag, false
+		case len(singleton) > 0:
+			extended = true
+		case len(subtag) < 4 || len(subtag) == 4 && !unicode.IsDigit(rune(subtag[0])):
+			return false // Not a variant
+		}
+	}
+	return len(singleton) == 0 || extended
+}
+
+// MustParse Parses `s` like Parse, and panics if it is invalid
+func MustParse(s string) Tag {
+	t, err := Parse(s)
+	if err != nil {
+		panic(err.Error() + ": " + s)
+	}
+	return t
+}
+
+// isRegion Tells whether `subtag` has the form of a region subtag: two letters or three digits
+func isRegion(subtag string) bool {
+	return len(subtag) == 2 && isLetters(subtag) || len(subtag) == 3 && strings.Trim(subtag, "0123456789") == ""
+}
+
+// parseRegion Returns the canonical form of a region subtag: an upper-case ISO 3166-1 code,
+// or a UN M.49 code. Returns ErrInvalidTag if the ISO 3166-1 code is unknown.
+func parseRegion(subtag string) (string, error) {
+	if len(subtag) == 3 {
+		return subtag, nil
+	}
+	region := strings.ToUpper(subtag)
+	if _, ok := regionNames[region]; !ok {
+		return "", ErrInvalidTag
+	}
+	return region, nil
+}
+
+func isLetters(s string) bool {
+	for _, r := range s {
+		if r > unicode.MaxASCII || !unicode.IsLetter(r) {
+			return false
+		}
+	}
+	return true
+}
+
+func isAlphanumeric(s string) bool {
+	for _, r := range s {
+		if r > unicode.MaxASCII || !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// subtags Returns the language, script and region subtags of the tag
+func (t Tag) subtags() (language, script, region string) {
+	parts := strings.Split(string(t), "-")
+	language = parts[0]
+	for _, p := range parts[1:] {
+		switch {
+		case len(p) == 4 && isLetters(p) && len(script) == 0 && len(region) == 0:
+			script = p
+		case (len(p) == 2 || len(p) == 3 && p[0] >= '0' && p[0] <= '9') && len(region) == 0:
+			region = p
+		}
+	}
+	return
+}
+
+// Base Returns the language of the tag without its script and region, e.g. "fr" for "fr-CA"
+func (t Tag) Base() Tag {
+	language, _, _ := t.subtags()
+	return Tag(language)
+}
+
+// Script Returns the script subtag of the tag, e.g. "Hant" for "zh-Hant-TW", or ""
+func (t Tag) Script() string {
+	_, script, _ := t.subtags()
+	return script
+}
+
+// Region Returns the region subtag of the tag, e.g. "CA" for "fr-CA", or ""
+func (t Tag) Region() string {
+	_, _, region := t.subtags()
+	return region
+}
+
+// WithRegion Returns the tag of the language of `t` spoken in `region`
+func (t Tag) WithRegion(region string) Tag {
+	language, script, _ := t.subtags()
+	parts := []string{language}
+	if len(script) > 0 {
+		parts = append(parts, script)
+	}
+	if len(region) > 0 {
+		parts = append(parts, region)
+	}
+	return Tag(strings.Join(parts, "-"))
+}
+
+// Language Returns the ISO 639 language of the tag, or `nil` if Unknown
+func (t Tag) Language() *Language {
+	l, _ := Lookup(string(t.Base()))
+	return l
+}
+
+// ISO6392 Returns the ISO 639-2/B code of the tag, as written in Matroska and MP4 metadata,
+// e.g. "fre" for "fr-CA". Returns "und" if Unknown.
+func (t Tag) ISO6392() string {
+	l := t.Language()
+	switch {
+	case l == nil:
+		return "und"
+	case len(l.Bibliographic) > 0:
+		return l.Bibliographic
+	default:
+		return l.Alpha3
+	}
+}
+
+// Matches Returns true if `t` is `other` or one of its dialects, e.g. "fr-CA" matches "fr"
+func (t Tag) Matches(other Tag) bool {
+	return t == other || strings.HasPrefix(string(t), string(other)+"-")
+}
+
+// Name Returns the name of the language in itself, e.g. "Français (Canada)" for "fr-CA".
+// See DisplayName.
+func (t Tag) Name() string {
+	return t.DisplayName(t)
+}
+
+// DisplayName Returns the name of the language of `t` in the language of `in`,
+// followed by its script and region, e.g. "Französisch (Kanada)" for "fr-CA" in German.
+// Names that are not translated are in English. Returns "" if Unknown.
+func (t Tag) DisplayName(in Tag) string {
+	l := t.Language()
+	if l == nil {
+		return ""
+	}
+	display := in.Base()
+	name := localizedName(display, l)
+	var qualifiers []string
+	if script := t.Script(); len(script) > 0 {
+		qualifiers = append(qualifiers, localized(scriptNames, display, script))
+	}
+	if region := t.Region(); len(region) > 0 {
+		qualifiers = append(qualifiers, localized(localizedRegionNames, display, region))
+	}
+	if len(qualifiers) > 0 {
+		name += " (" + strings.Join(qualifiers, ", ") + ")"
+	}
+	return name
+}
+
+// localizedName Returns the name of `l` in the language `display`
+func localizedName(display Tag, l *Language) string {
+	if name, ok := displayNames[display][l.Code()]; ok {
+		return name
+	}
+	if display == Tag(l.Code()) && len(l.Native) > 0 {
+		return l.Native
+	}
+	return l.Name
+}
+
+// localized Returns the name of `code` in `table` for the language `display`,
+// in English if it is not translated, or `code` itself if it has no name
+func localized(table map[Tag]map[string]string, display Tag, code string) string {
+	if name, ok := table[display][code]; ok {
+		return name
+	}
+	if name, ok := table[English][code]; ok {
+		return name
+	}
+	if name, ok := regionNames[code]; ok {
+		return name
+	}
+	return code
+}
+
+// FromName Returns the language named `name` in English, in itself, or in one of the
+// translated display languages, case insensitive, e.g. French for "Français".
+// Only languages with an ISO 639-1 code are recognized.
+func FromName(name string) (Tag, bool) {
+	t, ok := byName[strings.ToLower(strings.TrimSpace(name))]
+	return t, ok
+}
@@ -0,0 +1,64 @@
+package language
+
+import "testing"
+
+func TestParse(t *testing.T) {
+	for input, expected := range map[string]Tag{
+		"fr":            French,
+		"fre":           French,
+		"fra":           French,
+		"FR_ca":         CanadianFrench,
+		"es-419":        LatinAmericanSpanish,
+		"zh-hant-tw":    "zh-Hant-TW",
+		"iw":            "he",
+		"und":           Unknown,
+		"":              Unknown,
+		"haw":           "haw",
+		"en-gb":         BritishEnglish,
+		"sr-latn-rs":    "sr-Latn-RS",
+		"de-CH-1996":    "de-CH-1996",
+		"en-US-x-twain": "en-US-x-twain",
+	} {
+		tag, err := Parse(input)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", input, err)
+		} else if tag != expected {
+			t.Errorf("Parse(%q) = %q, expected %q", input, tag, expected)
+		}
+	}
+	for _, input := range []string{"xx", "french", "fr-toolongsubtag", "en-ZZ", "fr-CA-ZZ", "fr-c@", "en-US-u"} {
+		if _, err := Parse(input); err == nil {
+			t.Errorf("Parse(%q) should fail", input)
+		}
+	}
+	if region := MustParse("fr-ca").Region(); region != "CA" {
+		t.Errorf("Unexpected region %q", region)
+	}
+	if CanadianFrench.ISO6392() != "fre" || Unknown.ISO6392() != "und" {
+		t.Error("ISO 639-2 codes should be bibliographic")
+	}
+	if !CanadianFrench.Matches(French) || French.Matches(CanadianFrench) || Tag("frr").Matches(French) {
+		t.Error("a dialect should only match its language")
+	}
+}
+
+func TestDisplayName(t *testing.T) {
+	for _, c := range []struct {
+		tag, in  Tag
+		expected string
+	}{
+		{CanadianFrench, CanadianFrench, "Français (Canada)"},
+		{LatinAmericanSpanish, Spanish, "Español (Latinoamérica)"},
+		{CanadianFrench, German, "Französisch (Kanada)"},
+		{"zh-Hant-TW", Chinese, "中文 (繁體, Taiwan)"},
+		{Japanese, English, "Japanese"},
+		{Unknown, English, ""},
+	} {
+		if name := c.tag.DisplayName(c.in); name != c.expected {
+			t.Errorf("%q in %q: %q, expected %q", c.tag, c.in, name, c.expected)
+		}
+	}
+	if tag, ok := FromName("Deutsch"); !ok || tag != German {
+		t.Errorf("FromName(Deutsch) = %q", tag)
+	}
+}
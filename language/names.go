@@ -0,0 +1,124 @@
+package language
+
+// displayNames The names of common languages, by display language then ISO 639-1 code.
+// English names and the names of languages in themselves are in tables.go.
+var displayNames = map[Tag]map[string]string{
+	French: {
+		"ar": "Arabe", "bg": "Bulgare", "ca": "Catalan", "cs": "Tchèque", "da": "Danois", "de": "Allemand",
+		"el": "Grec", "en": "Anglais", "es": "Espagnol", "fa": "Persan", "fi": "Finnois", "fr": "Français",
+		"he": "Hébreu", "hi": "Hindi", "hr": "Croate", "hu": "Hongrois", "id": "Indonésien", "it": "Italien",
+		"ja": "Japonais", "ko": "Coréen", "ms": "Malais", "nb": "Norvégien bokmål", "nl": "Néerlandais",
+		"no": "Norvégien", "pl": "Polonais", "pt": "Portugais", "ro": "Roumain", "ru": "Russe", "sk": "Slovaque",
+		"sr": "Serbe", "sv": "Suédois", "th": "Thaï", "tr": "Turc", "uk": "Ukrainien", "vi": "Vietnamien",
+		"zh": "Chinois",
+	},
+	German: {
+		"ar": "Arabisch", "bg": "Bulgarisch", "ca": "Katalanisch", "cs": "Tschechisch", "da": "Dänisch",
+		"de": "Deutsch", "el": "Griechisch", "en": "Englisch", "es": "Spanisch", "fa": "Persisch", "fi": "Finnisch",
+		"fr": "Französisch", "he": "Hebräisch", "hi": "Hindi", "hr": "Kroatisch", "hu": "Ungarisch",
+		"id": "Indonesisch", "it": "Italienisch", "ja": "Japanisch", "ko": "Koreanisch", "ms": "Malaiisch",
+		"nb": "Norwegisch (Bokmål)", "nl": "Niederländisch", "no": "Norwegisch", "pl": "Polnisch",
+		"pt": "Portugiesisch", "ro": "Rumänisch", "ru": "Russisch", "sk": "Slowakisch", "sr": "Serbisch",
+		"sv": "Schwedisch", "th": "Thailändisch", "tr": "Türkisch", "uk": "Ukrainisch", "vi": "Vietnamesisch",
+		"zh": "Chinesisch",
+	},
+	Spanish: {
+		"ar": "Árabe", "bg": "Búlgaro", "ca": "Catalán", "cs": "Checo", "da": "Danés", "de": "Alemán",
+		"el": "Griego", "en": "Inglés", "es": "Español", "fa": "Persa", "fi": "Finés", "fr": "Francés",
+		"he": "Hebreo", "hi": "Hindi", "hr": "Croata", "hu": "Húngaro", "id": "Indonesio", "it": "Italiano",
+		"ja": "Japonés", "ko": "Coreano", "ms": "Malayo", "nb": "Noruego bokmal", "nl": "Neerlandés",
+		"no": "Noruego", "pl": "Polaco", "pt": "Portugués", "ro": "Rumano", "ru": "Ruso", "sk": "Eslovaco",
+		"sr": "Serbio", "sv": "Sueco", "th": "Tailandés", "tr": "Turco", "uk": "Ucraniano", "vi": "Vietnamita",
+		"zh": "Chino",
+	},
+	Italian: {
+		"ar": "Arabo", "bg": "Bulgaro", "ca": "Catalano", "cs": "Ceco", "da": "Danese", "de": "Tedesco",
+		"el": "Greco", "en": "Inglese", "es": "Spagnolo", "fa": "Persiano", "fi": "Finlandese", "fr": "Francese",
+		"he": "Ebraico", "hi": "Hindi", "hr": "Croato", "hu": "Ungherese", "id": "Indonesiano", "it": "Italiano",
+		"ja": "Giapponese", "ko": "Coreano", "ms": "Malese", "nb": "Norvegese bokmål", "nl": "Olandese",
+		"no": "Norvegese", "pl": "Polacco", "pt": "Portoghese", "ro": "Rumeno", "ru": "Russo", "sk": "Slovacco",
+		"sr": "Serbo", "sv": "Svedese", "th": "Thailandese", "tr": "Turco", "uk": "Ucraino", "vi": "Vietnamita",
+		"zh": "Cinese",
+	},
+	Portuguese: {
+		"ar": "Árabe", "bg": "Búlgaro", "ca": "Catalão", "cs": "Tcheco", "da": "Dinamarquês", "de": "Alemão",
+		"el": "Grego", "en": "Inglês", "es": "Espanhol", "fa": "Persa", "fi": "Finlandês", "fr": "Francês",
+		"he": "Hebraico", "hi": "Híndi", "hr": "Croata", "hu": "Húngaro", "id": "Indonésio", "it": "Italiano",
+		"ja": "Japonês", "ko": "Coreano", "ms": "Malaio", "nb": "Bokmål norueguês", "nl": "Holandês",
+		"no": "Norueguês", "pl": "Polonês", "pt": "Português", "ro": "Romeno", "ru": "Russo", "sk": "Eslovaco",
+		"sr": "Sérvio", "sv": "Sueco", "th": "Tailandês", "tr": "Turco", "uk": "Ucraniano", "vi": "Vietnamita",
+		"zh": "Chinês",
+	},
+	Japanese: {
+		"ar": "アラビア語", "bg": "ブルガリア語", "ca": "カタロニア語", "cs": "チェコ語", "da": "デンマーク語", "de": "ドイツ語",
+		"el": "ギリシャ語", "en": "英語", "es": "スペイン語", "fa": "ペルシア語", "fi": "フィンランド語", "fr": "フランス語",
+		"he": "ヘブライ語", "hi": "ヒンディー語", "hr": "クロアチア語", "hu": "ハンガリー語", "id": "インドネシア語", "it": "イタリア語",
+		"ja": "日本語", "ko": "韓国語", "ms": "マレー語", "nb": "ノルウェー語(ブークモール)", "nl": "オランダ語",
+		"no": "ノルウェー語", "pl": "ポーランド語", "pt": "ポルトガル語", "ro": "ルーマニア語", "ru": "ロシア語", "sk": "スロバキア語",
+		"sr": "セルビア語", "sv": "スウェーデン語", "th": "タイ語", "tr": "トルコ語", "uk": "ウクライナ語", "vi": "ベトナム語",
+		"zh": "中国語",
+	},
+	Arabic: {
+		"ar": "العربية", "bg": "البلغارية", "ca": "الكتالانية", "cs": "التشيكية", "da": "الدانمركية", "de": "الألمانية",
+		"el": "اليونانية", "en": "الإنجليزية", "es": "الإسبانية", "fa": "الفارسية", "fi": "الفنلندية", "fr": "الفرنسية",
+		"he": "العبرية", "hi": "الهندية", "hr": "الكرواتية", "hu": "الهنغارية", "id": "الإندونيسية", "it": "الإيطالية",
+		"ja": "اليابانية", "ko": "الكورية", "ms": "الماليزية", "nb": "النرويجية بوكمال", "nl": "الهولندية",
+		"no": "النرويجية", "pl": "البولندية", "pt": "البرتغالية", "ro": "الرومانية", "ru": "الروسية", "sk": "السلوفاكية",
+		"sr": "الصربية", "sv": "السويدية", "th": "التايلاندية", "tr": "التركية", "uk": "الأوكرانية", "vi": "الفيتنامية",
+		"zh": "الصينية",
+	},
+}
+
+// localizedRegionNames The names of the regions used by common dialects, by display language.
+// Other names are the English ones of tables.go.
+var localizedRegionNames = map[Tag]map[string]string{
+	English: {"419": "Latin America"},
+	French: {
+		"US": "États-Unis", "GB": "Royaume-Uni", "AU": "Australie", "CA": "Canada", "FR": "France",
+		"BE": "Belgique", "CH": "Suisse", "DE": "Allemagne", "AT": "Autriche", "ES": "Espagne", "MX": "Mexique",
+		"419": "Amérique latine", "BR": "Brésil", "PT": "Portugal", "CN": "Chine", "TW": "Taïwan",
+		"HK": "Hong Kong", "IN": "Inde",
+	},
+	German: {
+		"US": "Vereinigte Staaten", "GB": "Vereinigtes Königreich", "AU": "Australien", "CA": "Kanada",
+		"FR": "Frankreich", "BE": "Belgien", "CH": "Schweiz", "DE": "Deutschland", "AT": "Österreich",
+		"ES": "Spanien", "MX": "Mexiko", "419": "Lateinamerika", "BR": "Brasilien", "PT": "Portugal",
+		"CN": "China", "TW": "Taiwan", "HK": "Hongkong", "IN": "Indien",
+	},
+	Spanish: {
+		"US": "Estados Unidos", "GB": "Reino Unido", "AU": "Australia", "CA": "Canadá", "FR": "Francia",
+		"BE": "Bélgica", "CH": "Suiza", "DE": "Alemania", "AT": "Austria", "ES": "España", "MX": "México",
+		"419": "Latinoamérica", "BR": "Brasil", "PT": "Portugal", "CN": "China", "TW": "Taiwán",
+		"HK": "Hong Kong", "IN": "India",
+	},
+	Italian: {
+		"US": "Stati Uniti", "GB": "Regno Unito", "AU": "Australia", "CA": "Canada", "FR": "Francia",
+		"BE": "Belgio", "CH": "Svizzera", "DE": "Germania", "AT": "Austria", "ES": "Spagna", "MX": "Messico",
+		"419": "America Latina", "BR": "Brasile", "PT": "Portogallo", "CN": "Cina", "TW": "Taiwan",
+		"HK": "Hong Kong", "IN": "India",
+	},
+	Portuguese: {
+		"US": "Estados Unidos", "GB": "Reino Unido", "AU": "Austrália", "CA": "Canadá", "FR": "França",
+		"BE": "Bélgica", "CH": "Suíça", "DE": "Alemanha", "AT": "Áustria", "ES": "Espanha", "MX": "México",
+		"419": "América Latina", "BR": "Brasil", "PT": "Portugal", "CN": "China", "TW": "Taiwan",
+		"HK": "Hong Kong", "IN": "Índia",
+	},
+	Japanese: {
+		"US": "アメリカ合衆国", "GB": "イギリス", "AU": "オーストラリア", "CA": "カナダ", "FR": "フランス", "BE": "ベルギー",
+		"CH": "スイス", "DE": "ドイツ", "AT": "オーストリア", "ES": "スペイン", "MX": "メキシコ", "419": "ラテンアメリカ",
+		"BR": "ブラジル", "PT": "ポルトガル", "CN": "中国", "TW": "台湾", "HK": "香港", "IN": "インド",
+	},
+	Arabic: {
+		"US": "الولايات المتحدة", "GB": "المملكة المتحدة", "AU": "أستراليا", "CA": "كندا", "FR": "فرنسا",
+		"BE": "بلجيكا", "CH": "سويسرا", "DE": "ألمانيا", "AT": "النمسا", "ES": "إسبانيا", "MX": "المكسيك",
+		"419": "أمريكا اللاتينية", "BR": "البرازيل", "PT": "البرتغال", "CN": "الصين", "TW": "تايوان",
+		"HK": "هونغ كونغ", "IN": "الهند",
+	},
+}
+
+// scriptNames The names of common ISO 15924 scripts, by display language
+var scriptNames = map[Tag]map[string]string{
+	English: {"Hans": "Simplified", "Hant": "Traditional", "Latn": "Latin", "Cyrl": "Cyrillic", "Arab": "Arabic"},
+	French:  {"Hans": "simplifié", "Hant": "traditionnel", "Latn": "latin", "Cyrl": "cyrillique", "Arab": "arabe"},
+	Chinese: {"Hans": "简体", "Hant": "繁體"},
+}
@@ -0,0 +1,136 @@
+package llhls
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// sampleIsNonSync The sample_is_non_sync_sample bit of ISO/IEC 14496-12 sample flags
+const sampleIsNonSync = 0x10000
+
+// forEachBox Calls `f` with the type, the bytes and the payload of each box of `data`, in order
+func forEachBox(data []byte, f func(typ string, box, payload []byte) error) error {
+	for len(data) > 0 {
+		if len(data) < 8 {
+			return fmt.Errorf("truncated box header")
+		}
+		size, header := uint64(binary.BigEndian.Uint32(data)), uint64(8)
+		typ := string(data[4:8])
+		switch size {
+		case 0: // Up to the end of the file
+			size = uint64(len(data))
+		case 1:
+			if len(data) < 16 {
+				return fmt.Errorf("truncated %q box header", typ)
+			}
+			size, header = binary.BigEndian.Uint64(data[8:]), 16
+		}
+		if size < header || size > uint64(len(data)) {
+			return fmt.Errorf("invalid size %d of %q box", size, typ)
+		}
+		if err := f(typ, data[:size], data[header:size]); err != nil {
+			return err
+		}
+		data = data[size:]
+	}
+	return nil
+}
+
+// findBox Returns the payload of the first box at `path` in `data`, e.g. "moov", "mvex", "trex"
+func findBox(data []byte, path ...string) []byte {
+	for _, typ := range path {
+		var found []byte
+		forEachBox(data, func(t string, _, payload []byte) error {
+			if t == typ && found == nil {
+				found = payload
+			}
+			return nil
+		})
+		if found == nil {
+			return nil
+		}
+		data = found
+	}
+	return data
+}
+
+// defaultSampleFlags Returns the default sample flags of the first track of the initialization segment `init`,
+// from its 'trex' box. 0 if there is none.
+func defaultSampleFlags(init []byte) uint32 {
+	trex := findBox(init, "moov", "mvex", "trex")
+	if len(trex) < 24 {
+		return 0
+	}
+	return binary.BigEndian.Uint32(trex[20:])
+}
+
+// startsWithSyncSample Tells whether the first sample of the media segment `data` is a sync sample.
+// `trexFlags` are the default sample flags of the initialization segment.
+func startsWithSyncSample(data []byte, trexFlags uint32) (bool, error) {
+	traf := findBox(data, "moof", "traf")
+	tfhd, trun := findBox(traf, "tfhd"), findBox(traf, "trun")
+	if len(tfhd) < 8 || len(trun) < 8 {
+		return false, fmt.Errorf("no track fragment")
+	}
+	flags := trexFlags
+
+	// tfhd: version & flags, track ID, then optional fields
+	tfhdFlags := binary.BigEndian.Uint32(tfhd) & 0xffffff
+	offset := 8
+	for _, field := range []struct {
+		flag uint32
+		size int
+	}{{0x1, 8}, {0x2, 4}, {0x8, 4}, {0x10, 4}} {
+		if tfhdFlags&field.flag != 0 {
+			offset += field.size
+		}
+	}
+	if tfhdFlags&0x20 != 0 {
+		if len(tfhd) < offset+4 {
+			return false, fmt.Errorf("truncated 'tfhd' box")
+		}
+		flags = binary.BigEndian.Uint32(tfhd[offset:])
+	}
+
+	// trun: version & flags, sample count, then optional fields and the first sample
+	trunFlags := binary.BigEndian.Uint32(trun) & 0xffffff
+	offset = 8
+	if trunFlags&0x1 != 0 { // data offset
+		offset += 4
+	}
+	if trunFlags&0x4 != 0 { // first sample flags
+		if len(trun) < offset+4 {
+			return false, fmt.Errorf("truncated 'trun' box")
+		}
+		flags = binary.BigEndian.Uint32(trun[offset:])
+	} else if trunFlags&0x400 != 0 { // sample flags, after the duration and size of the sample
+		for _, flag := range []uint32{0x100, 0x200} {
+			if trunFlags&flag != 0 {
+				offset += 4
+			}
+		}
+		if len(trun) < offset+4 {
+			return false, fmt.Errorf("truncated 'trun' box")
+		}
+		flags = binary.BigEndian.Uint32(trun[offset:])
+	}
+	return flags&sampleIsNonSync == 0, nil
+}
+
+// joinParts Returns the media segment made of the `parts` written one after the other.
+// Only the segment type box of the first part is kept.
+func joinParts(parts [][]byte) ([]byte, error) {
+	var segment []byte
+	for i, part := range parts {
+		err := forEachBox(part, func(typ string, box, _ []byte) error {
+			if i == 0 || typ != "styp" {
+				segment = append(segment, box...)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return segment, nil
+}
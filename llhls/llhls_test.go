@@ -0,0 +1,201 @@
+package llhls
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPlaylistEncode(t *testing.T) {
+	p := Playlist{
+		TargetDuration: 4 * time.Second,
+		PartTarget:     time.Second,
+		Type:           "EVENT",
+		MapURI:         "init_0.mp4",
+		PreloadHint:    "part_0_17.m4s",
+	}
+	for i := 0; i < 4; i++ {
+		s := Segment{Duration: 4 * time.Second, URI: fmt.Sprintf("segment_0_%d.m4s", i)}
+		for j := 0; j < 4; j++ {
+			s.Parts = append(s.Parts, Part{Duration: time.Second, URI: fmt.Sprintf("part_0_%d.m4s", 4*i+j), Independent: j == 0})
+		}
+		p.Segments = append(p.Segments, s)
+	}
+	p.Pending = []Part{{Duration: time.Second, URI: "part_0_16.m4s", Independent: true}}
+	var b bytes.Buffer
+	if err := p.Encode(&b); err != nil {
+		t.Fatal(err)
+	}
+	encoded := b.String()
+	for _, expected := range []string{
+		"#EXT-X-TARGETDURATION:4\n",
+		"#EXT-X-SERVER-CONTROL:CAN-BLOCK-RELOAD=YES,PART-HOLD-BACK=3.00000\n",
+		"#EXT-X-PART-INF:PART-TARGET=1.00000\n",
+		"#EXT-X-MAP:URI=\"init_0.mp4\"\n",
+		"#EXT-X-PART:DURATION=1.00000,URI=\"part_0_12.m4s\",INDEPENDENT=YES\n#EXT-X-PART:DURATION=1.00000,URI=\"part_0_13.m4s\"\n",
+		"#EXT-X-PART:DURATION=1.00000,URI=\"part_0_15.m4s\"\n#EXTINF:4.00000,\nsegment_0_3.m4s\n",
+		"#EXT-X-PART:DURATION=1.00000,URI=\"part_0_16.m4s\",INDEPENDENT=YES\n#EXT-X-PRELOAD-HINT:TYPE=PART,URI=\"part_0_17.m4s\"\n",
+	} {
+		if !strings.Contains(encoded, expected) {
+			t.Errorf("Expected %q in playlist:\n%s", expected, encoded)
+		}
+	}
+	// Only the parts of the last three target durations are listed
+	if strings.Contains(encoded, "part_0_3.m4s") || !strings.Contains(encoded, "part_0_4.m4s") {
+		t.Errorf("Unexpected parts in playlist:\n%s", encoded)
+	}
+
+	// Parts and segments cannot last longer than their targets
+	p.Pending[0].Duration = 1100 * time.Millisecond
+	if err := p.Encode(ioutil.Discard); err == nil {
+		t.Error("A part longer than the part target should be rejected")
+	}
+	p.Pending[0].Duration = time.Second
+	p.Segments[3].Duration = 4600 * time.Millisecond
+	if err := p.Encode(ioutil.Discard); err == nil {
+		t.Error("A segment longer than the target duration should be rejected")
+	}
+	p.Segments[3].Duration = 4 * time.Second
+
+	p.Ended = true
+	b.Reset()
+	p.Encode(&b)
+	if !strings.HasSuffix(b.String(), "#EXT-X-ENDLIST\n") || strings.Contains(b.String(), "PRELOAD-HINT") {
+		t.Errorf("Unexpected ended playlist:\n%s", b.String())
+	}
+}
+
+// testBox Returns the box of type `typ` with `payload`
+func testBox(typ string, payload ...[]byte) []byte {
+	data := bytes.Join(payload, nil)
+	box := make([]byte, 8, 8+len(data))
+	binary.BigEndian.PutUint32(box, uint32(8+len(data)))
+	copy(box[4:], typ)
+	return append(box, data...)
+}
+
+func testUint32s(values ...uint32) []byte {
+	b := make([]byte, 4*len(values))
+	for i, v := range values {
+		binary.BigEndian.PutUint32(b[4*i:], v)
+	}
+	return b
+}
+
+// testPart Returns a fragment whose 'trun' box sets the flags of its first sample
+func testPart(independent bool) []byte {
+	flags := uint32(sampleIsNonSync)
+	if independent {
+		flags = 0x2000000
+	}
+	traf := testBox("traf",
+		testBox("tfhd", testUint32s(0x20000, 1)),
+		testBox("trun", testUint32s(0x4, 1, flags)))
+	return append(testBox("styp", []byte("msdh")), append(testBox("moof", traf), testBox("mdat", []byte{1, 2, 3})...)...)
+}
+
+func TestPackager(t *testing.T) {
+	dir, err := ioutil.TempDir("", "llhls")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	init := testBox("moov", testBox("mvex", testBox("trex", testUint32s(0, 1, 1, 0, 0, sampleIsNonSync))))
+	if err := ioutil.WriteFile(filepath.Join(dir, "init_0.mp4"), init, 0600); err != nil {
+		t.Fatal(err)
+	}
+	// Keyframes every two parts
+	source := "#EXTM3U\n#EXT-X-VERSION:7\n#EXT-X-TARGETDURATION:1\n#EXT-X-MEDIA-SEQUENCE:0\n#EXT-X-MAP:URI=\"init_0.mp4\"\n"
+	writeSource := func(parts int, ended bool) {
+		s := source
+		for i := 0; i < parts; i++ {
+			name := fmt.Sprintf("part_0_%d.m4s", i)
+			if err := ioutil.WriteFile(filepath.Join(dir, name), testPart(i%2 == 0), 0600); err != nil {
+				t.Fatal(err)
+			}
+			s += "#EXTINF:1.000000,\n" + name + "\n"
+		}
+		if ended {
+			s += "#EXT-X-ENDLIST\n"
+		}
+		if err := ioutil.WriteFile(filepath.Join(dir, "stream_0_parts.m3u8"), []byte(s), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	p := &Packager{
+		Dir:             dir,
+		Source:          "stream_0_parts.m3u8",
+		Output:          "stream_0.m3u8",
+		PartFilename:    "part_%v_%d.m4s",
+		SegmentFilename: "segment_%v_%d.m4s",
+		SegmentDuration: 2 * time.Second,
+		PartDuration:    time.Second,
+		Type:            "EVENT",
+	}
+
+	writeSource(3, false)
+	if ended, err := p.Update(); err != nil || ended {
+		t.Fatal("Unexpected update:", ended, err)
+	}
+	data, _ := ioutil.ReadFile(filepath.Join(dir, "stream_0.m3u8"))
+	if !strings.Contains(string(data), "#EXTINF:2.00000,\nsegment_0_0.m4s\n#EXT-X-PART:DURATION=1.00000,URI=\"part_0_2.m4s\",INDEPENDENT=YES\n"+
+		"#EXT-X-PRELOAD-HINT:TYPE=PART,URI=\"part_0_3.m4s\"\n") {
+		t.Errorf("Unexpected playlist:\n%s", data)
+	}
+
+	writeSource(5, true)
+	if ended, err := p.Update(); err != nil || !ended {
+		t.Fatal("Unexpected update:", ended, err)
+	}
+	data, _ = ioutil.ReadFile(filepath.Join(dir, "stream_0.m3u8"))
+	if strings.Count(string(data), "#EXTINF") != 3 || !strings.HasSuffix(string(data), "#EXTINF:1.00000,\nsegment_0_2.m4s\n#EXT-X-ENDLIST\n") {
+		t.Errorf("Unexpected playlist:\n%s", data)
+	}
+	segment, _ := ioutil.ReadFile(filepath.Join(dir, "segment_0_1.m4s"))
+	if bytes.Count(segment, []byte("styp")) != 1 || bytes.Count(segment, []byte("moof")) != 2 {
+		t.Error("Segment is not the concatenation of its parts")
+	}
+}
+
+func TestPackagerTargets(t *testing.T) {
+	dir, err := ioutil.TempDir("", "llhls")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	// A single keyframe, and a part longer than the part target
+	source := "#EXTM3U\n#EXT-X-VERSION:7\n#EXT-X-TARGETDURATION:2\n#EXT-X-MEDIA-SEQUENCE:0\n"
+	for i, duration := range []string{"1.000000", "1.500000", "1.000000", "1.000000"} {
+		name := fmt.Sprintf("part_0_%d.m4s", i)
+		if err := ioutil.WriteFile(filepath.Join(dir, name), testPart(i == 0), 0600); err != nil {
+			t.Fatal(err)
+		}
+		source += "#EXTINF:" + duration + ",\n" + name + "\n"
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "stream_0_parts.m3u8"), []byte(source+"#EXT-X-ENDLIST\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	p := &Packager{
+		Dir:             dir,
+		Source:          "stream_0_parts.m3u8",
+		Output:          "stream_0.m3u8",
+		PartFilename:    "part_%v_%d.m4s",
+		SegmentFilename: "segment_%v_%d.m4s",
+		SegmentDuration: 2 * time.Second,
+		PartDuration:    time.Second,
+		Type:            "EVENT",
+	}
+	if ended, err := p.Update(); err != nil || !ended {
+		t.Fatal("Unexpected update:", ended, err)
+	}
+	data, _ := ioutil.ReadFile(filepath.Join(dir, "stream_0.m3u8"))
+	if !strings.Contains(string(data), "#EXT-X-TARGETDURATION:5\n") || !strings.Contains(string(data), "PART-TARGET=1.50000\n") {
+		t.Errorf("The targets should be raised to the longest segment and part:\n%s", data)
+	}
+}
@@ -0,0 +1,265 @@
+package llhls
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/grafov/m3u8"
+)
+
+// Packager Turns the media playlist written by ffmpeg, whose segments are the parts of the
+// low-latency playlist, into a low-latency media playlist. Consecutive parts are joined into
+// segments of about SegmentDuration that start with an independent part.
+type Packager struct {
+	Dir             string // Directory of the playlists and segments
+	Source          string // Media playlist of the parts, written by ffmpeg
+	Output          string // Low-latency media playlist
+	Variant         int    // Replaces `%v` in the filename templates
+	PartFilename    string // Template of the parts written by ffmpeg, see FormatFilename
+	SegmentFilename string // Template of the segments, see FormatFilename
+	SegmentDuration time.Duration
+	PartDuration    time.Duration // The PART-TARGET
+	Type            string        // "EVENT", or "" for live playlists
+	ListSize        int           // Maximum number of segments in the playlist, 0 for all of them
+	DeleteSegments  bool          // Remove the segments that left the playlist. ffmpeg removes its parts
+
+	playlist      Playlist
+	parts         [][]byte  // Data of the pending parts
+	next          int       // Media sequence number of the next part in Source
+	trexFlags     uint32    // Default sample flags of the initialization segment
+	discontinuity bool      // Whether the next segment follows a discontinuity
+	removed       []Segment // Segments that left the playlist, not deleted yet
+	longestSegment time.Duration // Rounded duration of the longest segment, see targetDuration
+	longestPart    time.Duration // See partTarget
+}
+
+// deleteDelay Number of segments that left the playlist kept for the players that loaded it last
+const deleteDelay = 1
+
+// Run Updates the playlist as ffmpeg writes parts, until it ends the source playlist or until `exited` is closed.
+// The playlist is then ended with the parts written so far.
+func (p *Packager) Run(ctx context.Context, exited <-chan struct{}) error {
+	// Parts are announced well within their duration
+	ticker := time.NewTicker(p.PartDuration / 4)
+	defer ticker.Stop()
+	for {
+		ended, err := p.Update()
+		if err != nil || ended {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-exited:
+			if ended, err := p.Update(); err != nil || ended {
+				return err
+			}
+			return p.End()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Update Reads the parts ffmpeg added to the source playlist since the last update,
+// and rewrites the low-latency playlist if there are any. Returns `true` once the source playlist has ended.
+func (p *Packager) Update() (bool, error) {
+	f, err := os.Open(filepath.Join(p.Dir, p.Source))
+	if os.IsNotExist(err) {
+		return false, nil // Not written yet
+	} else if err != nil {
+		return false, err
+	}
+	decoded, listType, err := m3u8.DecodeFrom(f, true)
+	f.Close()
+	if err != nil {
+		return false, err
+	}
+	if listType != m3u8.MEDIA {
+		return false, fmt.Errorf("%s is not a media playlist", p.Source)
+	}
+	source := decoded.(*m3u8.MediaPlaylist)
+	if len(p.playlist.MapURI) == 0 && source.Map != nil {
+		if err := p.readInit(source.Map.URI); err != nil {
+			return false, err
+		}
+	}
+
+	changed := false
+	for i, segment := range source.Segments {
+		if segment == nil {
+			break // The end of the segments buffer
+		}
+		if sequence := int(source.SeqNo) + i; sequence >= p.next {
+			if segment.Discontinuity {
+				// ffmpeg was restarted: the initialization segment was written again
+				if err := p.closeSegment(); err != nil {
+					return false, err
+				}
+				p.discontinuity = true
+				if source.Map != nil {
+					if err := p.readInit(source.Map.URI); err != nil {
+						return false, err
+					}
+				}
+			}
+			if err := p.addPart(segment.URI, time.Duration(segment.Duration*float64(time.Second))); err != nil {
+				return false, err
+			}
+			p.next = sequence + 1
+			changed = true
+		}
+	}
+	if source.Closed {
+		return true, p.End()
+	}
+	if !changed {
+		return false, nil
+	}
+	return false, p.write()
+}
+
+// readInit Reads the default sample flags of the initialization segment
+func (p *Packager) readInit(uri string) error {
+	init, err := ioutil.ReadFile(filepath.Join(p.Dir, uri))
+	if err != nil {
+		return err
+	}
+	p.trexFlags = defaultSampleFlags(init)
+	p.playlist.MapURI = uri
+	return nil
+}
+
+// End Closes the last segment and ends the playlist
+func (p *Packager) End() error {
+	if err := p.closeSegment(); err != nil {
+		return err
+	}
+	p.playlist.Ended = true
+	return p.write()
+}
+
+// addPart Adds a part ffmpeg wrote. A new segment starts with it if it is independent,
+// and if the pending parts are long enough.
+func (p *Packager) addPart(uri string, duration time.Duration) error {
+	data, err := ioutil.ReadFile(filepath.Join(p.Dir, uri))
+	if err != nil {
+		return err
+	}
+	independent, err := startsWithSyncSample(data, p.trexFlags)
+	if err != nil {
+		return fmt.Errorf("part %s: %v", uri, err)
+	}
+	if independent && p.pendingDuration() >= p.SegmentDuration-p.PartDuration/2 {
+		if err := p.closeSegment(); err != nil {
+			return err
+		}
+	}
+	if len(p.playlist.Segments) == 0 && len(p.playlist.Pending) == 0 && !independent {
+		log.Printf("WARNING: The first part of %s does not start with a sync sample\n", p.Output)
+	}
+	if duration > p.partTarget() {
+		log.Printf("WARNING: Part %s lasts %v, more than the part target: raising it\n", uri, duration)
+	}
+	if duration > p.longestPart {
+		p.longestPart = duration
+	}
+	p.playlist.Pending = append(p.playlist.Pending, Part{Duration: duration, URI: uri, Independent: independent})
+	p.parts = append(p.parts, data)
+	return nil
+}
+
+func (p *Packager) pendingDuration() time.Duration {
+	var d time.Duration
+	for _, part := range p.playlist.Pending {
+		d += part.Duration
+	}
+	return d
+}
+
+// closeSegment Joins the pending parts into a segment
+func (p *Packager) closeSegment() error {
+	if len(p.playlist.Pending) == 0 {
+		return nil
+	}
+	data, err := joinParts(p.parts)
+	if err != nil {
+		return err
+	}
+	sequence := p.playlist.MediaSequence + len(p.playlist.Segments)
+	uri := FormatFilename(p.SegmentFilename, p.Variant, sequence)
+	if err := writeFileAtomic(filepath.Join(p.Dir, uri), data); err != nil {
+		return err
+	}
+	duration := p.pendingDuration()
+	if rounded := duration.Round(time.Second); rounded > p.targetDuration() {
+		log.Printf("WARNING: Segment %s lasts %v, more than the target duration: are keyframes aligned with parts? Raising it\n",
+			uri, duration)
+		p.longestSegment = rounded
+	}
+	p.playlist.Segments = append(p.playlist.Segments, Segment{
+		Duration:      duration,
+		URI:           uri,
+		Parts:         p.playlist.Pending,
+		Discontinuity: p.discontinuity,
+	})
+	p.playlist.Pending, p.parts, p.discontinuity = nil, nil, false
+	if removed := p.playlist.Slide(p.ListSize); p.DeleteSegments {
+		p.removed = append(p.removed, removed...)
+		for len(p.removed) > deleteDelay {
+			if err := os.Remove(filepath.Join(p.Dir, p.removed[0].URI)); err != nil && !os.IsNotExist(err) {
+				log.Println("Cannot remove segment:", err)
+			}
+			p.removed = p.removed[1:]
+		}
+	}
+	return nil
+}
+
+// targetDuration Returns the TARGETDURATION: SegmentDuration, unless a segment lasted longer.
+// Players expect it not to change, but no segment may last longer.
+func (p *Packager) targetDuration() time.Duration {
+	if p.longestSegment > p.SegmentDuration {
+		return p.longestSegment
+	}
+	return p.SegmentDuration
+}
+
+// partTarget Returns the PART-TARGET: PartDuration, unless a part lasted longer
+func (p *Packager) partTarget() time.Duration {
+	if p.longestPart > p.PartDuration {
+		return p.longestPart
+	}
+	return p.PartDuration
+}
+
+func (p *Packager) write() error {
+	p.playlist.TargetDuration = p.targetDuration()
+	p.playlist.PartTarget = p.partTarget()
+	p.playlist.Type = p.Type
+	p.playlist.PreloadHint = FormatFilename(p.PartFilename, p.Variant, p.next)
+	return p.playlist.WriteFile(filepath.Join(p.Dir, p.Output))
+}
+
+// writeFileAtomic Writes `data` to a temporary file renamed to `filename`,
+// so that players never read a partial segment
+func writeFileAtomic(filename string, data []byte) error {
+	f, err := ioutil.TempFile(filepath.Dir(filename), "."+filepath.Base(filename))
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	return os.Rename(f.Name(), filename)
+}
@@ -0,0 +1,192 @@
+// Package llhls writes Low-Latency HLS media playlists, whose segments are announced part by part
+// while they are written: EXT-X-PART, EXT-X-PART-INF, EXT-X-PRELOAD-HINT and EXT-X-SERVER-CONTROL.
+// Playlists without parts are regular live or event media playlists.
+// See https://datatracker.ietf.org/doc/html/draft-pantos-hls-rfc8216bis#section-4.4.4.9
+package llhls
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultVersion The EXT-X-VERSION of the playlists, for EXT-X-MAP
+const DefaultVersion = 6
+
+// partsWindow Parts are only listed for the segments of the last `partsWindow` target durations
+const partsWindow = 3
+
+// Part A partial segment
+type Part struct {
+	Duration    time.Duration
+	URI         string
+	Independent bool // Starts with a sync sample: players can start playback with it
+}
+
+// Segment A media segment, and its parts
+type Segment struct {
+	Duration      time.Duration
+	URI           string
+	Parts         []Part
+	Discontinuity bool // Timestamps or encoding parameters change from the previous segment
+}
+
+// Playlist A low-latency media playlist, or a regular one if PartTarget is 0
+type Playlist struct {
+	Version               int           // DefaultVersion if 0
+	TargetDuration        time.Duration // Rounded up to the second
+	PartTarget            time.Duration
+	Type                  string // "EVENT", or "" for live playlists
+	MediaSequence         int
+	DiscontinuitySequence int    // Number of discontinuities removed from the playlist
+	MapURI                string // Initialization segment, "" if none
+	Segments              []Segment
+	Pending               []Part // Parts of the segment being written
+	PreloadHint           string // URI of the next part, "" if unknown
+	Ended                 bool
+}
+
+// FormatFilename Returns the filename of `template` where `%v` is replaced by `variant`,
+// and `%d` (or `%05d`...) by `number`
+func FormatFilename(template string, variant, number int) string {
+	return fmt.Sprintf(strings.Replace(template, "%v", strconv.Itoa(variant), -1), number)
+}
+
+// Slide Removes the oldest segments so that at most `listSize` are left, and returns them.
+// The media and discontinuity sequence numbers follow.
+func (p *Playlist) Slide(listSize int) []Segment {
+	if listSize <= 0 || len(p.Segments) <= listSize {
+		return nil
+	}
+	removed := p.Segments[:len(p.Segments)-listSize]
+	p.Segments = p.Segments[len(removed):]
+	p.MediaSequence += len(removed)
+	for _, s := range removed {
+		if s.Discontinuity {
+			p.DiscontinuitySequence++
+		}
+	}
+	return removed
+}
+
+// seconds Formats a duration in seconds for attributes and EXTINF
+func seconds(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'f', 5, 64)
+}
+
+func (p Part) String() string {
+	tag := "#EXT-X-PART:DURATION=" + seconds(p.Duration) + ",URI=\"" + p.URI + "\""
+	if p.Independent {
+		tag += ",INDEPENDENT=YES"
+	}
+	return tag
+}
+
+// Encode Writes the playlist to `w`. The parts of the segments older than three target durations are left out.
+// Returns an error if a segment lasts longer than the target duration, or a part longer than the part target.
+func (p *Playlist) Encode(w io.Writer) error {
+	version := p.Version
+	if version == 0 {
+		version = DefaultVersion
+	}
+	target := int(math.Ceil(p.TargetDuration.Seconds()))
+	if err := p.checkDurations(target); err != nil {
+		return err
+	}
+	b := bufio.NewWriter(w)
+	b.WriteString("#EXTM3U\n#EXT-X-VERSION:" + strconv.Itoa(version) + "\n")
+	b.WriteString("#EXT-X-TARGETDURATION:" + strconv.Itoa(target) + "\n")
+	if p.PartTarget > 0 {
+		// Players stay three parts behind the live edge, as recommended
+		b.WriteString("#EXT-X-SERVER-CONTROL:CAN-BLOCK-RELOAD=YES,PART-HOLD-BACK=" + seconds(3*p.PartTarget) + "\n")
+		b.WriteString("#EXT-X-PART-INF:PART-TARGET=" + seconds(p.PartTarget) + "\n")
+	}
+	b.WriteString("#EXT-X-MEDIA-SEQUENCE:" + strconv.Itoa(p.MediaSequence) + "\n")
+	if p.DiscontinuitySequence > 0 {
+		b.WriteString("#EXT-X-DISCONTINUITY-SEQUENCE:" + strconv.Itoa(p.DiscontinuitySequence) + "\n")
+	}
+	if len(p.Type) > 0 {
+		b.WriteString("#EXT-X-PLAYLIST-TYPE:" + p.Type + "\n")
+	}
+	if len(p.MapURI) > 0 {
+		b.WriteString("#EXT-X-MAP:URI=\"" + p.MapURI + "\"\n")
+	}
+
+	// The first segment whose parts are listed
+	var elapsed time.Duration
+	for _, part := range p.Pending {
+		elapsed += part.Duration
+	}
+	firstWithParts := len(p.Segments)
+	for firstWithParts > 0 && elapsed < partsWindow*time.Duration(target)*time.Second {
+		firstWithParts--
+		elapsed += p.Segments[firstWithParts].Duration
+	}
+	for i, s := range p.Segments {
+		if s.Discontinuity {
+			b.WriteString("#EXT-X-DISCONTINUITY\n")
+		}
+		if i >= firstWithParts {
+			for _, part := range s.Parts {
+				b.WriteString(part.String() + "\n")
+			}
+		}
+		b.WriteString("#EXTINF:" + seconds(s.Duration) + ",\n" + s.URI + "\n")
+	}
+	for _, part := range p.Pending {
+		b.WriteString(part.String() + "\n")
+	}
+	if p.Ended {
+		b.WriteString("#EXT-X-ENDLIST\n")
+	} else if len(p.PreloadHint) > 0 && p.PartTarget > 0 {
+		b.WriteString("#EXT-X-PRELOAD-HINT:TYPE=PART,URI=\"" + p.PreloadHint + "\"\n")
+	}
+	return b.Flush()
+}
+
+// checkDurations Returns an error if the rounded duration of a segment is longer than `target` seconds,
+// or if a part is longer than the part target
+func (p *Playlist) checkDurations(target int) error {
+	parts := append([]Part(nil), p.Pending...)
+	for _, s := range p.Segments {
+		if rounded := int(math.Round(s.Duration.Seconds())); rounded > target {
+			return fmt.Errorf("segment %s lasts %v, more than the target duration of %ds", s.URI, s.Duration, target)
+		}
+		parts = append(parts, s.Parts...)
+	}
+	if p.PartTarget == 0 {
+		return nil
+	}
+	for _, part := range parts {
+		if part.Duration > p.PartTarget {
+			return fmt.Errorf("part %s lasts %v, more than the part target of %v", part.URI, part.Duration, p.PartTarget)
+		}
+	}
+	return nil
+}
+
+// WriteFile Writes the playlist to `filename`. The file is replaced atomically,
+// so that players never read a partial playlist.
+func (p *Playlist) WriteFile(filename string) error {
+	f, err := ioutil.TempFile(filepath.Dir(filename), "."+filepath.Base(filename))
+	if err != nil {
+		return err
+	}
+	if err := p.Encode(f); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	return os.Rename(f.Name(), filename)
+}
@@ -1 +1,80 @@
-package go_hls_encoder
+// Command go-hls-encoder exposes the library from the command line,
+// to probe, suggest variants for, encode and post-process HLS packages.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+type command struct {
+	name        string
+	usage       string
+	description string
+	run         func(fs *flag.FlagSet, args []string) error
+}
+
+var commands = []command{
+	{"probe", "probe [-json] [-prober ffprobe|native] [-probe-cache dir] <input>...", "Print the probe data of the inputs", runProbe},
+	{"suggest", "suggest [-stereo] [-audio-languages fr,en] [-subtitle-languages fr,en] [-default-language fr] [-drop-dialects fr-CA] [-yaml] [-o plan.json|plan.yaml] <input>...", "Print the suggested encoding plan", runSuggest},
+	{"encode", "encode -o <dir> [-master name] [-stream name] [-timeout duration] [-playlist-type type [-list-size n] [-part-duration seconds]] [-dash] [-encrypt method [-key-rotation n] [-key-uri-prefix prefix]] (-plan <plan> | <input>...)", "Encode a plan, or the inputs, to HLS", runEncode},
+	{"iframe", "iframe -dir <dir> [-master name.m3u8] [-info name.m3u8]", "Enrich a master playlist and add I-FRAME-ONLY playlists", runIFrame},
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "Usage: %s <command> [arguments]\n\nCommands:\n", filepath.Base(os.Args[0]))
+	for _, c := range commands {
+		fmt.Fprintf(os.Stderr, "  %-10s%s\n  %-10s  %s %s\n", c.name, c.description, "", filepath.Base(os.Args[0]), c.usage)
+	}
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+	name := os.Args[1]
+	for _, c := range commands {
+		if c.name != name {
+			continue
+		}
+		if err := c.run(c.flagSet(), os.Args[2:]); err != nil {
+			if err == flag.ErrHelp {
+				os.Exit(2)
+			}
+			fmt.Fprintln(os.Stderr, "Error:", err)
+			os.Exit(1)
+		}
+		return
+	}
+	if name != "help" && name != "-h" && name != "-help" {
+		fmt.Fprintf(os.Stderr, "Unknown command %q\n\n", name)
+	}
+	usage()
+	os.Exit(2)
+}
+
+// flagSet Creates the flag set of the command, with its usage.
+func (c command) flagSet() *flag.FlagSet {
+	fs := flag.NewFlagSet(c.name, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "%s\n\nUsage: %s %s\n", c.description, filepath.Base(os.Args[0]), c.usage)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// parseInputs Parses the flags of `fs` and returns the positional arguments,
+// or an error if there are none.
+func parseInputs(fs *flag.FlagSet, args []string) ([]string, error) {
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return nil, flag.ErrHelp
+	}
+	return fs.Args(), nil
+}
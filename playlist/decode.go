@@ -0,0 +1,198 @@
+package playlist
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// ErrNotMaster Is returned when decoding a media playlist as a master playlist
+var ErrNotMaster = errors.New("not a master playlist")
+
+// DecodeMaster Reads a master playlist. Unknown tags are ignored.
+func DecodeMaster(r io.Reader) (*Master, error) {
+	m := &Master{}
+	scanner := bufio.NewScanner(r)
+	var pending *Variant // EXT-X-STREAM-INF waiting for its URI
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if lineNumber == 1 {
+			if line != "#EXTM3U" {
+				return nil, errors.New("missing #EXTM3U header")
+			}
+			continue
+		}
+		if len(line) == 0 {
+			continue
+		}
+		if !strings.HasPrefix(line, "#") {
+			if pending == nil {
+				return nil, fmt.Errorf("line %d: URI without EXT-X-STREAM-INF", lineNumber)
+			}
+			pending.URI = line
+			m.Variants = append(m.Variants, *pending)
+			pending = nil
+			continue
+		}
+		tag, value := line, ""
+		if i := strings.IndexByte(line, ':'); i >= 0 {
+			tag, value = line[:i], line[i+1:]
+		}
+		a, err := parseAttributes(value)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", lineNumber, err)
+		}
+		switch tag {
+		case "#EXT-X-VERSION":
+			m.Version, err = strconv.Atoi(value)
+		case "#EXT-X-INDEPENDENT-SEGMENTS":
+			m.IndependentSegments = true
+		case "#EXTINF", "#EXT-X-TARGETDURATION", "#EXT-X-MEDIA-SEQUENCE":
+			return nil, ErrNotMaster
+		case "#EXT-X-MEDIA":
+			m.Renditions = append(m.Renditions, Rendition{
+				Type:            MediaType(a["TYPE"]),
+				GroupID:         a["GROUP-ID"],
+				Name:            a["NAME"],
+				Language:        a["LANGUAGE"],
+				AssocLanguage:   a["ASSOC-LANGUAGE"],
+				Default:         a["DEFAULT"] == "YES",
+				Autoselect:      a["AUTOSELECT"] == "YES",
+				Forced:          a["FORCED"] == "YES",
+				InstreamID:      a["INSTREAM-ID"],
+				Characteristics: splitList(a["CHARACTERISTICS"]),
+				Channels:        a["CHANNELS"],
+				URI:             a["URI"],
+			})
+		case "#EXT-X-STREAM-INF":
+			pending = &Variant{
+				Codecs:         a["CODECS"],
+				Supplemental:   a["SUPPLEMENTAL-CODECS"],
+				Resolution:     a["RESOLUTION"],
+				HDCPLevel:      a["HDCP-LEVEL"],
+				VideoRange:     a["VIDEO-RANGE"],
+				Audio:          a["AUDIO"],
+				Video:          a["VIDEO"],
+				Subtitles:      a["SUBTITLES"],
+				ClosedCaptions: a["CLOSED-CAPTIONS"],
+			}
+			pending.Bandwidth, pending.AverageBandwidth, err = a.bandwidths()
+			if err == nil && len(a["FRAME-RATE"]) > 0 {
+				pending.FrameRate, err = strconv.ParseFloat(a["FRAME-RATE"], 64)
+			}
+		case "#EXT-X-I-FRAME-STREAM-INF":
+			v := IFrameVariant{
+				URI:          a["URI"],
+				Codecs:       a["CODECS"],
+				Supplemental: a["SUPPLEMENTAL-CODECS"],
+				Resolution:   a["RESOLUTION"],
+				HDCPLevel:    a["HDCP-LEVEL"],
+				VideoRange:   a["VIDEO-RANGE"],
+				Video:        a["VIDEO"],
+			}
+			v.Bandwidth, v.AverageBandwidth, err = a.bandwidths()
+			m.IFrameVariants = append(m.IFrameVariants, v)
+		case "#EXT-X-SESSION-DATA":
+			m.SessionData = append(m.SessionData, SessionData{
+				DataID:   a["DATA-ID"],
+				Value:    a["VALUE"],
+				URI:      a["URI"],
+				Language: a["LANGUAGE"],
+			})
+		case "#EXT-X-SESSION-KEY":
+			m.SessionKeys = append(m.SessionKeys, Key{
+				Method:            a["METHOD"],
+				URI:               a["URI"],
+				IV:                a["IV"],
+				KeyFormat:         a["KEYFORMAT"],
+				KeyFormatVersions: a["KEYFORMATVERSIONS"],
+			})
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", lineNumber, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if lineNumber == 0 {
+		return nil, errors.New("empty playlist")
+	}
+	if pending != nil {
+		return nil, errors.New("missing URI after the last EXT-X-STREAM-INF")
+	}
+	return m, nil
+}
+
+// ReadMaster Reads the master playlist at `filename`
+func ReadMaster(filename string) (*Master, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return DecodeMaster(f)
+}
+
+// attributeList The attributes of a tag, by name, without quotes
+type attributeList map[string]string
+
+// parseAttributes Parses an attribute list such as `BANDWIDTH=1000,CODECS="avc1.640028,mp4a.40.2"`
+func parseAttributes(value string) (attributeList, error) {
+	a := attributeList{}
+	for len(value) > 0 {
+		i := strings.IndexByte(value, '=')
+		if i < 0 {
+			// Not an attribute list, e.g. EXT-X-VERSION
+			return a, nil
+		}
+		name := value[:i]
+		value = value[i+1:]
+		var attributeValue string
+		if strings.HasPrefix(value, "\"") {
+			end := strings.IndexByte(value[1:], '"')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated quoted string for %s", name)
+			}
+			attributeValue = value[1 : end+1]
+			value = value[end+2:]
+		} else {
+			end := strings.IndexByte(value, ',')
+			if end < 0 {
+				end = len(value)
+			}
+			attributeValue = value[:end]
+			value = value[end:]
+		}
+		a[name] = attributeValue
+		value = strings.TrimPrefix(value, ",")
+	}
+	return a, nil
+}
+
+// bandwidths Returns the BANDWIDTH and AVERAGE-BANDWIDTH attributes
+func (a attributeList) bandwidths() (bandwidth, average int, err error) {
+	if bandwidth, err = strconv.Atoi(a["BANDWIDTH"]); err != nil {
+		return 0, 0, fmt.Errorf("invalid BANDWIDTH %q", a["BANDWIDTH"])
+	}
+	if len(a["AVERAGE-BANDWIDTH"]) > 0 {
+		if average, err = strconv.Atoi(a["AVERAGE-BANDWIDTH"]); err != nil {
+			return 0, 0, fmt.Errorf("invalid AVERAGE-BANDWIDTH %q", a["AVERAGE-BANDWIDTH"])
+		}
+	}
+	return bandwidth, average, nil
+}
+
+// splitList Splits a comma separated list, or returns `nil` if empty
+func splitList(value string) []string {
+	if len(value) == 0 {
+		return nil
+	}
+	return strings.Split(value, ",")
+}
@@ -0,0 +1,309 @@
+// Package playlist is a typed model of HLS master playlists, that renders deterministically.
+// See https://tools.ietf.org/html/rfc8216#section-4.3.4
+package playlist
+
+import (
+	"bufio"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// DefaultVersion The EXT-X-VERSION written when none is set
+const DefaultVersion = 7
+
+// Master A master playlist.
+// Renditions are written grouped by type, then variants, then I-frame streams,
+// each in the order of their slice.
+type Master struct {
+	Version             int // DefaultVersion if 0
+	IndependentSegments bool
+	SessionData         []SessionData
+	SessionKeys         []Key
+	Renditions          []Rendition
+	Variants            []Variant
+	IFrameVariants      []IFrameVariant
+}
+
+// MediaType The TYPE of a rendition
+type MediaType string
+
+const (
+	Audio          MediaType = "AUDIO"
+	Video          MediaType = "VIDEO"
+	Subtitles      MediaType = "SUBTITLES"
+	ClosedCaptions MediaType = "CLOSED-CAPTIONS"
+)
+
+// renditionTypes The order in which renditions are written
+var renditionTypes = []MediaType{Audio, Video, Subtitles, ClosedCaptions}
+
+// Rendition An EXT-X-MEDIA tag
+type Rendition struct {
+	Type            MediaType
+	GroupID         string
+	Name            string
+	Language        string
+	AssocLanguage   string
+	Default         bool
+	Autoselect      bool
+	Forced          bool // SUBTITLES only
+	InstreamID      string
+	Characteristics []string
+	Channels        string
+	URI             string // Required, except for CLOSED-CAPTIONS
+}
+
+// Variant An EXT-X-STREAM-INF tag and its URI
+type Variant struct {
+	URI              string
+	Bandwidth        int // Peak bitrate, in bits/s. Required
+	AverageBandwidth int
+	Codecs           string // RFC 6381 codecs, comma separated
+	Supplemental     string // SUPPLEMENTAL-CODECS, e.g. "dvh1.08.06/db1p"
+	Resolution       string // e.g. "1920x1080"
+	FrameRate        float64
+	HDCPLevel        string
+	VideoRange       string
+	Audio            string // Group IDs of the renditions
+	Video            string
+	Subtitles        string
+	ClosedCaptions   string // "NONE" is written as an enumerated string
+}
+
+// IFrameVariant An EXT-X-I-FRAME-STREAM-INF tag
+type IFrameVariant struct {
+	URI              string
+	Bandwidth        int
+	AverageBandwidth int
+	Codecs           string
+	Supplemental     string
+	Resolution       string
+	HDCPLevel        string
+	VideoRange       string
+	Video            string
+}
+
+// SessionData An EXT-X-SESSION-DATA tag, with either a Value or a URI
+type SessionData struct {
+	DataID   string
+	Value    string
+	URI      string
+	Language string
+}
+
+// Key An EXT-X-SESSION-KEY tag, or the EXT-X-KEY tag of a media playlist
+type Key struct {
+	Method            string // "AES-128", "SAMPLE-AES" or "SAMPLE-AES-CTR"
+	URI               string
+	IV                string // Hexadecimal, with its 0x prefix
+	KeyFormat         string
+	KeyFormatVersions string
+}
+
+// attributes Builds an attribute list
+type attributes []string
+
+func (a *attributes) enum(name, value string) {
+	if len(value) > 0 {
+		*a = append(*a, name+"="+value)
+	}
+}
+
+func (a *attributes) quoted(name, value string) {
+	if len(value) > 0 {
+		*a = append(*a, name+"=\""+value+"\"")
+	}
+}
+
+func (a *attributes) integer(name string, value int) {
+	if value > 0 {
+		*a = append(*a, name+"="+strconv.Itoa(value))
+	}
+}
+
+func (a *attributes) yes(name string, value bool) {
+	if value {
+		*a = append(*a, name+"=YES")
+	}
+}
+
+func (a attributes) String() string {
+	return strings.Join(a, ",")
+}
+
+// String Returns the EXT-X-MEDIA tag
+func (r Rendition) String() string {
+	var a attributes
+	a.enum("TYPE", string(r.Type))
+	a.quoted("GROUP-ID", r.GroupID)
+	a.quoted("NAME", r.Name)
+	a.quoted("LANGUAGE", r.Language)
+	a.quoted("ASSOC-LANGUAGE", r.AssocLanguage)
+	a.yes("DEFAULT", r.Default)
+	a.yes("AUTOSELECT", r.Autoselect)
+	if r.Type == Subtitles {
+		forced := "NO"
+		if r.Forced {
+			forced = "YES"
+		}
+		a.enum("FORCED", forced)
+	}
+	a.quoted("INSTREAM-ID", r.InstreamID)
+	a.quoted("CHARACTERISTICS", strings.Join(r.Characteristics, ","))
+	a.quoted("CHANNELS", r.Channels)
+	a.quoted("URI", r.URI)
+	return "#EXT-X-MEDIA:" + a.String()
+}
+
+// String Returns the EXT-X-STREAM-INF tag, followed by the URI line
+func (v Variant) String() string {
+	var a attributes
+	a.integer("BANDWIDTH", v.Bandwidth)
+	a.integer("AVERAGE-BANDWIDTH", v.AverageBandwidth)
+	a.enum("RESOLUTION", v.Resolution)
+	if v.FrameRate > 0 {
+		a.enum("FRAME-RATE", strconv.FormatFloat(v.FrameRate, 'f', 3, 64))
+	}
+	a.enum("VIDEO-RANGE", v.VideoRange)
+	a.enum("HDCP-LEVEL", v.HDCPLevel)
+	a.quoted("CODECS", v.Codecs)
+	a.quoted("SUPPLEMENTAL-CODECS", v.Supplemental)
+	a.quoted("AUDIO", v.Audio)
+	a.quoted("VIDEO", v.Video)
+	a.quoted("SUBTITLES", v.Subtitles)
+	if v.ClosedCaptions == "NONE" {
+		a.enum("CLOSED-CAPTIONS", v.ClosedCaptions)
+	} else {
+		a.quoted("CLOSED-CAPTIONS", v.ClosedCaptions)
+	}
+	return "#EXT-X-STREAM-INF:" + a.String() + "\n" + v.URI
+}
+
+// String Returns the EXT-X-I-FRAME-STREAM-INF tag
+func (v IFrameVariant) String() string {
+	var a attributes
+	a.integer("BANDWIDTH", v.Bandwidth)
+	a.integer("AVERAGE-BANDWIDTH", v.AverageBandwidth)
+	a.enum("RESOLUTION", v.Resolution)
+	a.enum("VIDEO-RANGE", v.VideoRange)
+	a.enum("HDCP-LEVEL", v.HDCPLevel)
+	a.quoted("CODECS", v.Codecs)
+	a.quoted("SUPPLEMENTAL-CODECS", v.Supplemental)
+	a.quoted("VIDEO", v.Video)
+	a.quoted("URI", v.URI)
+	return "#EXT-X-I-FRAME-STREAM-INF:" + a.String()
+}
+
+// String Returns the EXT-X-SESSION-DATA tag
+func (d SessionData) String() string {
+	var a attributes
+	a.quoted("DATA-ID", d.DataID)
+	a.quoted("VALUE", d.Value)
+	a.quoted("URI", d.URI)
+	a.quoted("LANGUAGE", d.Language)
+	return "#EXT-X-SESSION-DATA:" + a.String()
+}
+
+// attributes Returns the attributes of the key, shared by EXT-X-KEY and EXT-X-SESSION-KEY
+func (k Key) attributes() string {
+	var a attributes
+	a.enum("METHOD", k.Method)
+	a.quoted("URI", k.URI)
+	a.enum("IV", k.IV)
+	a.quoted("KEYFORMAT", k.KeyFormat)
+	a.quoted("KEYFORMATVERSIONS", k.KeyFormatVersions)
+	return a.String()
+}
+
+// String Returns the EXT-X-SESSION-KEY tag
+func (k Key) String() string {
+	return "#EXT-X-SESSION-KEY:" + k.attributes()
+}
+
+// KeyTag Returns the EXT-X-KEY tag of media playlists with the same attributes
+func (k Key) KeyTag() string {
+	return "#EXT-X-KEY:" + k.attributes()
+}
+
+// Encode Writes the playlist to `w`
+func (m *Master) Encode(w io.Writer) error {
+	b := bufio.NewWriter(w)
+	version := m.Version
+	if version == 0 {
+		version = DefaultVersion
+	}
+	b.WriteString("#EXTM3U\n#EXT-X-VERSION:" + strconv.Itoa(version) + "\n")
+	if m.IndependentSegments {
+		b.WriteString("#EXT-X-INDEPENDENT-SEGMENTS\n")
+	}
+	section := func(lines []string) {
+		if len(lines) == 0 {
+			return
+		}
+		b.WriteString("\n")
+		for _, line := range lines {
+			b.WriteString(line + "\n")
+		}
+	}
+	var lines []string
+	for _, d := range m.SessionData {
+		lines = append(lines, d.String())
+	}
+	for _, k := range m.SessionKeys {
+		lines = append(lines, k.String())
+	}
+	section(lines)
+	for _, t := range renditionTypes {
+		lines = nil
+		for _, r := range m.Renditions {
+			if r.Type == t {
+				lines = append(lines, r.String())
+			}
+		}
+		section(lines)
+	}
+	lines = nil
+	for _, v := range m.Variants {
+		lines = append(lines, v.String())
+	}
+	section(lines)
+	lines = nil
+	for _, v := range m.IFrameVariants {
+		lines = append(lines, v.String())
+	}
+	section(lines)
+	return b.Flush()
+}
+
+// WriteFile Writes the playlist to `filename`. The file is replaced atomically,
+// so that players never read a partial playlist.
+func (m *Master) WriteFile(filename string) error {
+	f, err := ioutil.TempFile(filepath.Dir(filename), "."+filepath.Base(filename))
+	if err != nil {
+		return err
+	}
+	if err := m.Encode(f); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	return os.Rename(f.Name(), filename)
+}
+
+// Variant Returns the variant with the given URI, or `nil`
+func (m *Master) Variant(uri string) *Variant {
+	for i := range m.Variants {
+		if m.Variants[i].URI == uri {
+			return &m.Variants[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package playlist
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+const expectedMaster = `#EXTM3U
+#EXT-X-VERSION:7
+#EXT-X-INDEPENDENT-SEGMENTS
+
+#EXT-X-SESSION-DATA:DATA-ID="com.example.title",VALUE="Movie",LANGUAGE="en"
+
+#EXT-X-MEDIA:TYPE=AUDIO,GROUP-ID="audio",NAME="English",LANGUAGE="en",DEFAULT=YES,AUTOSELECT=YES,CHANNELS="2",URI="stream_2.m3u8"
+
+#EXT-X-MEDIA:TYPE=SUBTITLES,GROUP-ID="subtitles",NAME="English (SDH)",LANGUAGE="en",AUTOSELECT=YES,FORCED=NO,CHARACTERISTICS="public.accessibility.transcribes-spoken-dialog,public.accessibility.describes-music-and-sound",URI="sdh.m3u8"
+
+#EXT-X-STREAM-INF:BANDWIDTH=5400000,AVERAGE-BANDWIDTH=4000000,RESOLUTION=1920x1080,FRAME-RATE=23.976,VIDEO-RANGE=SDR,CODECS="avc1.640028,mp4a.40.2",AUDIO="audio",SUBTITLES="subtitles"
+stream_0.m3u8
+#EXT-X-STREAM-INF:BANDWIDTH=1100000,RESOLUTION=960x540,CODECS="avc1.4d401f,mp4a.40.2",AUDIO="audio",SUBTITLES="subtitles"
+stream_1.m3u8
+
+#EXT-X-I-FRAME-STREAM-INF:BANDWIDTH=540000,RESOLUTION=1920x1080,CODECS="avc1.640028",URI="stream_0_iframe.m3u8"
+`
+
+func testMaster() *Master {
+	return &Master{
+		IndependentSegments: true,
+		SessionData:         []SessionData{{DataID: "com.example.title", Value: "Movie", Language: "en"}},
+		Renditions: []Rendition{{
+			Type: Subtitles, GroupID: "subtitles", Name: "English (SDH)", Language: "en", Autoselect: true,
+			Characteristics: []string{"public.accessibility.transcribes-spoken-dialog", "public.accessibility.describes-music-and-sound"},
+			URI:             "sdh.m3u8",
+		}, {
+			Type: Audio, GroupID: "audio", Name: "English", Language: "en", Default: true, Autoselect: true,
+			Channels: "2", URI: "stream_2.m3u8",
+		}},
+		Variants: []Variant{{
+			URI: "stream_0.m3u8", Bandwidth: 5400000, AverageBandwidth: 4000000, Resolution: "1920x1080",
+			FrameRate: 23.976, VideoRange: "SDR", Codecs: "avc1.640028,mp4a.40.2", Audio: "audio", Subtitles: "subtitles",
+		}, {
+			URI: "stream_1.m3u8", Bandwidth: 1100000, Resolution: "960x540",
+			Codecs: "avc1.4d401f,mp4a.40.2", Audio: "audio", Subtitles: "subtitles",
+		}},
+		IFrameVariants: []IFrameVariant{{
+			URI: "stream_0_iframe.m3u8", Bandwidth: 540000, Resolution: "1920x1080", Codecs: "avc1.640028",
+		}},
+	}
+}
+
+func TestEncodeMaster(t *testing.T) {
+	var buffer bytes.Buffer
+	if err := testMaster().Encode(&buffer); err != nil {
+		t.Fatal("Cannot encode master playlist:", err)
+	}
+	if buffer.String() != expectedMaster {
+		t.Errorf("Unexpected master playlist:\n%s", buffer.String())
+	}
+}
+
+func TestDecodeMaster(t *testing.T) {
+	m, err := DecodeMaster(bytes.NewBufferString(expectedMaster))
+	if err != nil {
+		t.Fatal("Cannot decode master playlist:", err)
+	}
+	expected := testMaster()
+	expected.Version = DefaultVersion
+	// Renditions are written grouped by type
+	expected.Renditions[0], expected.Renditions[1] = expected.Renditions[1], expected.Renditions[0]
+	if !reflect.DeepEqual(m, expected) {
+		t.Errorf("Unexpected decoded playlist:\n%+v\ninstead of\n%+v", m, expected)
+	}
+	if _, err := DecodeMaster(bytes.NewBufferString("#EXTM3U\n#EXT-X-TARGETDURATION:6\n")); err != ErrNotMaster {
+		t.Error("Media playlist not reported:", err)
+	}
+}
@@ -0,0 +1,703 @@
+package probe
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// errTruncated Is returned when a header is shorter than its syntax
+var errTruncated = errors.New("truncated header")
+
+// bitReader Reads big-endian bit fields. Reading past the end sets `err`
+type bitReader struct {
+	data []byte
+	pos  int // In bits
+	err  error
+}
+
+// u Reads an unsigned integer of `n` bits, at most 64
+func (r *bitReader) u(n int) uint64 {
+	var v uint64
+	for i := 0; i < n; i++ {
+		if r.pos >= 8*len(r.data) {
+			r.err = errTruncated
+			return 0
+		}
+		bit := (r.data[r.pos/8] >> (7 - uint(r.pos%8))) & 1
+		v = v<<1 | uint64(bit)
+		r.pos++
+	}
+	return v
+}
+
+// flag Reads a single bit
+func (r *bitReader) flag() bool {
+	return r.u(1) == 1
+}
+
+// skip Skips `n` bits
+func (r *bitReader) skip(n int) {
+	r.pos += n
+	if r.pos > 8*len(r.data) {
+		r.err = errTruncated
+	}
+}
+
+// ue Reads an unsigned Exp-Golomb code
+func (r *bitReader) ue() uint64 {
+	zeros := 0
+	for !r.flag() {
+		if r.err != nil || zeros > 32 {
+			r.err = errTruncated
+			return 0
+		}
+		zeros++
+	}
+	return (1 << uint(zeros)) - 1 + r.u(zeros)
+}
+
+// se Reads a signed Exp-Golomb code
+func (r *bitReader) se() int64 {
+	v := r.ue()
+	if v%2 == 1 {
+		return int64(v+1) / 2
+	}
+	return -int64(v / 2)
+}
+
+// unescapeRBSP Removes the emulation prevention bytes of a NAL unit
+func unescapeRBSP(nal []byte) []byte {
+	rbsp := make([]byte, 0, len(nal))
+	zeros := 0
+	for _, b := range nal {
+		if zeros >= 2 && b == 3 {
+			zeros = 0
+			continue
+		}
+		if b == 0 {
+			zeros++
+		} else {
+			zeros = 0
+		}
+		rbsp = append(rbsp, b)
+	}
+	return rbsp
+}
+
+// videoInfo What the parameter sets of a video stream tell about it
+type videoInfo struct {
+	codec          string
+	profile        string
+	level          int
+	width, height  int
+	chromaFormat   int // 0 for monochrome, 1 for 4:2:0, 2 for 4:2:2, 3 for 4:4:4
+	bitDepth       int
+	sarNum, sarDen int
+	frameRate      string // Rational. Empty if unknown
+	colorRange     string
+	colorPrimaries string
+	colorTransfer  string
+	colorSpace     string
+}
+
+// apply Sets the fields of `stream` known from the parameter sets
+func (v *videoInfo) apply(stream *ProbeStream) {
+	stream.CodecName = v.codec
+	stream.Profile = v.profile
+	stream.Level = v.level
+	if v.width > 0 && v.height > 0 {
+		stream.Width, stream.Height = v.width, v.height
+	}
+	stream.PixFmt = pixFmt(v.chromaFormat, v.bitDepth)
+	if v.bitDepth > 0 {
+		stream.BitsPerRawSample = fmt.Sprint(v.bitDepth)
+	}
+	if v.sarNum > 0 && v.sarDen > 0 && stream.Width > 0 && stream.Height > 0 {
+		stream.SampleAspectRatio = ratio(v.sarNum, v.sarDen)
+		stream.DisplayAspectRatio = ratio(stream.Width*v.sarNum, stream.Height*v.sarDen)
+	}
+	if len(v.frameRate) > 0 && len(stream.AvgFrameRate) == 0 {
+		stream.AvgFrameRate = v.frameRate
+		stream.RFrameRate = v.frameRate
+	}
+	setString(&stream.ColorRange, v.colorRange)
+	setString(&stream.ColorPrimaries, v.colorPrimaries)
+	setString(&stream.ColorTransfer, v.colorTransfer)
+	setString(&stream.ColorSpace, v.colorSpace)
+}
+
+// setString Sets `dst` to `value` if not empty
+func setString(dst *string, value string) {
+	if len(value) > 0 {
+		*dst = value
+	}
+}
+
+// pixFmt Returns the name of the pixel format, as written by ffprobe
+func pixFmt(chromaFormat, bitDepth int) string {
+	var name string
+	switch chromaFormat {
+	case 0:
+		name = "gray"
+	case 2:
+		name = "yuv422p"
+	case 3:
+		name = "yuv444p"
+	default:
+		name = "yuv420p"
+	}
+	if bitDepth > 8 {
+		name += fmt.Sprintf("%dle", bitDepth)
+	}
+	return name
+}
+
+// Names of the colour description code points of ITU-T H.273, as written by ffprobe
+var (
+	colorPrimariesNames = map[uint64]string{1: "bt709", 4: "bt470m", 5: "bt470bg", 6: "smpte170m", 7: "smpte240m",
+		8: "film", 9: "bt2020", 10: "smpte428", 11: "smpte431", 12: "smpte432", 22: "jedec-p22"}
+	colorTransferNames = map[uint64]string{1: "bt709", 4: "gamma22", 5: "gamma28", 6: "smpte170m", 7: "smpte240m",
+		8: "linear", 9: "log100", 10: "log316", 11: "iec61966-2-4", 12: "bt1361e", 13: "iec61966-2-1",
+		14: "bt2020-10", 15: "bt2020-12", 16: "smpte2084", 17: "smpte428", 18: "arib-std-b67"}
+	colorSpaceNames = map[uint64]string{0: "gbr", 1: "bt709", 4: "fcc", 5: "bt470bg", 6: "smpte170m", 7: "smpte240m",
+		8: "ycgco", 9: "bt2020nc", 10: "bt2020c", 11: "smpte2085", 14: "ictcp"}
+)
+
+// setColor Sets the colour description of `v` from H.273 code points
+func (v *videoInfo) setColor(primaries, transfer, matrix uint64, fullRange bool) {
+	v.colorPrimaries = colorPrimariesNames[primaries]
+	v.colorTransfer = colorTransferNames[transfer]
+	v.colorSpace = colorSpaceNames[matrix]
+	if fullRange {
+		v.colorRange = "pc"
+	} else {
+		v.colorRange = "tv"
+	}
+}
+
+// avcProfileName Returns the name of an H.264 profile, as written by ffprobe
+func avcProfileName(profileIDC, constraintFlags int) string {
+	switch profileIDC {
+	case 66:
+		if constraintFlags&0x40 != 0 {
+			return "Constrained Baseline"
+		}
+		return "Baseline"
+	case 77:
+		return "Main"
+	case 88:
+		return "Extended"
+	case 100:
+		return "High"
+	case 110:
+		return "High 10"
+	case 122:
+		return "High 4:2:2"
+	case 244:
+		return "High 4:4:4 Predictive"
+	case 44:
+		return "CAVLC 4:4:4"
+	}
+	return ""
+}
+
+// parseAVCSPS Parses an H.264 sequence parameter set NAL unit, header included
+func parseAVCSPS(nal []byte) (*videoInfo, error) {
+	if len(nal) < 4 {
+		return nil, errTruncated
+	}
+	r := &bitReader{data: unescapeRBSP(nal[1:])}
+	profileIDC := int(r.u(8))
+	constraintFlags := int(r.u(8))
+	v := &videoInfo{
+		codec:        "h264",
+		profile:      avcProfileName(profileIDC, constraintFlags),
+		level:        int(r.u(8)),
+		chromaFormat: 1,
+		bitDepth:     8,
+	}
+	r.ue() // seq_parameter_set_id
+	switch profileIDC {
+	case 100, 110, 122, 244, 44, 83, 86, 118, 128, 138, 139, 134, 135:
+		v.chromaFormat = int(r.ue())
+		if v.chromaFormat == 3 {
+			r.skip(1) // separate_colour_plane_flag
+		}
+		v.bitDepth = 8 + int(r.ue())
+		r.ue()        // bit_depth_chroma_minus8
+		r.skip(1)     // qpprime_y_zero_transform_bypass_flag
+		if r.flag() { // seq_scaling_matrix_present_flag
+			lists := 8
+			if v.chromaFormat == 3 {
+				lists = 12
+			}
+			for i := 0; i < lists; i++ {
+				if !r.flag() {
+					continue
+				}
+				size := 16
+				if i >= 6 {
+					size = 64
+				}
+				last, next := int64(8), int64(8)
+				for j := 0; j < size && r.err == nil; j++ {
+					if next != 0 {
+						next = (last + r.se() + 256) % 256
+					}
+					if next != 0 {
+						last = next
+					}
+				}
+			}
+		}
+	}
+	r.ue()          // log2_max_frame_num_minus4
+	switch r.ue() { // pic_order_cnt_type
+	case 0:
+		r.ue() // log2_max_pic_order_cnt_lsb_minus4
+	case 1:
+		r.skip(1) // delta_pic_order_always_zero_flag
+		r.se()    // offset_for_non_ref_pic
+		r.se()    // offset_for_top_to_bottom_field
+		cycle := r.ue()
+		for i := uint64(0); i < cycle && r.err == nil; i++ {
+			r.se()
+		}
+	}
+	r.ue()    // max_num_ref_frames
+	r.skip(1) // gaps_in_frame_num_value_allowed_flag
+	widthInMbs := int(r.ue()) + 1
+	heightInMapUnits := int(r.ue()) + 1
+	frameMbsOnly := r.flag()
+	if !frameMbsOnly {
+		r.skip(1) // mb_adaptive_frame_field_flag
+	}
+	r.skip(1) // direct_8x8_inference_flag
+	fieldFactor := 1
+	if !frameMbsOnly {
+		fieldFactor = 2
+	}
+	v.width = widthInMbs * 16
+	v.height = fieldFactor * heightInMapUnits * 16
+	if r.flag() { // frame_cropping_flag
+		cropX, cropY := 1, fieldFactor
+		switch v.chromaFormat {
+		case 1:
+			cropX, cropY = 2, 2*fieldFactor
+		case 2:
+			cropX = 2
+		}
+		left, right, top, bottom := int(r.ue()), int(r.ue()), int(r.ue()), int(r.ue())
+		v.width -= cropX * (left + right)
+		v.height -= cropY * (top + bottom)
+	}
+	if r.flag() { // vui_parameters_present_flag
+		parseAVCVUI(r, v)
+	}
+	return v, r.err
+}
+
+// sarTable The sample aspect ratios of aspect_ratio_idc, shared by H.264 and HEVC
+var sarTable = [][2]int{{0, 0}, {1, 1}, {12, 11}, {10, 11}, {16, 11}, {40, 33}, {24, 11}, {20, 11},
+	{32, 11}, {80, 33}, {18, 11}, {15, 11}, {64, 33}, {160, 99}, {4, 3}, {3, 2}, {2, 1}}
+
+// parseAVCVUI Parses the beginning of the VUI parameters of an H.264 SPS,
+// up to the timing information.
+func parseAVCVUI(r *bitReader, v *videoInfo) {
+	if r.flag() { // aspect_ratio_info_present_flag
+		idc := int(r.u(8))
+		if idc == 255 {
+			v.sarNum, v.sarDen = int(r.u(16)), int(r.u(16))
+		} else if idc < len(sarTable) {
+			v.sarNum, v.sarDen = sarTable[idc][0], sarTable[idc][1]
+		}
+	}
+	if r.flag() { // overscan_info_present_flag
+		r.skip(1)
+	}
+	if r.flag() { // video_signal_type_present_flag
+		r.skip(3) // video_format
+		fullRange := r.flag()
+		if r.flag() { // colour_description_present_flag
+			v.setColor(r.u(8), r.u(8), r.u(8), fullRange)
+		} else if fullRange {
+			v.colorRange = "pc"
+		}
+	}
+	if r.flag() { // chroma_loc_info_present_flag
+		r.ue()
+		r.ue()
+	}
+	if r.flag() { // timing_info_present_flag
+		numUnitsInTick := int(r.u(32))
+		timeScale := int(r.u(32))
+		if numUnitsInTick > 0 && timeScale > 0 && r.err == nil {
+			v.frameRate = ratio(timeScale, 2*numUnitsInTick)
+		}
+	}
+}
+
+// hevcProfileName Returns the name of an HEVC profile, as written by ffprobe
+func hevcProfileName(profileIDC int) string {
+	switch profileIDC {
+	case 1:
+		return "Main"
+	case 2:
+		return "Main 10"
+	case 3:
+		return "Main Still Picture"
+	case 4:
+		return "Rext"
+	}
+	return ""
+}
+
+// parseHEVCSPS Parses an HEVC sequence parameter set NAL unit, header included,
+// up to the bit depth.
+func parseHEVCSPS(nal []byte) (*videoInfo, error) {
+	if len(nal) < 3 {
+		return nil, errTruncated
+	}
+	r := &bitReader{data: unescapeRBSP(nal[2:])}
+	r.skip(4) // sps_video_parameter_set_id
+	maxSubLayers := int(r.u(3))
+	r.skip(1) // sps_temporal_id_nesting_flag
+	// profile_tier_level
+	r.skip(3) // general_profile_space, general_tier_flag
+	v := &videoInfo{codec: "hevc", profile: hevcProfileName(int(r.u(5)))}
+	r.skip(32 + 48) // general_profile_compatibility_flags, constraint flags
+	v.level = int(r.u(8))
+	subLayerProfile := make([]bool, maxSubLayers)
+	subLayerLevel := make([]bool, maxSubLayers)
+	for i := 0; i < maxSubLayers; i++ {
+		subLayerProfile[i] = r.flag()
+		subLayerLevel[i] = r.flag()
+	}
+	if maxSubLayers > 0 {
+		r.skip(2 * (8 - maxSubLayers))
+	}
+	for i := 0; i < maxSubLayers; i++ {
+		if subLayerProfile[i] {
+			r.skip(88)
+		}
+		if subLayerLevel[i] {
+			r.skip(8)
+		}
+	}
+	r.ue() // sps_seq_parameter_set_id
+	v.chromaFormat = int(r.ue())
+	if v.chromaFormat == 3 {
+		r.skip(1) // separate_colour_plane_flag
+	}
+	v.width = int(r.ue())
+	v.height = int(r.ue())
+	if r.flag() { // conformance_window_flag
+		cropX, cropY := 1, 1
+		switch v.chromaFormat {
+		case 1:
+			cropX, cropY = 2, 2
+		case 2:
+			cropX = 2
+		}
+		left, right, top, bottom := int(r.ue()), int(r.ue()), int(r.ue()), int(r.ue())
+		v.width -= cropX * (left + right)
+		v.height -= cropY * (top + bottom)
+	}
+	v.bitDepth = 8 + int(r.ue())
+	return v, r.err
+}
+
+// parseAVCC Parses an AVCDecoderConfigurationRecord, as found in MP4 `avcC` boxes
+// and Matroska codec private data.
+func parseAVCC(data []byte) (*videoInfo, error) {
+	if len(data) < 7 || data[0] != 1 {
+		return nil, errors.New("invalid avcC")
+	}
+	if data[5]&0x1f > 0 && len(data) >= 8 {
+		length := int(data[6])<<8 | int(data[7])
+		if 8+length <= len(data) {
+			return parseAVCSPS(data[8 : 8+length])
+		}
+	}
+	// No SPS: only the profile and level are known
+	return &videoInfo{codec: "h264", profile: avcProfileName(int(data[1]), int(data[2])),
+		level: int(data[3]), chromaFormat: 1, bitDepth: 8}, nil
+}
+
+// parseHVCC Parses an HEVCDecoderConfigurationRecord, as found in MP4 `hvcC` boxes
+// and Matroska codec private data.
+func parseHVCC(data []byte) (*videoInfo, error) {
+	if len(data) < 23 {
+		return nil, errors.New("invalid hvcC")
+	}
+	v := &videoInfo{
+		codec:        "hevc",
+		profile:      hevcProfileName(int(data[1] & 0x1f)),
+		level:        int(data[12]),
+		chromaFormat: int(data[16] & 0x03),
+		bitDepth:     8 + int(data[17]&0x07),
+	}
+	// Look for the SPS for the dimensions
+	pos := 23
+	for arrays := int(data[22]); arrays > 0 && pos+3 <= len(data); arrays-- {
+		nalType := data[pos] & 0x3f
+		count := int(data[pos+1])<<8 | int(data[pos+2])
+		pos += 3
+		for ; count > 0 && pos+2 <= len(data); count-- {
+			length := int(data[pos])<<8 | int(data[pos+1])
+			pos += 2
+			if pos+length > len(data) {
+				return v, nil
+			}
+			if nalType == 33 {
+				if sps, err := parseHEVCSPS(data[pos : pos+length]); err == nil {
+					v.width, v.height = sps.width, sps.height
+				}
+			}
+			pos += length
+		}
+	}
+	return v, nil
+}
+
+// parseAV1C Parses an AV1CodecConfigurationRecord, as found in MP4 `av1C` boxes
+// and Matroska codec private data.
+func parseAV1C(data []byte) (*videoInfo, error) {
+	if len(data) < 4 || data[0]&0x80 == 0 {
+		return nil, errors.New("invalid av1C")
+	}
+	r := &bitReader{data: data[1:]}
+	profile := r.u(3)
+	v := &videoInfo{codec: "av1", level: int(r.u(5)), bitDepth: 8}
+	v.profile = [...]string{"Main", "High", "Professional", "", "", "", "", ""}[profile]
+	r.skip(1)     // seq_tier_0
+	if r.flag() { // high_bitdepth
+		v.bitDepth = 10
+		if r.flag() { // twelve_bit
+			v.bitDepth = 12
+		}
+	} else {
+		r.skip(1)
+	}
+	monochrome := r.flag()
+	subsamplingX, subsamplingY := r.flag(), r.flag()
+	switch {
+	case monochrome:
+		v.chromaFormat = 0
+	case subsamplingX && subsamplingY:
+		v.chromaFormat = 1
+	case subsamplingX:
+		v.chromaFormat = 2
+	default:
+		v.chromaFormat = 3
+	}
+	return v, r.err
+}
+
+// audioInfo What the headers of an audio stream tell about it
+type audioInfo struct {
+	codec      string
+	profile    string
+	sampleRate int
+	channels   int
+}
+
+// apply Sets the fields of `stream` known from the headers
+func (a *audioInfo) apply(stream *ProbeStream) {
+	stream.CodecName = a.codec
+	setString(&stream.Profile, a.profile)
+	if a.sampleRate > 0 {
+		stream.SampleRate = fmt.Sprint(a.sampleRate)
+	}
+	if a.channels > 0 {
+		stream.Channels = a.channels
+		stream.ChannelLayout = channelLayout(a.channels)
+	}
+}
+
+// channelLayout Returns the usual layout of `channels` channels, as written by ffprobe
+func channelLayout(channels int) string {
+	switch channels {
+	case 1:
+		return "mono"
+	case 2:
+		return "stereo"
+	case 3:
+		return "2.1"
+	case 4:
+		return "quad"
+	case 5:
+		return "5.0"
+	case 6:
+		return "5.1"
+	case 7:
+		return "6.1"
+	case 8:
+		return "7.1"
+	}
+	return ""
+}
+
+// aacSampleRates The sampling frequencies of samplingFrequencyIndex
+var aacSampleRates = []int{96000, 88200, 64000, 48000, 44100, 32000, 24000, 22050, 16000, 12000, 11025, 8000, 7350}
+
+// aacProfileName Returns the name of an MPEG-4 audio object type, as written by ffprobe
+func aacProfileName(objectType int) string {
+	switch objectType {
+	case 1:
+		return "Main"
+	case 2:
+		return "LC"
+	case 3:
+		return "SSR"
+	case 4:
+		return "LTP"
+	case 5:
+		return "HE-AAC"
+	case 29:
+		return "HE-AACv2"
+	}
+	return ""
+}
+
+// parseAudioSpecificConfig Parses the AudioSpecificConfig of an AAC stream
+func parseAudioSpecificConfig(data []byte) (*audioInfo, error) {
+	r := &bitReader{data: data}
+	readObjectType := func() int {
+		objectType := int(r.u(5))
+		if objectType == 31 {
+			objectType = 32 + int(r.u(6))
+		}
+		return objectType
+	}
+	readSampleRate := func() int {
+		index := int(r.u(4))
+		if index == 15 {
+			return int(r.u(24))
+		}
+		if index < len(aacSampleRates) {
+			return aacSampleRates[index]
+		}
+		return 0
+	}
+	objectType := readObjectType()
+	a := &audioInfo{codec: "aac", sampleRate: readSampleRate()}
+	channelConfiguration := int(r.u(4))
+	if objectType == 5 || objectType == 29 {
+		// Explicit SBR signaling: the output sample rate follows
+		a.sampleRate = readSampleRate()
+	}
+	a.profile = aacProfileName(objectType)
+	a.channels = channelConfiguration
+	if channelConfiguration == 7 {
+		a.channels = 8
+	}
+	return a, r.err
+}
+
+// parseADTS Parses the header of an ADTS frame
+func parseADTS(data []byte) (*audioInfo, error) {
+	if len(data) < 7 || data[0] != 0xff || data[1]&0xf0 != 0xf0 {
+		return nil, errors.New("invalid ADTS header")
+	}
+	objectType := int(data[2]>>6) + 1
+	a := &audioInfo{codec: "aac", profile: aacProfileName(objectType)}
+	if index := int(data[2]>>2) & 0x0f; index < len(aacSampleRates) {
+		a.sampleRate = aacSampleRates[index]
+	}
+	a.channels = int(data[2]&1)<<2 | int(data[3]>>6)
+	if a.channels == 7 {
+		a.channels = 8
+	}
+	return a, nil
+}
+
+// ac3SampleRates The sampling frequencies of fscod
+var ac3SampleRates = []int{48000, 44100, 32000}
+
+// ac3Channels The number of full-bandwidth channels of acmod
+var ac3Channels = []int{2, 1, 2, 3, 3, 4, 4, 5}
+
+// parseAC3 Parses the header of an AC-3 or E-AC-3 sync frame
+func parseAC3(data []byte) (*audioInfo, error) {
+	if len(data) < 8 || data[0] != 0x0b || data[1] != 0x77 {
+		return nil, errors.New("invalid AC-3 header")
+	}
+	if bsid := data[5] >> 3; bsid > 10 {
+		// E-AC-3
+		r := &bitReader{data: data[2:]}
+		r.skip(2 + 3 + 11) // strmtyp, substreamid, frmsiz
+		a := &audioInfo{codec: "eac3"}
+		fscod := int(r.u(2))
+		if fscod == 3 {
+			a.sampleRate = ac3SampleRates[r.u(2)%3] / 2
+		} else {
+			a.sampleRate = ac3SampleRates[fscod]
+			r.skip(2) // numblkscod
+		}
+		acmod := int(r.u(3))
+		a.channels = ac3Channels[acmod]
+		if r.flag() { // lfeon
+			a.channels++
+		}
+		return a, r.err
+	}
+	r := &bitReader{data: data[4:]}
+	a := &audioInfo{codec: "ac3"}
+	if fscod := int(r.u(2)); fscod < len(ac3SampleRates) {
+		a.sampleRate = ac3SampleRates[fscod]
+	}
+	r.skip(6 + 5 + 3) // frmsizecod, bsid, bsmod
+	acmod := int(r.u(3))
+	if acmod&1 != 0 && acmod != 1 {
+		r.skip(2) // cmixlev
+	}
+	if acmod&4 != 0 {
+		r.skip(2) // surmixlev
+	}
+	if acmod == 2 {
+		r.skip(2) // dsurmod
+	}
+	a.channels = ac3Channels[acmod]
+	if r.flag() { // lfeon
+		a.channels++
+	}
+	return a, r.err
+}
+
+// gcd Returns the greatest common divisor of `a` and `b`
+func gcd(a, b int) int {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
+
+// ratio Returns the irreducible rational `num/den`, the way ffprobe writes them
+func ratio(num, den int) string {
+	if d := gcd(num, den); d > 0 {
+		num, den = num/d, den/d
+	}
+	return fmt.Sprintf("%d/%d", num, den)
+}
+
+// frameRateRatio Returns the rational of a frame rate, recognizing the NTSC ones,
+// e.g. "24000/1001" for 23.976.
+func frameRateRatio(fps float64) string {
+	if fps <= 0 || math.IsInf(fps, 0) || math.IsNaN(fps) {
+		return ""
+	}
+	if rounded := math.Round(fps); math.Abs(fps-rounded) < 0.001 {
+		return ratio(int(rounded), 1)
+	}
+	if ntsc := math.Round(fps * 1.001); math.Abs(fps*1.001-ntsc) < 0.01 {
+		return ratio(int(ntsc)*1000, 1001)
+	}
+	return ratio(int(math.Round(fps*1000)), 1000)
+}
+
+// seconds Formats a duration in seconds the way ffprobe does
+func seconds(s float64) string {
+	return fmt.Sprintf("%f", s)
+}
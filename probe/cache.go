@@ -0,0 +1,114 @@
+package probe
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Cache Stores probe data on disk, as one JSON file per input.
+// An entry is only used if the input still has the same path, size and modification time.
+// Inputs that are not local files, e.g. URLs, are not cached.
+type Cache struct {
+	Dir string
+}
+
+// cacheEntry A file of the cache
+type cacheEntry struct {
+	Path    string     `json:"path"`
+	Size    int64      `json:"size"`
+	ModTime time.Time  `json:"mod_time"`
+	Probe   *ProbeData `json:"probe"`
+}
+
+// NewCache Returns a cache storing its entries in `dir`, created if needed
+func NewCache(dir string) (*Cache, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	return &Cache{Dir: dir}, nil
+}
+
+// Probe Returns the cached probe data of `filename` if it did not change,
+// or probes it and caches the result.
+func (c *Cache) Probe(filename string) (*ProbeData, error) {
+	entry, ok := c.entry(filename)
+	if !ok {
+		return Probe(filename)
+	}
+	if cached := c.load(entry); cached != nil {
+		return cached, nil
+	}
+	probeData, err := Probe(filename)
+	if err != nil {
+		return probeData, err
+	}
+	entry.Probe = probeData
+	if err := c.store(entry); err != nil {
+		log.Println("Cannot cache the probe data of", filename, err)
+	}
+	return probeData, nil
+}
+
+// entry Returns the key of `filename` in the cache,
+// or false if it is not a local file.
+func (c *Cache) entry(filename string) (cacheEntry, bool) {
+	path, err := filepath.Abs(filename)
+	if err != nil {
+		return cacheEntry{}, false
+	}
+	info, err := os.Stat(path)
+	if err != nil || !info.Mode().IsRegular() {
+		return cacheEntry{}, false
+	}
+	return cacheEntry{Path: path, Size: info.Size(), ModTime: info.ModTime()}, true
+}
+
+// filename Returns the file of the cache storing the entry of `path`
+func (c *Cache) filename(path string) string {
+	sum := sha1.Sum([]byte(path))
+	return filepath.Join(c.Dir, hex.EncodeToString(sum[:])+".json")
+}
+
+// load Returns the cached probe data of `key`, or `nil` if missing or stale
+func (c *Cache) load(key cacheEntry) *ProbeData {
+	data, err := ioutil.ReadFile(c.filename(key.Path))
+	if err != nil {
+		return nil
+	}
+	var entry cacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		log.Println("Ignoring invalid probe cache entry for", key.Path, err)
+		return nil
+	}
+	if entry.Path != key.Path || entry.Size != key.Size || !entry.ModTime.Equal(key.ModTime) {
+		return nil
+	}
+	return entry.Probe
+}
+
+// store Writes `entry` to the cache, replacing any previous entry of the same path
+func (c *Cache) store(entry cacheEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(c.Dir, ".probe-*.json")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // No-op once renamed
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), c.filename(entry.Path))
+}
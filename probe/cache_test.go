@@ -0,0 +1,51 @@
+package probe
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCache(t *testing.T) {
+	dir, err := ioutil.TempDir("", "probe")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	cache, err := NewCache(filepath.Join(dir, "cache"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	input := filepath.Join(dir, "movie.mkv")
+	if err := ioutil.WriteFile(input, []byte("movie"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := cache.entry("https://example.com/movie.mkv"); ok {
+		t.Error("URLs should not be cached")
+	}
+	entry, ok := cache.entry(input)
+	if !ok {
+		t.Fatal("Local files should be cached")
+	}
+	entry.Probe = &ProbeData{Format: &ProbeFormat{DurationSeconds: "42.000000"}}
+	if err := cache.store(entry); err != nil {
+		t.Fatal("Cannot store entry:", err)
+	}
+	key, _ := cache.entry(input)
+	if cached := cache.load(key); cached == nil || cached.Format.DurationSeconds != "42.000000" {
+		t.Error("Unexpected cached probe data:", cached)
+	}
+
+	// A modified input is probed again
+	later := time.Now().Add(time.Hour)
+	if err := os.Chtimes(input, later, later); err != nil {
+		t.Fatal(err)
+	}
+	key, _ = cache.entry(input)
+	if cached := cache.load(key); cached != nil {
+		t.Error("Stale entry should not be used:", cached)
+	}
+}
@@ -0,0 +1,42 @@
+package probe
+
+import (
+	"io"
+	"io/ioutil"
+	"os/exec"
+	"strconv"
+
+	"gitlab.com/joutube/joutube-server/jt-error"
+)
+
+// EncodedSize Encodes `duration` seconds of the video stream `streamIndex` of `inputURL`,
+// starting at `start`, with libx264 at the given `height` and `crf`, and returns
+// the size in bytes of the encoded stream. The encode is thrown away.
+// A CRF encode spends bits where the picture needs them: its size measures
+// how complex the video is.
+func EncodedSize(inputURL string, streamIndex int, start, duration float64, height, crf int) (int64, error) {
+	cmd := exec.Command("ffmpeg", "-nostdin", "-v", "error",
+		"-ss", strconv.FormatFloat(start, 'f', 3, 64),
+		"-t", strconv.FormatFloat(duration, 'f', 3, 64),
+		"-i", inputURL,
+		"-map", "0:"+strconv.Itoa(streamIndex), "-an", "-sn", "-dn",
+		"-filter:v", "scale=trunc(oh*a/2)*2:"+strconv.Itoa(height),
+		"-c:v", "libx264", "-preset", "veryfast", "-crf", strconv.Itoa(crf),
+		"-f", "matroska", "pipe:1")
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return 0, err
+	}
+	if err := cmd.Start(); err != nil {
+		return 0, err
+	}
+	size, copyErr := io.Copy(ioutil.Discard, stdout)
+	if err := cmd.Wait(); err != nil {
+		return 0, jt_error.JoutubeError{
+			ErrorType:       jt_error.ConversionError,
+			Origin:          "encoding a sample of '" + inputURL + "'",
+			AssociatedError: err,
+		}
+	}
+	return size, copyErr
+}
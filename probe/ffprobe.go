@@ -1,9 +1,10 @@
 package probe
 
 import (
-	"encoding/json"
+	"errors"
 	"gitlab.com/joutube/joutube-server/jt-error"
-	"os/exec"
+	"strconv"
+	"strings"
 )
 
 // ffmpeg probe
@@ -80,46 +81,162 @@ type ProbeStream struct {
 	Level              int               `json:"level,omitempty"`
 	ColorRange         string            `json:"color_range,omitempty"`
 	ColorSpace         string            `json:"color_space,omitempty"`
+	ColorTransfer      string            `json:"color_transfer,omitempty"`
+	ColorPrimaries     string            `json:"color_primaries,omitempty"`
 
 	SampleFmt     string `json:"sample_fmt,omitempty"`
 	SampleRate    string `json:"sample_rate,omitempty"`
 	Channels      int    `json:"channels,omitempty"`
 	ChannelLayout string `json:"channel_layout,omitempty"`
 	BitsPerSample int    `json:"bits_per_sample,omitempty"`
+
+	SideDataList []StreamSideData `json:"side_data_list,omitempty"`
 }
 
-type ProbeData struct {
-	Format  *ProbeFormat   `json:"format,omitempty"`
-	Streams []*ProbeStream `json:"streams,omitempty"`
+// Types of the side data of streams, as written by ffprobe
+const (
+	SideDataDisplayMatrix     = "Display Matrix"
+	SideDataMasteringDisplay  = "Mastering display metadata"   // SMPTE ST 2086, of HDR10 streams
+	SideDataContentLightLevel = "Content light level metadata" // MaxCLL and MaxFALL, of HDR10 streams
+	SideDataDOVIConfiguration = "DOVI configuration record"    // Dolby Vision streams
+)
+
+// StreamSideData Side data of a stream. Only the fields of its `SideDataType` are set
+type StreamSideData struct {
+	SideDataType string `json:"side_data_type"`
+
+	// SideDataDisplayMatrix
+	Rotation int `json:"rotation,omitempty"`
+
+	// SideDataMasteringDisplay. Rationals, e.g. "34000/50000"
+	RedX         string `json:"red_x,omitempty"`
+	RedY         string `json:"red_y,omitempty"`
+	GreenX       string `json:"green_x,omitempty"`
+	GreenY       string `json:"green_y,omitempty"`
+	BlueX        string `json:"blue_x,omitempty"`
+	BlueY        string `json:"blue_y,omitempty"`
+	WhitePointX  string `json:"white_point_x,omitempty"`
+	WhitePointY  string `json:"white_point_y,omitempty"`
+	MinLuminance string `json:"min_luminance,omitempty"`
+	MaxLuminance string `json:"max_luminance,omitempty"`
+
+	// SideDataContentLightLevel, in cd/m²
+	MaxContent int `json:"max_content,omitempty"`
+	MaxAverage int `json:"max_average,omitempty"`
+
+	// SideDataDOVIConfiguration
+	DVVersionMajor            int `json:"dv_version_major,omitempty"`
+	DVVersionMinor            int `json:"dv_version_minor,omitempty"`
+	DVProfile                 int `json:"dv_profile,omitempty"`
+	DVLevel                   int `json:"dv_level,omitempty"`
+	RPUPresentFlag            int `json:"rpu_present_flag,omitempty"`
+	ELPresentFlag             int `json:"el_present_flag,omitempty"`
+	BLPresentFlag             int `json:"bl_present_flag,omitempty"`
+	DVBLSignalCompatibilityID int `json:"dv_bl_signal_compatibility_id,omitempty"`
 }
 
-func Probe(filename string) (*ProbeData, error) {
-	rf, errf := exec.Command("ffprobe", "-show_format", filename, "-print_format", "json").Output()
-	if errf != nil {
-		return nil, errf
+// SideData Returns the side data of type `sideDataType` of the stream, or `nil`
+func (s *ProbeStream) SideData(sideDataType string) *StreamSideData {
+	for i := range s.SideDataList {
+		if s.SideDataList[i].SideDataType == sideDataType {
+			return &s.SideDataList[i]
+		}
 	}
+	return nil
+}
 
-	var v ProbeData
-	// Unmarshal Format data
-	err := json.Unmarshal(rf, &v)
-	if err != nil {
-		return &v, err
+// MasteringDisplay Returns the mastering display metadata of the stream, or `nil`
+func (s *ProbeStream) MasteringDisplay() *StreamSideData {
+	return s.SideData(SideDataMasteringDisplay)
+}
+
+// ContentLightLevel Returns the content light level metadata of the stream, or `nil`
+func (s *ProbeStream) ContentLightLevel() *StreamSideData {
+	return s.SideData(SideDataContentLightLevel)
+}
+
+// DOVIConfiguration Returns the Dolby Vision configuration record of the stream,
+// or `nil` if it has no Dolby Vision metadata
+func (s *ProbeStream) DOVIConfiguration() *StreamSideData {
+	if d := s.SideData(SideDataDOVIConfiguration); d != nil && d.DVProfile > 0 {
+		return d
+	}
+	return nil
+}
+
+// FrameRate Returns the frame rate of the stream in frames per second,
+// from its average frame rate or, if unknown, its real base frame rate.
+// Returns 0 if both are unknown.
+func (s *ProbeStream) FrameRate() float64 {
+	for _, value := range []string{s.AvgFrameRate, s.RFrameRate} {
+		if rate, err := ParseRational(value); err == nil && rate > 0 {
+			return rate
+		}
 	}
+	return 0
+}
 
-	rs, errs := exec.Command("ffprobe", "-show_streams", filename, "-print_format", "json").Output()
-	if errs != nil {
-		return &v, errs
+// ParseRational Parses a rational like ffprobe writes them, e.g. "24000/1001", or a decimal number.
+func ParseRational(value string) (float64, error) {
+	parts := strings.SplitN(value, "/", 2)
+	numerator, err := strconv.ParseFloat(strings.TrimSpace(parts[0]), 64)
+	if err != nil || len(parts) == 1 {
+		return numerator, err
 	}
+	denominator, err := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+	if err != nil {
+		return 0, err
+	}
+	if denominator == 0 {
+		return 0, errors.New("invalid rational " + value + ": null denominator")
+	}
+	return numerator / denominator, nil
+}
 
-	// Unmarshal Streams Data
-	err = json.Unmarshal(rs, &v)
+type ChapterTags struct {
+	Title string `json:"title,omitempty"`
+}
+
+type ProbeChapter struct {
+	ID        int64       `json:"id"`
+	TimeBase  string      `json:"time_base"`
+	Start     int64       `json:"start"`
+	StartTime string      `json:"start_time"`
+	End       int64       `json:"end"`
+	EndTime   string      `json:"end_time"`
+	Tags      ChapterTags `json:"tags,omitempty"`
+}
+
+type ProbeProgram struct {
+	ProgramID  int               `json:"program_id"`
+	ProgramNum int               `json:"program_num"`
+	NBStreams  int               `json:"nb_streams"`
+	PMTPid     int               `json:"pmt_pid"`
+	PCRPid     int               `json:"pcr_pid"`
+	Tags       map[string]string `json:"tags,omitempty"`
+	Streams    []*ProbeStream    `json:"streams,omitempty"`
+}
 
-	return &v, err
+type ProbeData struct {
+	Format   *ProbeFormat    `json:"format,omitempty"`
+	Streams  []*ProbeStream  `json:"streams,omitempty"`
+	Chapters []*ProbeChapter `json:"chapters,omitempty"`
+	Programs []*ProbeProgram `json:"programs,omitempty"`
 }
 
+// DefaultCache Is used by GetProbeData when not `nil`
+var DefaultCache *Cache
+
+// GetProbeData Probes each input, using DefaultCache if set
 func GetProbeData(streamURLs ...string) (inputProbes []*ProbeData, errFinal error) {
 	for _, streamURL := range streamURLs {
-		probeData, err := Probe(streamURL)
+		var probeData *ProbeData
+		var err error
+		if DefaultCache != nil {
+			probeData, err = DefaultCache.Probe(streamURL)
+		} else {
+			probeData, err = Probe(streamURL)
+		}
 		if err != nil {
 			return nil, jt_error.JoutubeError{
 				ErrorType:       jt_error.ConversionError,
@@ -0,0 +1,703 @@
+package probe
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Matroska element IDs, with their marker bits
+const (
+	mkvEBML          = 0x1a45dfa3
+	mkvDocType       = 0x4282
+	mkvSegment       = 0x18538067
+	mkvSeekHead      = 0x114d9b74
+	mkvSeek          = 0x4dbb
+	mkvSeekID        = 0x53ab
+	mkvSeekPosition  = 0x53ac
+	mkvInfo          = 0x1549a966
+	mkvTimecodeScale = 0x2ad7b1
+	mkvDuration      = 0x4489
+	mkvTracks        = 0x1654ae6b
+	mkvTrackEntry    = 0xae
+	mkvTrackNumber   = 0xd7
+	mkvTrackUID      = 0x73c5
+	mkvTrackType     = 0x83
+	mkvCodecID       = 0x86
+	mkvCodecPrivate  = 0x63a2
+	mkvName          = 0x536e
+	mkvLanguage      = 0x22b59c
+	mkvFlagDefault   = 0x88
+	mkvFlagForced    = 0x55aa
+	mkvFlagHearing   = 0x55ab
+	mkvFlagVisual    = 0x55ac
+	mkvFlagOriginal  = 0x55ae
+	mkvFlagComment   = 0x55af
+	mkvDefaultDur    = 0x23e383
+	mkvVideo         = 0xe0
+	mkvPixelWidth    = 0xb0
+	mkvPixelHeight   = 0xba
+	mkvDisplayWidth  = 0x54b0
+	mkvDisplayHeight = 0x54ba
+	mkvColour        = 0x55b0
+	mkvMatrix        = 0x55b1
+	mkvBitsPerChan   = 0x55b2
+	mkvRange         = 0x55b9
+	mkvTransfer      = 0x55ba
+	mkvPrimaries     = 0x55bb
+	mkvMaxCLL        = 0x55bc
+	mkvMaxFALL       = 0x55bd
+	mkvMastering     = 0x55d0
+	mkvAudio         = 0xe1
+	mkvSampling      = 0xb5
+	mkvOutSampling   = 0x78b5
+	mkvChannels      = 0x9f
+	mkvBitDepth      = 0x6264
+	mkvBlockAddMap   = 0x41e4
+	mkvBlockAddType  = 0x41e7
+	mkvBlockAddExtra = 0x41ed
+	mkvChapters      = 0x1043a770
+	mkvEditionEntry  = 0x45b9
+	mkvChapterAtom   = 0xb6
+	mkvChapterUID    = 0x73c4
+	mkvChapterStart  = 0x91
+	mkvChapterEnd    = 0x92
+	mkvChapterDisp   = 0x80
+	mkvChapString    = 0x85
+	mkvTags          = 0x1254c367
+	mkvTag           = 0x7373
+	mkvTargets       = 0x63c0
+	mkvTagTrackUID   = 0x63c5
+	mkvSimpleTag     = 0x67c8
+	mkvTagName       = 0x45a3
+	mkvTagString     = 0x4487
+	mkvCluster       = 0x1f43b675
+)
+
+// mkvMaxElementSize The largest top-level element read in memory, clusters are skipped
+const mkvMaxElementSize = 64 << 20
+
+// mkvUnknownSize The size of elements whose size is unknown, e.g. live streams
+const mkvUnknownSize = -1
+
+// mkvCodecs The codec and type of the Matroska codec IDs
+var mkvCodecs = map[string][2]string{
+	"V_MPEG4/ISO/AVC":  {"h264", "video"},
+	"V_MPEGH/ISO/HEVC": {"hevc", "video"},
+	"V_AV1":            {"av1", "video"},
+	"V_VP9":            {"vp9", "video"},
+	"V_VP8":            {"vp8", "video"},
+	"V_MPEG2":          {"mpeg2video", "video"},
+	"V_MPEG4/ISO/ASP":  {"mpeg4", "video"},
+	"A_AAC":            {"aac", "audio"},
+	"A_AC3":            {"ac3", "audio"},
+	"A_EAC3":           {"eac3", "audio"},
+	"A_DTS":            {"dts", "audio"},
+	"A_TRUEHD":         {"truehd", "audio"},
+	"A_FLAC":           {"flac", "audio"},
+	"A_OPUS":           {"opus", "audio"},
+	"A_VORBIS":         {"vorbis", "audio"},
+	"A_MPEG/L3":        {"mp3", "audio"},
+	"A_MPEG/L2":        {"mp2", "audio"},
+	"A_PCM/INT/LIT":    {"pcm_s16le", "audio"},
+	"S_TEXT/UTF8":      {"subrip", "subtitle"},
+	"S_TEXT/ASS":       {"ass", "subtitle"},
+	"S_TEXT/SSA":       {"ass", "subtitle"},
+	"S_ASS":            {"ass", "subtitle"},
+	"S_SSA":            {"ass", "subtitle"},
+	"S_TEXT/WEBVTT":    {"webvtt", "subtitle"},
+	"S_HDMV/PGS":       {"hdmv_pgs_subtitle", "subtitle"},
+	"S_VOBSUB":         {"dvd_subtitle", "subtitle"},
+	"S_DVBSUB":         {"dvb_subtitle", "subtitle"},
+}
+
+// ebmlElement An element of an EBML document
+type ebmlElement struct {
+	id   uint32
+	data []byte
+}
+
+// readVint Reads a variable-length integer. `marker` keeps the length marker, as in element IDs.
+// Returns the value, its length, and whether all its bits are set.
+func readVint(b []byte, marker bool) (uint64, int, bool) {
+	if len(b) == 0 || b[0] == 0 {
+		return 0, 0, false
+	}
+	length := 1
+	for mask := byte(0x80); b[0]&mask == 0; mask >>= 1 {
+		length++
+	}
+	if length > len(b) {
+		return 0, 0, false
+	}
+	value := uint64(b[0])
+	if !marker {
+		value &= uint64(0xff >> uint(length))
+	}
+	allOnes := value == uint64(0xff>>uint(length))
+	for _, c := range b[1:length] {
+		value = value<<8 | uint64(c)
+		allOnes = allOnes && c == 0xff
+	}
+	return value, length, allOnes
+}
+
+// readElementHeader Reads the ID and data size of an element.
+// Returns its size as mkvUnknownSize if unknown.
+func readElementHeader(b []byte) (id uint32, size int64, header int, ok bool) {
+	idValue, idLength, _ := readVint(b, true)
+	if idLength == 0 {
+		return 0, 0, 0, false
+	}
+	sizeValue, sizeLength, unknown := readVint(b[idLength:], false)
+	if sizeLength == 0 {
+		return 0, 0, 0, false
+	}
+	size = int64(sizeValue)
+	if unknown {
+		size = mkvUnknownSize
+	}
+	return uint32(idValue), size, idLength + sizeLength, true
+}
+
+// ebmlChildren Returns the child elements of an element's data
+func ebmlChildren(data []byte) []ebmlElement {
+	var children []ebmlElement
+	for pos := 0; pos < len(data); {
+		id, size, header, ok := readElementHeader(data[pos:])
+		if !ok {
+			break
+		}
+		end := pos + header + int(size)
+		if size == mkvUnknownSize || end > len(data) {
+			end = len(data)
+		}
+		children = append(children, ebmlElement{id: id, data: data[pos+header : end]})
+		pos = end
+	}
+	return children
+}
+
+// uint Returns the value of an unsigned integer element
+func (e ebmlElement) uint() uint64 {
+	var v uint64
+	for _, b := range e.data {
+		v = v<<8 | uint64(b)
+	}
+	return v
+}
+
+// float Returns the value of a float element
+func (e ebmlElement) float() float64 {
+	switch len(e.data) {
+	case 4:
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(e.data)))
+	case 8:
+		return math.Float64frombits(binary.BigEndian.Uint64(e.data))
+	}
+	return 0
+}
+
+// string Returns the value of a string element, without padding
+func (e ebmlElement) string() string {
+	return strings.TrimRight(string(e.data), "\x00")
+}
+
+// mkvReader Reads the top-level elements of a Matroska segment
+type mkvReader struct {
+	r            io.ReaderAt
+	size         int64
+	segmentStart int64 // Of the segment data, the origin of the seek positions
+}
+
+// element Reads the header of the element at `pos`
+func (m *mkvReader) element(pos int64) (id uint32, size int64, header int, err error) {
+	b := make([]byte, 12)
+	n, err := m.r.ReadAt(b, pos)
+	if n == 0 {
+		if err == nil {
+			err = io.EOF
+		}
+		return 0, 0, 0, err
+	}
+	id, size, header, ok := readElementHeader(b[:n])
+	if !ok {
+		return 0, 0, 0, fmt.Errorf("invalid EBML element at %d", pos)
+	}
+	return id, size, header, nil
+}
+
+// read Returns the data of the element at `pos`
+func (m *mkvReader) read(pos int64, size int64, header int) ([]byte, error) {
+	if size == mkvUnknownSize || pos+int64(header)+size > m.size {
+		size = m.size - pos - int64(header)
+	}
+	if size > mkvMaxElementSize {
+		return nil, fmt.Errorf("EBML element at %d is too large", pos)
+	}
+	data := make([]byte, size)
+	_, err := m.r.ReadAt(data, pos+int64(header))
+	if err == io.EOF {
+		err = nil
+	}
+	return data, err
+}
+
+// probeMatroska Reads the tracks, chapters and tags of a Matroska or WebM file of `size` bytes
+func probeMatroska(r io.ReaderAt, size int64) (*ProbeData, error) {
+	m := &mkvReader{r: r, size: size}
+	id, headerSize, header, err := m.element(0)
+	if err != nil || id != mkvEBML {
+		return nil, errors.New("invalid EBML header")
+	}
+	ebmlHeader, err := m.read(0, headerSize, header)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range ebmlChildren(ebmlHeader) {
+		if e.id == mkvDocType && e.string() != "matroska" && e.string() != "webm" {
+			return nil, fmt.Errorf("%w: EBML document %q", ErrUnsupportedFormat, e.string())
+		}
+	}
+	pos := int64(header) + headerSize
+	id, _, header, err = m.element(pos)
+	if err != nil || id != mkvSegment {
+		return nil, errors.New("no Matroska segment")
+	}
+	m.segmentStart = pos + int64(header)
+
+	// Top-level elements, until the first cluster, and those the seek head points to after it
+	elements := map[uint32][]byte{}
+	var seekPositions []int64
+	read := func(pos int64) (uint32, int64, error) {
+		id, size, header, err := m.element(pos)
+		if err != nil {
+			return 0, 0, err
+		}
+		switch id {
+		case mkvSeekHead, mkvInfo, mkvTracks, mkvChapters, mkvTags:
+			if _, ok := elements[id]; ok && id != mkvSeekHead {
+				break
+			}
+			data, err := m.read(pos, size, header)
+			if err != nil {
+				return id, 0, err
+			}
+			elements[id] = data
+			if id == mkvSeekHead {
+				seekPositions = append(seekPositions, m.seekPositions(data)...)
+			}
+		}
+		if size == mkvUnknownSize {
+			return id, m.size, nil
+		}
+		return id, pos + int64(header) + size, nil
+	}
+	for pos = m.segmentStart; pos < m.size; {
+		id, next, err := read(pos)
+		if err != nil || id == mkvCluster {
+			break
+		}
+		pos = next
+	}
+	for _, pos := range seekPositions {
+		if pos < m.size {
+			read(pos)
+		}
+	}
+	if _, ok := elements[mkvTracks]; !ok {
+		return nil, errors.New("no Matroska tracks")
+	}
+
+	data := &ProbeData{Format: &ProbeFormat{
+		FormatName:       "matroska,webm",
+		FormatLongName:   "Matroska / WebM",
+		StartTimeSeconds: seconds(0),
+	}}
+	timecodeScale := 1000000.0 // Nanoseconds per tick
+	for _, e := range ebmlChildren(elements[mkvInfo]) {
+		if e.id == mkvTimecodeScale {
+			timecodeScale = float64(e.uint())
+		}
+	}
+	for _, e := range ebmlChildren(elements[mkvInfo]) {
+		if e.id == mkvDuration {
+			data.Format.DurationSeconds = seconds(e.float() * timecodeScale / 1e9)
+		}
+	}
+	uids := map[uint64]*ProbeStream{}
+	for _, e := range ebmlChildren(elements[mkvTracks]) {
+		if e.id != mkvTrackEntry {
+			continue
+		}
+		stream, uid := mkvTrack(e, len(data.Streams))
+		data.Streams = append(data.Streams, stream)
+		uids[uid] = stream
+	}
+	data.Chapters = mkvChapterList(elements[mkvChapters])
+	mkvStatistics(elements[mkvTags], uids)
+	return data, nil
+}
+
+// seekPositions Returns the absolute positions of the elements of a seek head
+func (m *mkvReader) seekPositions(seekHead []byte) []int64 {
+	var positions []int64
+	for _, seek := range ebmlChildren(seekHead) {
+		if seek.id != mkvSeek {
+			continue
+		}
+		for _, e := range ebmlChildren(seek.data) {
+			if e.id == mkvSeekPosition {
+				positions = append(positions, m.segmentStart+int64(e.uint()))
+			}
+		}
+	}
+	return positions
+}
+
+// mkvTrack Returns the probe data of a track entry, and its UID
+func mkvTrack(entry ebmlElement, index int) (*ProbeStream, uint64) {
+	s := &ProbeStream{
+		Index:       index,
+		TimeBase:    "1/1000",
+		StartTime:   seconds(0),
+		Tags:        StreamTags{Language: "eng"},
+		Disposition: StreamDisposition{Default: 1},
+	}
+	var uid uint64
+	var codecID string
+	var private []byte
+	var video, audio []ebmlElement
+	for _, e := range ebmlChildren(entry.data) {
+		switch e.id {
+		case mkvTrackUID:
+			uid = e.uint()
+		case mkvTrackType:
+			switch e.uint() {
+			case 1:
+				s.CodecType = "video"
+			case 2:
+				s.CodecType = "audio"
+			case 0x11:
+				s.CodecType = "subtitle"
+			default:
+				s.CodecType = "data"
+			}
+		case mkvCodecID:
+			codecID = e.string()
+		case mkvCodecPrivate:
+			private = e.data
+		case mkvName:
+			s.Tags.Title = e.string()
+		case mkvLanguage:
+			s.Tags.Language = e.string()
+		case mkvFlagDefault:
+			s.Disposition.Default = int(e.uint())
+		case mkvFlagForced:
+			s.Disposition.Forced = int(e.uint())
+		case mkvFlagHearing:
+			s.Disposition.HearingImpaired = int(e.uint())
+		case mkvFlagVisual:
+			s.Disposition.VisualImpaired = int(e.uint())
+		case mkvFlagOriginal:
+			s.Disposition.Original = int(e.uint())
+		case mkvFlagComment:
+			s.Disposition.Comment = int(e.uint())
+		case mkvDefaultDur:
+			if duration := e.uint(); duration > 0 {
+				s.AvgFrameRate = frameRateRatio(1e9 / float64(duration))
+				s.RFrameRate = s.AvgFrameRate
+			}
+		case mkvVideo:
+			video = ebmlChildren(e.data)
+		case mkvAudio:
+			audio = ebmlChildren(e.data)
+		case mkvBlockAddMap:
+			if dovi := mkvDOVIConfiguration(e); dovi != nil {
+				s.SideDataList = append(s.SideDataList, *dovi)
+			}
+		}
+	}
+	if codec, ok := mkvCodecs[codecID]; ok {
+		s.CodecName = codec[0]
+	} else if strings.HasPrefix(codecID, "A_AAC/") {
+		// Legacy IDs, e.g. A_AAC/MPEG4/LC/SBR
+		s.CodecName = "aac"
+	}
+	switch s.CodecType {
+	case "video":
+		mkvVideoTrack(s, codecID, private, video)
+	case "audio":
+		mkvAudioTrack(s, codecID, private, audio)
+	}
+	return s, uid
+}
+
+// mkvVideoTrack Sets the video fields of a track from its codec private data and Video element
+func mkvVideoTrack(s *ProbeStream, codecID string, private []byte, video []ebmlElement) {
+	var info *videoInfo
+	var err error
+	switch s.CodecName {
+	case "h264":
+		info, err = parseAVCC(private)
+	case "hevc":
+		info, err = parseHVCC(private)
+	case "av1":
+		info, err = parseAV1C(private)
+	}
+	if info != nil && err == nil {
+		info.apply(s)
+	}
+	var displayWidth, displayHeight int
+	for _, e := range video {
+		switch e.id {
+		case mkvPixelWidth:
+			s.Width = int(e.uint())
+		case mkvPixelHeight:
+			s.Height = int(e.uint())
+		case mkvDisplayWidth:
+			displayWidth = int(e.uint())
+		case mkvDisplayHeight:
+			displayHeight = int(e.uint())
+		case mkvColour:
+			mkvColourElement(s, ebmlChildren(e.data))
+		}
+	}
+	if displayWidth > 0 && displayHeight > 0 && s.Width > 0 && s.Height > 0 {
+		s.DisplayAspectRatio = ratio(displayWidth, displayHeight)
+		s.SampleAspectRatio = ratio(displayWidth*s.Height, displayHeight*s.Width)
+	}
+}
+
+// mkvColourElement Sets the colour description and HDR metadata of a video track
+func mkvColourElement(s *ProbeStream, colour []ebmlElement) {
+	var primaries, transfer, matrix uint64 = 2, 2, 2 // Unspecified
+	colorRange := ""
+	var lightLevel *StreamSideData
+	for _, e := range colour {
+		switch e.id {
+		case mkvPrimaries:
+			primaries = e.uint()
+		case mkvTransfer:
+			transfer = e.uint()
+		case mkvMatrix:
+			matrix = e.uint()
+		case mkvRange:
+			switch e.uint() {
+			case 1:
+				colorRange = "tv"
+			case 2:
+				colorRange = "pc"
+			}
+		case mkvMaxCLL, mkvMaxFALL:
+			if lightLevel == nil {
+				lightLevel = &StreamSideData{SideDataType: SideDataContentLightLevel}
+			}
+			if e.id == mkvMaxCLL {
+				lightLevel.MaxContent = int(e.uint())
+			} else {
+				lightLevel.MaxAverage = int(e.uint())
+			}
+		case mkvMastering:
+			s.SideDataList = append(s.SideDataList, mkvMasteringMetadata(ebmlChildren(e.data)))
+		}
+	}
+	setString(&s.ColorPrimaries, colorPrimariesNames[primaries])
+	setString(&s.ColorTransfer, colorTransferNames[transfer])
+	setString(&s.ColorSpace, colorSpaceNames[matrix])
+	setString(&s.ColorRange, colorRange)
+	if lightLevel != nil {
+		s.SideDataList = append(s.SideDataList, *lightLevel)
+	}
+}
+
+// mkvMasteringMetadata Returns the side data of a MasteringMetadata element,
+// with the same denominators as ffprobe.
+func mkvMasteringMetadata(metadata []ebmlElement) StreamSideData {
+	chromaticity := func(v float64) string { return fmt.Sprintf("%d/50000", int(math.Round(v*50000))) }
+	luminance := func(v float64) string { return fmt.Sprintf("%d/10000", int(math.Round(v*10000))) }
+	d := StreamSideData{SideDataType: SideDataMasteringDisplay}
+	for _, e := range metadata {
+		switch e.id {
+		case 0x55d1:
+			d.RedX = chromaticity(e.float())
+		case 0x55d2:
+			d.RedY = chromaticity(e.float())
+		case 0x55d3:
+			d.GreenX = chromaticity(e.float())
+		case 0x55d4:
+			d.GreenY = chromaticity(e.float())
+		case 0x55d5:
+			d.BlueX = chromaticity(e.float())
+		case 0x55d6:
+			d.BlueY = chromaticity(e.float())
+		case 0x55d7:
+			d.WhitePointX = chromaticity(e.float())
+		case 0x55d8:
+			d.WhitePointY = chromaticity(e.float())
+		case 0x55d9:
+			d.MaxLuminance = luminance(e.float())
+		case 0x55da:
+			d.MinLuminance = luminance(e.float())
+		}
+	}
+	return d
+}
+
+// mkvDOVIConfiguration Returns the Dolby Vision configuration of a BlockAdditionMapping, or `nil`
+func mkvDOVIConfiguration(mapping ebmlElement) *StreamSideData {
+	var kind string
+	var extra []byte
+	for _, e := range ebmlChildren(mapping.data) {
+		switch e.id {
+		case mkvBlockAddType:
+			kind = string([]byte{byte(e.uint() >> 24), byte(e.uint() >> 16), byte(e.uint() >> 8), byte(e.uint())})
+		case mkvBlockAddExtra:
+			extra = e.data
+		}
+	}
+	if kind != "dvcC" && kind != "dvvC" && kind != "dvwC" {
+		return nil
+	}
+	dovi := doviConfiguration(extra)
+	return &dovi
+}
+
+// mkvAudioTrack Sets the audio fields of a track from its codec private data and Audio element
+func mkvAudioTrack(s *ProbeStream, codecID string, private []byte, audio []ebmlElement) {
+	a := &audioInfo{codec: s.CodecName}
+	var outputSampleRate int
+	for _, e := range audio {
+		switch e.id {
+		case mkvSampling:
+			a.sampleRate = int(e.float())
+		case mkvOutSampling:
+			outputSampleRate = int(e.float())
+		case mkvChannels:
+			a.channels = int(e.uint())
+		case mkvBitDepth:
+			s.BitsPerRawSample = strconv.Itoa(int(e.uint()))
+		}
+	}
+	if outputSampleRate > 0 {
+		a.sampleRate = outputSampleRate
+	}
+	if s.CodecName == "aac" {
+		if config, err := parseAudioSpecificConfig(private); len(private) > 0 && err == nil {
+			a.profile = config.profile
+		} else {
+			switch {
+			case strings.HasSuffix(codecID, "/SBR"):
+				a.profile = "HE-AAC"
+			case strings.HasSuffix(codecID, "/LC"):
+				a.profile = "LC"
+			case strings.HasSuffix(codecID, "/MAIN"):
+				a.profile = "Main"
+			}
+		}
+	}
+	a.apply(s)
+}
+
+// mkvChapterList Returns the chapters of the first edition of a Chapters element
+func mkvChapterList(chapters []byte) []*ProbeChapter {
+	var list []*ProbeChapter
+	for _, edition := range ebmlChildren(chapters) {
+		if edition.id != mkvEditionEntry {
+			continue
+		}
+		for _, atom := range ebmlChildren(edition.data) {
+			if atom.id != mkvChapterAtom {
+				continue
+			}
+			c := &ProbeChapter{TimeBase: "1/1000000000"}
+			for _, e := range ebmlChildren(atom.data) {
+				switch e.id {
+				case mkvChapterUID:
+					c.ID = int64(e.uint())
+				case mkvChapterStart:
+					c.Start = int64(e.uint())
+				case mkvChapterEnd:
+					c.End = int64(e.uint())
+				case mkvChapterDisp:
+					for _, d := range ebmlChildren(e.data) {
+						if d.id == mkvChapString && len(c.Tags.Title) == 0 {
+							c.Tags.Title = d.string()
+						}
+					}
+				}
+			}
+			c.StartTime = seconds(float64(c.Start) / 1e9)
+			c.EndTime = seconds(float64(c.End) / 1e9)
+			list = append(list, c)
+		}
+		break
+	}
+	return list
+}
+
+// mkvStatistics Sets the bitrate and duration of the tracks from the statistics tags
+// written by mkvmerge
+func mkvStatistics(tags []byte, tracks map[uint64]*ProbeStream) {
+	for _, tag := range ebmlChildren(tags) {
+		if tag.id != mkvTag {
+			continue
+		}
+		var stream *ProbeStream
+		simpleTags := map[string]string{}
+		for _, e := range ebmlChildren(tag.data) {
+			switch e.id {
+			case mkvTargets:
+				for _, target := range ebmlChildren(e.data) {
+					if target.id == mkvTagTrackUID {
+						stream = tracks[target.uint()]
+					}
+				}
+			case mkvSimpleTag:
+				var name, value string
+				for _, t := range ebmlChildren(e.data) {
+					switch t.id {
+					case mkvTagName:
+						name = t.string()
+					case mkvTagString:
+						value = t.string()
+					}
+				}
+				simpleTags[name] = value
+			}
+		}
+		if stream == nil {
+			continue
+		}
+		if bps, err := strconv.Atoi(simpleTags["BPS"]); err == nil {
+			stream.BitRate = bps
+		}
+		if duration, ok := parseTagDuration(simpleTags["DURATION"]); ok {
+			stream.Duration = duration
+		}
+		if frames := simpleTags["NUMBER_OF_FRAMES"]; len(frames) > 0 {
+			stream.NbFrames = frames
+		}
+	}
+}
+
+// parseTagDuration Parses a duration tag, e.g. "01:23:45.678000000", in seconds
+func parseTagDuration(value string) (float64, bool) {
+	parts := strings.Split(value, ":")
+	if len(parts) != 3 {
+		return 0, false
+	}
+	var total float64
+	for _, part := range parts {
+		v, err := strconv.ParseFloat(part, 64)
+		if err != nil {
+			return 0, false
+		}
+		total = total*60 + v
+	}
+	return total, true
+}
@@ -0,0 +1,738 @@
+package probe
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// mp4MaxBoxSize The largest `moov` or `moof` box read in memory
+const mp4MaxBoxSize = 256 << 20
+
+// mp4Box A box of an ISO-BMFF file
+type mp4Box struct {
+	kind   string
+	offset int64 // Of the box header
+	header int64 // Length of the header
+	size   int64 // Of the whole box
+}
+
+// readMP4Boxes Calls `f` on each box of `r` between `start` and `end`
+func readMP4Boxes(r io.ReaderAt, start, end int64, f func(b mp4Box) error) error {
+	header := make([]byte, 16)
+	for offset := start; offset+8 <= end; {
+		if _, err := r.ReadAt(header[:8], offset); err != nil {
+			return err
+		}
+		b := mp4Box{kind: string(header[4:8]), offset: offset, header: 8,
+			size: int64(binary.BigEndian.Uint32(header[:4]))}
+		switch b.size {
+		case 0: // Until the end of the file
+			b.size = end - offset
+		case 1:
+			if _, err := r.ReadAt(header[8:16], offset+8); err != nil {
+				return err
+			}
+			b.header = 16
+			b.size = int64(binary.BigEndian.Uint64(header[8:16]))
+		}
+		if b.size < b.header {
+			return fmt.Errorf("invalid size of box %q at %d", b.kind, offset)
+		}
+		if err := f(b); err != nil {
+			return err
+		}
+		offset += b.size
+	}
+	return nil
+}
+
+// readMP4Box Returns the payload of `b`
+func readMP4Box(r io.ReaderAt, b mp4Box) ([]byte, error) {
+	if b.size-b.header > mp4MaxBoxSize {
+		return nil, fmt.Errorf("box %q is too large", b.kind)
+	}
+	data := make([]byte, b.size-b.header)
+	_, err := r.ReadAt(data, b.offset+b.header)
+	return data, err
+}
+
+// forEachMP4Box Calls `f` on each box of `data`, with its payload
+// and its offset in `data`.
+func forEachMP4Box(data []byte, f func(kind string, payload []byte, offset int)) {
+	for offset := 0; offset+8 <= len(data); {
+		size := int(binary.BigEndian.Uint32(data[offset:]))
+		header := 8
+		switch size {
+		case 0:
+			size = len(data) - offset
+		case 1:
+			if offset+16 > len(data) {
+				return
+			}
+			size = int(binary.BigEndian.Uint64(data[offset+8:]))
+			header = 16
+		}
+		if size < header || offset+size > len(data) {
+			return
+		}
+		f(string(data[offset+4:offset+8]), data[offset+header:offset+size], offset)
+		offset += size
+	}
+}
+
+// mp4Reader Reads big-endian fields of a box payload, returning zeros past its end
+type mp4Reader struct {
+	data []byte
+	pos  int
+}
+
+func (r *mp4Reader) next(n int) []byte {
+	if r.pos+n > len(r.data) {
+		r.pos = len(r.data)
+		return make([]byte, n)
+	}
+	b := r.data[r.pos : r.pos+n]
+	r.pos += n
+	return b
+}
+
+func (r *mp4Reader) u8() uint8   { return r.next(1)[0] }
+func (r *mp4Reader) u16() uint16 { return binary.BigEndian.Uint16(r.next(2)) }
+func (r *mp4Reader) u32() uint32 { return binary.BigEndian.Uint32(r.next(4)) }
+func (r *mp4Reader) u64() uint64 { return binary.BigEndian.Uint64(r.next(8)) }
+func (r *mp4Reader) skip(n int)  { r.next(n) }
+
+// fullBox Returns the version, flags and body of a full box
+func fullBox(payload []byte) (uint8, uint32, *mp4Reader) {
+	r := &mp4Reader{data: payload}
+	versionAndFlags := r.u32()
+	return uint8(versionAndFlags >> 24), versionAndFlags & 0xffffff, r
+}
+
+// mp4Track A track of the `moov` box
+type mp4Track struct {
+	id          uint32
+	enabled     bool
+	handler     string
+	timescale   uint32
+	duration    uint64
+	language    string
+	width       int
+	height      int
+	sampleEntry string
+	entry       []byte // Payload of the sample entry
+	sampleCount uint64
+	sampleTime  uint64 // Sum of the sample durations
+	sampleBytes uint64
+	// Defaults of the fragments, from `trex`
+	defaultDuration uint32
+	defaultSize     uint32
+	defaultFlags    uint32
+}
+
+// mp4Movie The content of a `moov` box
+type mp4Movie struct {
+	timescale        uint32
+	duration         uint64
+	fragmentDuration uint64
+	tracks           []*mp4Track
+}
+
+// parseMoov Parses the tracks of a `moov` box payload
+func parseMoov(payload []byte) *mp4Movie {
+	movie := &mp4Movie{}
+	forEachMP4Box(payload, func(kind string, payload []byte, _ int) {
+		switch kind {
+		case "mvhd":
+			version, _, r := fullBox(payload)
+			if version == 1 {
+				r.skip(16)
+				movie.timescale = r.u32()
+				movie.duration = r.u64()
+			} else {
+				r.skip(8)
+				movie.timescale = r.u32()
+				movie.duration = uint64(r.u32())
+			}
+		case "trak":
+			movie.tracks = append(movie.tracks, parseTrak(payload))
+		case "mvex":
+			forEachMP4Box(payload, func(kind string, payload []byte, _ int) {
+				switch kind {
+				case "mehd":
+					version, _, r := fullBox(payload)
+					if version == 1 {
+						movie.fragmentDuration = r.u64()
+					} else {
+						movie.fragmentDuration = uint64(r.u32())
+					}
+				case "trex":
+					_, _, r := fullBox(payload)
+					id := r.u32()
+					r.skip(4) // default_sample_description_index
+					for _, t := range movie.tracks {
+						if t.id == id {
+							t.defaultDuration, t.defaultSize, t.defaultFlags = r.u32(), r.u32(), r.u32()
+						}
+					}
+				}
+			})
+		}
+	})
+	return movie
+}
+
+// parseTrak Parses a `trak` box payload
+func parseTrak(payload []byte) *mp4Track {
+	t := &mp4Track{language: "und"}
+	var walk func(payload []byte)
+	walk = func(payload []byte) {
+		forEachMP4Box(payload, func(kind string, payload []byte, _ int) {
+			switch kind {
+			case "mdia", "minf", "stbl":
+				walk(payload)
+			case "tkhd":
+				version, flags, r := fullBox(payload)
+				t.enabled = flags&1 != 0
+				if version == 1 {
+					r.skip(16)
+					t.id = r.u32()
+					r.skip(4 + 8)
+				} else {
+					r.skip(8)
+					t.id = r.u32()
+					r.skip(4 + 4)
+				}
+				r.skip(8 + 2 + 2 + 2 + 2 + 36)
+				t.width, t.height = int(r.u32()>>16), int(r.u32()>>16)
+			case "mdhd":
+				version, _, r := fullBox(payload)
+				if version == 1 {
+					r.skip(16)
+					t.timescale = r.u32()
+					t.duration = r.u64()
+				} else {
+					r.skip(8)
+					t.timescale = r.u32()
+					t.duration = uint64(r.u32())
+				}
+				if packed := r.u16(); packed != 0 && packed != 0x7fff {
+					t.language = string([]byte{byte(packed>>10&0x1f) + 0x60, byte(packed>>5&0x1f) + 0x60, byte(packed&0x1f) + 0x60})
+				}
+			case "hdlr":
+				_, _, r := fullBox(payload)
+				r.skip(4)
+				t.handler = string(r.next(4))
+			case "stsd":
+				_, _, r := fullBox(payload)
+				if r.u32() > 0 {
+					forEachMP4Box(r.data[r.pos:], func(kind string, payload []byte, _ int) {
+						if len(t.sampleEntry) == 0 {
+							t.sampleEntry, t.entry = kind, payload
+						}
+					})
+				}
+			case "stts":
+				_, _, r := fullBox(payload)
+				for n := r.u32(); n > 0 && r.pos < len(r.data); n-- {
+					count, delta := uint64(r.u32()), uint64(r.u32())
+					t.sampleCount += count
+					t.sampleTime += count * delta
+				}
+			case "stsz":
+				_, _, r := fullBox(payload)
+				size, count := uint64(r.u32()), r.u32()
+				if size > 0 {
+					t.sampleBytes = size * uint64(count)
+					break
+				}
+				for ; count > 0 && r.pos < len(r.data); count-- {
+					t.sampleBytes += uint64(r.u32())
+				}
+			}
+		})
+	}
+	walk(payload)
+	return t
+}
+
+// fourCCTag Returns the codec tag of a four-character code, as written by ffprobe
+func fourCCTag(code string) string {
+	if len(code) != 4 {
+		return "0x0000"
+	}
+	return fmt.Sprintf("0x%08x", binary.LittleEndian.Uint32([]byte(code)))
+}
+
+// mp4Codecs The codec of the sample entries whose headers are not parsed
+var mp4Codecs = map[string]string{
+	"ac-3": "ac3", "ec-3": "eac3", "Opus": "opus", "fLaC": "flac", "alac": "alac",
+	"avc1": "h264", "avc3": "h264", "hvc1": "hevc", "hev1": "hevc", "dvh1": "hevc", "dvhe": "hevc", "av01": "av1",
+	"mp4a": "aac", "mp4v": "mpeg4", "vp09": "vp9", "vp08": "vp8",
+	"wvtt": "webvtt", "tx3g": "mov_text", "stpp": "ttml", "c608": "eia_608",
+}
+
+// stream Returns the probe data of the track
+func (t *mp4Track) stream(index int) *ProbeStream {
+	s := &ProbeStream{
+		Index:          index,
+		CodecName:      mp4Codecs[t.sampleEntry],
+		CodecTagString: t.sampleEntry,
+		CodecTag:       fourCCTag(t.sampleEntry),
+		TimeBase:       fmt.Sprintf("1/%d", t.timescale),
+		StartTime:      seconds(0),
+		DurationTs:     t.duration,
+		Tags:           StreamTags{Language: t.language},
+	}
+	if t.timescale > 0 {
+		s.Duration = float64(t.duration) / float64(t.timescale)
+	}
+	if t.sampleCount > 0 {
+		s.NbFrames = fmt.Sprint(t.sampleCount)
+	}
+	if s.Duration > 0 {
+		s.BitRate = int(float64(t.sampleBytes*8) / s.Duration)
+	}
+	switch t.handler {
+	case "vide":
+		s.CodecType = "video"
+		s.Width, s.Height = t.width, t.height
+		if t.sampleTime > 0 {
+			s.AvgFrameRate = ratio(int(t.sampleCount)*int(t.timescale), int(t.sampleTime))
+			s.RFrameRate = frameRateRatio(float64(t.sampleCount) * float64(t.timescale) / float64(t.sampleTime))
+		}
+		t.parseVisualSampleEntry(s)
+	case "soun":
+		s.CodecType = "audio"
+		t.parseAudioSampleEntry(s)
+	case "subt", "text", "sbtl", "clcp":
+		s.CodecType = "subtitle"
+	default:
+		s.CodecType = "data"
+	}
+	return s
+}
+
+// parseVisualSampleEntry Sets the codec of a video stream from its sample entry
+func (t *mp4Track) parseVisualSampleEntry(s *ProbeStream) {
+	if len(t.entry) < 78 {
+		return
+	}
+	r := &mp4Reader{data: t.entry}
+	r.skip(24)
+	if width, height := int(r.u16()), int(r.u16()); width > 0 && height > 0 {
+		s.Width, s.Height = width, height
+	}
+	var info *videoInfo
+	var err error
+	forEachMP4Box(t.entry[78:], func(kind string, payload []byte, _ int) {
+		switch kind {
+		case "avcC":
+			info, err = parseAVCC(payload)
+		case "hvcC":
+			info, err = parseHVCC(payload)
+		case "av1C":
+			info, err = parseAV1C(payload)
+		case "colr":
+			if len(payload) >= 11 && string(payload[:4]) == "nclx" {
+				r := &mp4Reader{data: payload[4:]}
+				c := &videoInfo{}
+				c.setColor(uint64(r.u16()), uint64(r.u16()), uint64(r.u16()), r.u8()&0x80 != 0)
+				s.ColorPrimaries, s.ColorTransfer, s.ColorSpace, s.ColorRange = c.colorPrimaries, c.colorTransfer, c.colorSpace, c.colorRange
+			}
+		case "pasp":
+			r := &mp4Reader{data: payload}
+			if h, v := int(r.u32()), int(r.u32()); h > 0 && v > 0 && s.Width > 0 && s.Height > 0 {
+				s.SampleAspectRatio = ratio(h, v)
+				s.DisplayAspectRatio = ratio(s.Width*h, s.Height*v)
+			}
+		case "mdcv":
+			s.SideDataList = append(s.SideDataList, masteringDisplayFromMDCV(payload))
+		case "clli":
+			r := &mp4Reader{data: payload}
+			s.SideDataList = append(s.SideDataList, StreamSideData{SideDataType: SideDataContentLightLevel,
+				MaxContent: int(r.u16()), MaxAverage: int(r.u16())})
+		case "dvcC", "dvvC", "dvwC":
+			s.SideDataList = append(s.SideDataList, doviConfiguration(payload))
+		}
+	})
+	if info == nil || err != nil {
+		return
+	}
+	// The sample entry has the actual dimensions, the colour boxes override the parameter sets
+	colors := [...]string{s.ColorPrimaries, s.ColorTransfer, s.ColorSpace, s.ColorRange}
+	width, height := s.Width, s.Height
+	info.apply(s)
+	s.Width, s.Height = width, height
+	setString(&s.ColorPrimaries, colors[0])
+	setString(&s.ColorTransfer, colors[1])
+	setString(&s.ColorSpace, colors[2])
+	setString(&s.ColorRange, colors[3])
+}
+
+// masteringDisplayFromMDCV Returns the side data of an `mdcv` box
+// (SMPTE ST 2086, primaries in green, blue, red order)
+func masteringDisplayFromMDCV(payload []byte) StreamSideData {
+	r := &mp4Reader{data: payload}
+	chromaticity := func() string { return fmt.Sprintf("%d/50000", r.u16()) }
+	greenX, greenY := chromaticity(), chromaticity()
+	blueX, blueY := chromaticity(), chromaticity()
+	redX, redY := chromaticity(), chromaticity()
+	return StreamSideData{
+		SideDataType: SideDataMasteringDisplay,
+		RedX:         redX, RedY: redY,
+		GreenX: greenX, GreenY: greenY,
+		BlueX: blueX, BlueY: blueY,
+		WhitePointX:  chromaticity(),
+		WhitePointY:  chromaticity(),
+		MaxLuminance: fmt.Sprintf("%d/10000", r.u32()),
+		MinLuminance: fmt.Sprintf("%d/10000", r.u32()),
+	}
+}
+
+// doviConfiguration Returns the side data of a Dolby Vision configuration record
+func doviConfiguration(payload []byte) StreamSideData {
+	r := &bitReader{data: payload}
+	return StreamSideData{
+		SideDataType:              SideDataDOVIConfiguration,
+		DVVersionMajor:            int(r.u(8)),
+		DVVersionMinor:            int(r.u(8)),
+		DVProfile:                 int(r.u(7)),
+		DVLevel:                   int(r.u(6)),
+		RPUPresentFlag:            int(r.u(1)),
+		ELPresentFlag:             int(r.u(1)),
+		BLPresentFlag:             int(r.u(1)),
+		DVBLSignalCompatibilityID: int(r.u(4)),
+	}
+}
+
+// parseAudioSampleEntry Sets the codec of an audio stream from its sample entry
+func (t *mp4Track) parseAudioSampleEntry(s *ProbeStream) {
+	if len(t.entry) < 28 {
+		return
+	}
+	r := &mp4Reader{data: t.entry}
+	r.skip(8)
+	version := r.u16()
+	r.skip(6)
+	a := &audioInfo{codec: s.CodecName, channels: int(r.u16())}
+	s.BitsPerSample = int(r.u16())
+	r.skip(4)
+	a.sampleRate = int(r.u32() >> 16)
+	children := 28
+	switch version {
+	case 1:
+		children += 16
+	case 2:
+		children += 36
+	}
+	if children > len(t.entry) {
+		children = len(t.entry)
+	}
+	forEachMP4Box(t.entry[children:], func(kind string, payload []byte, _ int) {
+		switch kind {
+		case "esds":
+			if config := parseESDS(payload); config != nil {
+				a = config
+			}
+		case "dac3":
+			r := &bitReader{data: payload}
+			if fscod := int(r.u(2)); fscod < len(ac3SampleRates) {
+				a.sampleRate = ac3SampleRates[fscod]
+			}
+			r.skip(5 + 3)
+			a.channels = ac3Channels[r.u(3)]
+			if r.flag() {
+				a.channels++
+			}
+		case "dec3":
+			r := &bitReader{data: payload}
+			r.skip(13 + 3)
+			if fscod := int(r.u(2)); fscod < len(ac3SampleRates) {
+				a.sampleRate = ac3SampleRates[fscod]
+			}
+			r.skip(5 + 1 + 1 + 3)
+			a.channels = ac3Channels[r.u(3)]
+			if r.flag() {
+				a.channels++
+			}
+		}
+	})
+	if len(a.codec) == 0 {
+		a.codec = s.CodecName
+	}
+	a.apply(s)
+	s.BitsPerSample = 0
+}
+
+// parseESDS Returns the codec of an `esds` box: AAC with its AudioSpecificConfig, or MP3
+func parseESDS(payload []byte) *audioInfo {
+	_, _, r := fullBox(payload)
+	readDescriptor := func() (byte, int) {
+		tag := r.u8()
+		length := 0
+		for i := 0; i < 4; i++ {
+			b := r.u8()
+			length = length<<7 | int(b&0x7f)
+			if b&0x80 == 0 {
+				break
+			}
+		}
+		return tag, length
+	}
+	if tag, _ := readDescriptor(); tag != 0x03 {
+		return nil
+	}
+	r.skip(2) // ES_ID
+	flags := r.u8()
+	if flags&0x80 != 0 {
+		r.skip(2)
+	}
+	if flags&0x40 != 0 {
+		r.skip(int(r.u8()))
+	}
+	if flags&0x20 != 0 {
+		r.skip(2)
+	}
+	if tag, _ := readDescriptor(); tag != 0x04 {
+		return nil
+	}
+	objectTypeIndication := r.u8()
+	r.skip(1 + 3 + 4 + 4)
+	switch objectTypeIndication {
+	case 0x69, 0x6b:
+		return &audioInfo{codec: "mp3"}
+	case 0x40, 0x66, 0x67, 0x68:
+		tag, length := readDescriptor()
+		if tag != 0x05 {
+			return &audioInfo{codec: "aac"}
+		}
+		if a, err := parseAudioSpecificConfig(r.next(length)); err == nil {
+			return a
+		}
+		return &audioInfo{codec: "aac"}
+	}
+	return nil
+}
+
+// probeMP4 Reads the tracks of an ISO-BMFF file of `size` bytes
+func probeMP4(r io.ReaderAt, size int64) (*ProbeData, error) {
+	format := &ProbeFormat{
+		FormatName:     "mov,mp4,m4a,3gp,3g2,mj2",
+		FormatLongName: "QuickTime / MOV",
+	}
+	var movie *mp4Movie
+	err := readMP4Boxes(r, 0, size, func(b mp4Box) error {
+		switch b.kind {
+		case "ftyp":
+			payload, err := readMP4Box(r, b)
+			if err != nil || len(payload) < 8 {
+				return err
+			}
+			tags := &FormatTags{
+				MajorBrand:   string(payload[:4]),
+				MinorVersion: fmt.Sprint(binary.BigEndian.Uint32(payload[4:8])),
+			}
+			for i := 8; i+4 <= len(payload); i += 4 {
+				tags.CompatibleBrands += string(payload[i : i+4])
+			}
+			format.Tags = tags
+		case "moov":
+			payload, err := readMP4Box(r, b)
+			if err != nil {
+				return err
+			}
+			movie = parseMoov(payload)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if movie == nil {
+		return nil, errors.New("no moov box: not an MP4 file, or a segment without its initialization")
+	}
+
+	data := &ProbeData{Format: format}
+	defaults := map[string]bool{}
+	for _, t := range movie.tracks {
+		s := t.stream(len(data.Streams))
+		if t.enabled && !defaults[s.CodecType] {
+			s.Disposition.Default = 1
+			defaults[s.CodecType] = true
+		}
+		data.Streams = append(data.Streams, s)
+	}
+	duration := movie.duration
+	if duration == 0 {
+		duration = movie.fragmentDuration
+	}
+	format.StartTimeSeconds = seconds(0)
+	if movie.timescale > 0 {
+		format.DurationSeconds = seconds(float64(duration) / float64(movie.timescale))
+	}
+	return data, nil
+}
+
+// fragmentedMP4Packets Returns the packets of the first video track of a fragmented MP4 segment.
+// Its tracks are declared by the `moov` box of `init`, of `initSize` bytes, or of the segment itself
+// if `init` is `nil`. Positions are relative to the start of `init`.
+func fragmentedMP4Packets(ctx context.Context, init io.ReaderAt, initSize int64, r io.ReaderAt, size int64) ([]*ProbePacket, error) {
+	var movie *mp4Movie
+	findMoov := func(r io.ReaderAt, size int64) error {
+		return readMP4Boxes(r, 0, size, func(b mp4Box) error {
+			if b.kind != "moov" {
+				return nil
+			}
+			payload, err := readMP4Box(r, b)
+			if err == nil {
+				movie = parseMoov(payload)
+			}
+			return err
+		})
+	}
+	var err error
+	if init != nil {
+		err = findMoov(init, initSize)
+	} else {
+		err = findMoov(r, size)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if movie == nil {
+		return nil, errors.New("no moov box: the initialization segment is needed")
+	}
+	var video *mp4Track
+	for _, t := range movie.tracks {
+		if t.handler == "vide" {
+			video = t
+			break
+		}
+	}
+	if video == nil || video.timescale == 0 {
+		return nil, errors.New("no video track found in MP4")
+	}
+
+	var packets []*ProbePacket
+	err = readMP4Boxes(r, 0, size, func(b mp4Box) error {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if b.kind != "moof" {
+			return nil
+		}
+		payload, err := readMP4Box(r, b)
+		if err != nil {
+			return err
+		}
+		forEachMP4Box(payload, func(kind string, payload []byte, _ int) {
+			if kind == "traf" {
+				packets = append(packets, video.trafPackets(payload, b.offset, initSize)...)
+			}
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(packets) == 0 {
+		return nil, errors.New("no fragment found in MP4")
+	}
+	return packets, nil
+}
+
+// trafPackets Returns the samples of a `traf` box of the track, in a `moof` box at `moofOffset`.
+// `initSize` is added to the positions.
+func (t *mp4Track) trafPackets(payload []byte, moofOffset, initSize int64) []*ProbePacket {
+	var packets []*ProbePacket
+	trackID := uint32(0)
+	baseOffset := moofOffset
+	var decodeTime uint64
+	defaultDuration, defaultSize, defaultFlags := t.defaultDuration, t.defaultSize, t.defaultFlags
+	forEachMP4Box(payload, func(kind string, payload []byte, _ int) {
+		switch kind {
+		case "tfhd":
+			_, flags, r := fullBox(payload)
+			trackID = r.u32()
+			if flags&0x01 != 0 {
+				baseOffset = int64(r.u64())
+			}
+			if flags&0x02 != 0 {
+				r.skip(4) // sample_description_index
+			}
+			if flags&0x08 != 0 {
+				defaultDuration = r.u32()
+			}
+			if flags&0x10 != 0 {
+				defaultSize = r.u32()
+			}
+			if flags&0x20 != 0 {
+				defaultFlags = r.u32()
+			}
+		case "tfdt":
+			version, _, r := fullBox(payload)
+			if version == 1 {
+				decodeTime = r.u64()
+			} else {
+				decodeTime = uint64(r.u32())
+			}
+		case "trun":
+			if trackID != t.id {
+				return
+			}
+			version, flags, r := fullBox(payload)
+			count := r.u32()
+			offset := baseOffset
+			if flags&0x01 != 0 {
+				offset += int64(int32(r.u32()))
+			}
+			firstFlags, hasFirstFlags := uint32(0), flags&0x04 != 0
+			if hasFirstFlags {
+				firstFlags = r.u32()
+			}
+			timescale := float64(t.timescale)
+			for i := uint32(0); i < count && r.pos < len(r.data); i++ {
+				duration, size, sampleFlags := defaultDuration, defaultSize, defaultFlags
+				var compositionOffset int64
+				if flags&0x100 != 0 {
+					duration = r.u32()
+				}
+				if flags&0x200 != 0 {
+					size = r.u32()
+				}
+				if flags&0x400 != 0 {
+					sampleFlags = r.u32()
+				}
+				if i == 0 && hasFirstFlags {
+					sampleFlags = firstFlags
+				}
+				if flags&0x800 != 0 {
+					if version == 0 {
+						compositionOffset = int64(r.u32())
+					} else {
+						compositionOffset = int64(int32(r.u32()))
+					}
+				}
+				packet := &ProbePacket{
+					DtsTime:      float64(decodeTime) / timescale,
+					PtsTime:      float64(int64(decodeTime)+compositionOffset) / timescale,
+					DurationTime: float64(duration) / timescale,
+					Size:         uint(size),
+					Pos:          uint(initSize + offset),
+					Flags:        "__",
+				}
+				if sampleFlags&0x10000 == 0 { // sample_is_non_sync_sample
+					packet.Flags = "K_"
+				}
+				packets = append(packets, packet)
+				decodeTime += uint64(duration)
+				offset += int64(size)
+			}
+		}
+	})
+	return packets
+}
@@ -0,0 +1,122 @@
+package probe
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"strconv"
+)
+
+// ErrUnsupportedFormat Is returned by Native for files it cannot read
+var ErrUnsupportedFormat = errors.New("unsupported format")
+
+// Native Probes files with parsers written in Go, without ffprobe.
+// It reads the headers of ISO-BMFF (MP4, MOV), Matroska (MKV, WebM) and MPEG-TS files,
+// and the packets of MPEG-TS and fragmented MP4 segments.
+// Only local files are supported.
+type Native struct{}
+
+// containerFormat A container recognized by Native
+type containerFormat int
+
+const (
+	unknownFormat containerFormat = iota
+	mpegTSFormat
+	mp4Format
+	matroskaFormat
+)
+
+// detectFormat Recognizes the container of a file from its first bytes
+func detectFormat(header []byte) containerFormat {
+	switch {
+	case len(header) > tsPacketSize && header[0] == tsSyncByte && header[tsPacketSize] == tsSyncByte:
+		return mpegTSFormat
+	case bytes.HasPrefix(header, []byte{0x1a, 0x45, 0xdf, 0xa3}):
+		return matroskaFormat
+	case len(header) >= 8:
+		switch string(header[4:8]) {
+		case "ftyp", "styp", "moov", "moof", "sidx", "free", "mdat", "wide":
+			return mp4Format
+		}
+	}
+	return unknownFormat
+}
+
+// open Opens `filename` and recognizes its container
+func open(filename string) (*os.File, int64, containerFormat, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, 0, unknownFormat, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, 0, unknownFormat, err
+	}
+	header := make([]byte, 2*tsPacketSize)
+	n, err := f.ReadAt(header, 0)
+	if err != nil && err != io.EOF {
+		f.Close()
+		return nil, 0, unknownFormat, err
+	}
+	return f, info.Size(), detectFormat(header[:n]), nil
+}
+
+// Probe Reads the format, streams, chapters and programs of `filename`
+func (Native) Probe(filename string) (*ProbeData, error) {
+	f, size, format, err := open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var data *ProbeData
+	switch format {
+	case mpegTSFormat:
+		data, err = probeTS(f, size)
+	case mp4Format:
+		data, err = probeMP4(f, size)
+	case matroskaFormat:
+		data, err = probeMatroska(f, size)
+	default:
+		return nil, ErrUnsupportedFormat
+	}
+	if err != nil {
+		return nil, err
+	}
+	data.Format.Filename = filename
+	data.Format.NBStreams = len(data.Streams)
+	data.Format.Size = strconv.FormatInt(size, 10)
+	data.Format.ProbeScore = 100
+	if duration, err := strconv.ParseFloat(data.Format.DurationSeconds, 64); err == nil && duration > 0 && len(data.Format.BitRate) == 0 {
+		data.Format.BitRate = strconv.Itoa(int(float64(size*8) / duration))
+	}
+	return data, nil
+}
+
+// Packets Reads the video packets of an MPEG-TS or fragmented MP4 segment.
+// Fragmented MP4 segments need the `initFilename` declaring their tracks,
+// unless they contain it.
+func (Native) Packets(ctx context.Context, initFilename, filename string) ([]*ProbePacket, error) {
+	f, size, format, err := open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	switch format {
+	case mpegTSFormat:
+		return tsPackets(ctx, f, size)
+	case mp4Format:
+		if len(initFilename) == 0 {
+			return fragmentedMP4Packets(ctx, nil, 0, f, size)
+		}
+		initFile, initSize, _, err := open(initFilename)
+		if err != nil {
+			return nil, err
+		}
+		defer initFile.Close()
+		return fragmentedMP4Packets(ctx, initFile, initSize, f, size)
+	}
+	return nil, ErrUnsupportedFormat
+}
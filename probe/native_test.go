@@ -0,0 +1,214 @@
+package probe
+
+import (
+	"context"
+	"encoding/binary"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const tsSegment = "../iframe-playlist-generator/tests/bigbuckbunny-400k-00004.ts"
+
+func TestNativeMPEGTS(t *testing.T) {
+	data, err := Native{}.Probe(tsSegment)
+	if err != nil {
+		t.Fatal("Cannot probe segment:", err)
+	}
+	if data.Format.FormatName != "mpegts" || len(data.Streams) != 2 || len(data.Programs) != 1 {
+		t.Fatalf("Unexpected probe data: %+v", data.Format)
+	}
+	video, audio := data.Streams[0], data.Streams[1]
+	if video.CodecName != "h264" || video.Profile != "Main" || video.Level != 30 ||
+		video.Width != 416 || video.Height != 234 || video.AvgFrameRate != "25/1" {
+		t.Errorf("Unexpected video stream: %+v", video)
+	}
+	if audio.CodecName != "aac" || audio.Profile != "LC" || audio.SampleRate != "22050" || audio.Channels != 2 {
+		t.Errorf("Unexpected audio stream: %+v", audio)
+	}
+
+	// Same key-frames as ffprobe
+	packets, err := Native{}.Packets(context.Background(), "", tsSegment)
+	if err != nil {
+		t.Fatal("Cannot read packets:", err)
+	}
+	var keyFrames []uint
+	for _, p := range packets {
+		if p.IsKeyFrame() {
+			keyFrames = append(keyFrames, p.Pos)
+		}
+	}
+	if len(keyFrames) != 4 || keyFrames[1] != 28388 {
+		t.Error("Unexpected key-frames:", keyFrames)
+	}
+}
+
+// box Returns an ISO-BMFF box
+func box(kind string, payload ...[]byte) []byte {
+	var data []byte
+	for _, p := range payload {
+		data = append(data, p...)
+	}
+	b := make([]byte, 8, 8+len(data))
+	binary.BigEndian.PutUint32(b, uint32(8+len(data)))
+	copy(b[4:], kind)
+	return append(b, data...)
+}
+
+// u32s Returns big-endian 32-bit integers
+func u32s(values ...uint32) []byte {
+	b := make([]byte, 4*len(values))
+	for i, v := range values {
+		binary.BigEndian.PutUint32(b[4*i:], v)
+	}
+	return b
+}
+
+func TestNativeMP4(t *testing.T) {
+	visualEntry := make([]byte, 78)
+	binary.BigEndian.PutUint16(visualEntry[24:], 1920)
+	binary.BigEndian.PutUint16(visualEntry[26:], 1080)
+	language := []byte{0x15, 0xc7} // "eng"
+	init := append(box("ftyp", []byte("isom"), u32s(512), []byte("isomiso6")),
+		box("moov",
+			box("mvhd", u32s(0, 0, 0, 1000, 0)),
+			box("trak",
+				box("tkhd", u32s(0x3, 0, 0, 1, 0, 0), make([]byte, 52), u32s(1920<<16, 1080<<16)),
+				box("mdia",
+					box("mdhd", u32s(0, 0, 0, 24000, 0), language, []byte{0, 0}),
+					box("hdlr", u32s(0, 0), []byte("vide")),
+					box("minf", box("stbl",
+						box("stsd", u32s(0, 1), box("avc1", visualEntry,
+							box("avcC", []byte{1, 100, 0, 40, 0xff, 0xe0, 0}),
+							box("colr", []byte("nclx"), []byte{0, 9, 0, 16, 0, 9, 0}))),
+						box("stts", u32s(0, 0)),
+						box("stsz", u32s(0, 0, 0)))))),
+			box("mvex", box("trex", u32s(0, 1, 1, 1001, 0, 0x10000))))...)
+	moof := box("moof",
+		box("mfhd", u32s(0, 1)),
+		box("traf",
+			box("tfhd", u32s(0x020000, 1)),
+			box("tfdt", u32s(0, 48048)),
+			box("trun", u32s(0x000205, 3, 0, 0x02000000, 1000, 200, 300))))
+	// Fix the data offset: samples start right after the moof and the mdat header
+	binary.BigEndian.PutUint32(moof[len(moof)-20:], uint32(len(moof)+8))
+	segment := append(moof, box("mdat", make([]byte, 1500))...)
+
+	dir, err := ioutil.TempDir("", "probe")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	initFilename, segmentFilename := filepath.Join(dir, "init.mp4"), filepath.Join(dir, "segment.m4s")
+	ioutil.WriteFile(initFilename, init, 0600)
+	ioutil.WriteFile(segmentFilename, segment, 0600)
+
+	data, err := Native{}.Probe(initFilename)
+	if err != nil {
+		t.Fatal("Cannot probe init segment:", err)
+	}
+	if data.Format.Tags == nil || data.Format.Tags.MajorBrand != "isom" || len(data.Streams) != 1 {
+		t.Fatalf("Unexpected probe data: %+v", data.Format)
+	}
+	s := data.Streams[0]
+	if s.CodecName != "h264" || s.Profile != "High" || s.Level != 40 || s.Width != 1920 ||
+		s.Tags.Language != "eng" || s.ColorTransfer != "smpte2084" || s.Disposition.Default != 1 {
+		t.Errorf("Unexpected stream: %+v", s)
+	}
+
+	packets, err := Native{}.Packets(context.Background(), initFilename, segmentFilename)
+	if err != nil {
+		t.Fatal("Cannot read packets:", err)
+	}
+	if len(packets) != 3 || !packets[0].IsKeyFrame() || packets[1].IsKeyFrame() {
+		t.Fatalf("Unexpected packets: %+v", packets)
+	}
+	if p := packets[1]; p.Pos != uint(len(init)+len(moof)+8+1000) || p.Size != 200 ||
+		math.Abs(p.DtsTime-48048.0/24000-1001.0/24000) > 1e-9 {
+		t.Errorf("Unexpected second packet: %+v", *p)
+	}
+}
+
+// element Returns an EBML element
+func element(id uint32, data ...[]byte) []byte {
+	var b []byte
+	for shift := 24; shift >= 0; shift -= 8 {
+		if c := byte(id >> uint(shift)); c != 0 || len(b) > 0 {
+			b = append(b, c)
+		}
+	}
+	var payload []byte
+	for _, d := range data {
+		payload = append(payload, d...)
+	}
+	size := make([]byte, 8)
+	binary.BigEndian.PutUint64(size, uint64(len(payload)))
+	size[0] = 0x01 // 8-byte size
+	return append(append(b, size...), payload...)
+}
+
+// uintElement Returns an EBML unsigned integer element
+func uintElement(id uint32, v uint64) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, v)
+	return element(id, b)
+}
+
+func TestNativeMatroska(t *testing.T) {
+	duration := make([]byte, 8)
+	binary.BigEndian.PutUint64(duration, math.Float64bits(5400000))
+	sampling := make([]byte, 8)
+	binary.BigEndian.PutUint64(sampling, math.Float64bits(48000))
+	file := append(element(mkvEBML, element(mkvDocType, []byte("matroska"))),
+		element(mkvSegment,
+			element(mkvInfo, uintElement(mkvTimecodeScale, 1000000), element(mkvDuration, duration)),
+			element(mkvTracks,
+				element(mkvTrackEntry,
+					uintElement(mkvTrackNumber, 1), uintElement(mkvTrackUID, 11), uintElement(mkvTrackType, 1),
+					element(mkvCodecID, []byte("V_MPEGH/ISO/HEVC")), uintElement(mkvDefaultDur, 41708333),
+					element(mkvVideo, uintElement(mkvPixelWidth, 3840), uintElement(mkvPixelHeight, 2160),
+						element(mkvColour, uintElement(mkvPrimaries, 9), uintElement(mkvTransfer, 16),
+							uintElement(mkvMaxCLL, 1000), uintElement(mkvMaxFALL, 400)))),
+				element(mkvTrackEntry,
+					uintElement(mkvTrackNumber, 2), uintElement(mkvTrackUID, 22), uintElement(mkvTrackType, 2),
+					element(mkvCodecID, []byte("A_EAC3")), element(mkvLanguage, []byte("fre")),
+					uintElement(mkvFlagDefault, 0),
+					element(mkvAudio, element(mkvSampling, sampling), uintElement(mkvChannels, 6)))),
+			element(mkvChapters, element(mkvEditionEntry, element(mkvChapterAtom,
+				uintElement(mkvChapterUID, 7), uintElement(mkvChapterStart, 60e9),
+				element(mkvChapterDisp, element(mkvChapString, []byte("Opening")))))),
+			element(mkvTags, element(mkvTag,
+				element(mkvTargets, uintElement(mkvTagTrackUID, 22)),
+				element(mkvSimpleTag, element(mkvTagName, []byte("BPS")), element(mkvTagString, []byte("640000"))))),
+			element(mkvCluster, make([]byte, 100)))...)
+
+	f, err := ioutil.TempFile("", "probe-*.mkv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	f.Write(file)
+	f.Close()
+
+	data, err := Native{}.Probe(f.Name())
+	if err != nil {
+		t.Fatal("Cannot probe file:", err)
+	}
+	if data.Format.DurationSeconds != "5400.000000" || len(data.Streams) != 2 {
+		t.Fatalf("Unexpected probe data: %+v", data.Format)
+	}
+	video, audio := data.Streams[0], data.Streams[1]
+	if video.CodecName != "hevc" || video.Width != 3840 || video.AvgFrameRate != "24000/1001" ||
+		video.ColorTransfer != "smpte2084" || video.SideData(SideDataContentLightLevel) == nil {
+		t.Errorf("Unexpected video stream: %+v", video)
+	}
+	if audio.CodecName != "eac3" || audio.Channels != 6 || audio.SampleRate != "48000" || audio.Tags.Language != "fre" ||
+		audio.Disposition.Default != 0 || audio.BitRate != 640000 {
+		t.Errorf("Unexpected audio stream: %+v", audio)
+	}
+	if len(data.Chapters) != 1 || data.Chapters[0].StartTime != "60.000000" || data.Chapters[0].Tags.Title != "Opening" {
+		t.Errorf("Unexpected chapters: %+v", data.Chapters)
+	}
+}
@@ -0,0 +1,111 @@
+package probe
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+)
+
+// Prober Reads the streams of media files
+type Prober interface {
+	// Probe Returns the format, streams, chapters and programs of `filename`
+	Probe(filename string) (*ProbeData, error)
+	// Packets Returns the packets of the video stream of the media segment `filename`,
+	// in decode order. If not empty, `initFilename` is the initialization segment
+	// preceding it, and positions are relative to the start of `initFilename`.
+	// Stops as soon as `ctx` is done.
+	Packets(ctx context.Context, initFilename, filename string) ([]*ProbePacket, error)
+}
+
+// DefaultProber Is the backend used by Probe and GetProbeData
+var DefaultProber Prober = FFprobe{}
+
+// Probe Probes `filename` with DefaultProber
+func Probe(filename string) (*ProbeData, error) {
+	return DefaultProber.Probe(filename)
+}
+
+// ProbePacket A packet of a stream, as written by `ffprobe -show_packets`
+type ProbePacket struct {
+	PtsTime      float64 `json:"pts_time,string"`
+	DtsTime      float64 `json:"dts_time,string"`
+	DurationTime float64 `json:"duration_time,string"`
+	Size         uint    `json:"size,string"`
+	Pos          uint    `json:"pos,string"`
+	Flags        string  `json:"flags"`
+}
+
+// IsKeyFrame Returns `true` if the packet
+// comes from a key-frame.
+func (p *ProbePacket) IsKeyFrame() bool {
+	if len(p.Flags) < 1 {
+		log.Println("Assertion Failed: Flags length is 0. Should be at least 1")
+		return false
+	}
+	return p.Flags[0] == 'K'
+}
+
+// FFprobe Probes files with the ffprobe binary
+type FFprobe struct{}
+
+// Probe Runs ffprobe once on `filename` for its format, streams, chapters and programs
+func (FFprobe) Probe(filename string) (*ProbeData, error) {
+	r, err := exec.Command("ffprobe", "-v", "error", "-print_format", "json",
+		"-show_format", "-show_streams", "-show_chapters", "-show_programs", filename).Output()
+	if err != nil {
+		return nil, err
+	}
+
+	var v ProbeData
+	err = json.Unmarshal(r, &v)
+	return &v, err
+}
+
+// Packets Runs ffprobe on `filename`, preceded by `initFilename` if not empty, for its video packets.
+// ffprobe is killed if `ctx` is done before it completes.
+func (FFprobe) Packets(ctx context.Context, initFilename, filename string) ([]*ProbePacket, error) {
+	type ProbePackets struct {
+		Packets []*ProbePacket `json:"packets"`
+	}
+
+	input := filename
+	var stdin io.Reader
+	if len(initFilename) > 0 {
+		// The init segment and the segment are probed as one stream
+		initFile, err := os.Open(initFilename)
+		if err != nil {
+			return nil, err
+		}
+		defer initFile.Close()
+		segmentFile, err := os.Open(filename)
+		if err != nil {
+			return nil, err
+		}
+		defer segmentFile.Close()
+		input = "-"
+		stdin = io.MultiReader(initFile, segmentFile)
+	}
+	cmd := exec.CommandContext(ctx, "ffprobe",
+		"-hide_banner", "-loglevel", "warning",
+		"-show_packets",
+		"-select_streams", "v",
+		"-show_entries", "packet=pts_time,dts_time,size,pos,flags,duration_time",
+		"-print_format", "json",
+		input,
+	)
+	cmd.Stdin = stdin
+	cmd.Stderr = os.Stderr
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+	if err := cmd.Run(); err != nil {
+		return nil, err
+	}
+
+	var v ProbePackets
+	err := json.Unmarshal(stdout.Bytes(), &v)
+	return v.Packets, err
+}
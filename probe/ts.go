@@ -0,0 +1,573 @@
+package probe
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+)
+
+const (
+	tsPacketSize  = 188
+	tsSyncByte    = 0x47
+	tsHeadLength  = 8 << 20 // Bytes read for the headers of the streams
+	tsTailLength  = 1 << 20 // Bytes read at the end for the duration
+	tsMaxPESBytes = 1 << 18 // Bytes of the first PES packets kept to find the headers
+	tsClock       = 90000
+)
+
+// tsStreamTypes The codec and type of the PMT stream types
+var tsStreamTypes = map[byte][2]string{
+	0x01: {"mpeg1video", "video"},
+	0x02: {"mpeg2video", "video"},
+	0x03: {"mp3", "audio"},
+	0x04: {"mp3", "audio"},
+	0x0f: {"aac", "audio"},
+	0x10: {"mpeg4", "video"},
+	0x11: {"aac_latm", "audio"},
+	0x15: {"timed_id3", "data"},
+	0x1b: {"h264", "video"},
+	0x24: {"hevc", "video"},
+	0x81: {"ac3", "audio"},
+	0x87: {"eac3", "audio"},
+	0x90: {"hdmv_pgs_subtitle", "subtitle"},
+}
+
+// tsDescriptorCodecs The codec and type of private streams, by descriptor tag
+var tsDescriptorCodecs = map[byte][2]string{
+	0x56: {"dvb_teletext", "subtitle"},
+	0x59: {"dvb_subtitle", "subtitle"},
+	0x6a: {"ac3", "audio"},
+	0x7a: {"eac3", "audio"},
+}
+
+// tsPacket The header of a transport stream packet
+type tsPacket struct {
+	pid              int
+	payloadUnitStart bool
+	randomAccess     bool
+	payload          []byte
+}
+
+// parseTSPacket Parses a 188-byte transport stream packet
+func parseTSPacket(data []byte) (tsPacket, error) {
+	if len(data) < tsPacketSize || data[0] != tsSyncByte {
+		return tsPacket{}, errors.New("lost MPEG-TS sync")
+	}
+	p := tsPacket{
+		pid:              int(data[1]&0x1f)<<8 | int(data[2]),
+		payloadUnitStart: data[1]&0x40 != 0,
+	}
+	control := data[3] >> 4 & 0x3
+	offset := 4
+	if control&0x2 != 0 {
+		length := int(data[4])
+		if length > 0 {
+			p.randomAccess = data[5]&0x40 != 0
+		}
+		offset += 1 + length
+	}
+	if control&0x1 != 0 && offset < tsPacketSize {
+		p.payload = data[offset:tsPacketSize]
+	}
+	return p, nil
+}
+
+// pesHeader The header of a PES packet
+type pesHeader struct {
+	pts, dts int64 // -1 if absent
+	length   int   // Of the header, the elementary stream follows
+}
+
+// parsePESHeader Parses the header of a PES packet at the start of `data`
+func parsePESHeader(data []byte) (pesHeader, bool) {
+	h := pesHeader{pts: -1, dts: -1}
+	if len(data) < 9 || data[0] != 0 || data[1] != 0 || data[2] != 1 {
+		return h, false
+	}
+	h.length = 9 + int(data[8])
+	flags := data[7] >> 6
+	if flags&0x2 != 0 && len(data) >= 14 {
+		h.pts = parseTimestamp(data[9:14])
+		h.dts = h.pts
+	}
+	if flags == 0x3 && len(data) >= 19 {
+		h.dts = parseTimestamp(data[14:19])
+	}
+	if h.length > len(data) {
+		h.length = len(data)
+	}
+	return h, true
+}
+
+// parseTimestamp Parses a 33-bit PES timestamp
+func parseTimestamp(b []byte) int64 {
+	return int64(b[0]>>1&0x07)<<30 | int64(b[1])<<22 | int64(b[2]>>1)<<15 | int64(b[3])<<7 | int64(b[4]>>1)
+}
+
+// tsStream A stream of the transport stream, as declared by its PMT
+type tsStream struct {
+	pid        int
+	streamType byte
+	stream     *ProbeStream
+	found      bool   // Whether the headers of the elementary stream were parsed
+	pes        []byte // Elementary stream of the PES packet being read, until `found`
+	firstPTS   int64
+	lastPTS    int64
+}
+
+// tsDemuxer Reads the program tables and streams of a transport stream
+type tsDemuxer struct {
+	programs []*ProbeProgram
+	pmtPIDs  map[int]*ProbeProgram
+	streams  map[int]*tsStream
+	order    []*tsStream
+}
+
+func newTSDemuxer() *tsDemuxer {
+	return &tsDemuxer{pmtPIDs: map[int]*ProbeProgram{}, streams: map[int]*tsStream{}}
+}
+
+// section Returns the section of a PSI packet, without its pointer field and CRC
+func section(p tsPacket) ([]byte, bool) {
+	if !p.payloadUnitStart || len(p.payload) < 1 {
+		return nil, false
+	}
+	data := p.payload[1:]
+	if pointer := int(p.payload[0]); pointer < len(data) {
+		data = data[pointer:]
+	} else {
+		return nil, false
+	}
+	if len(data) < 3 {
+		return nil, false
+	}
+	length := int(data[1]&0x0f)<<8 | int(data[2])
+	if length < 9 || 3+length > len(data) {
+		return nil, false // Sections spanning several packets are not supported
+	}
+	return data[:3+length-4], true
+}
+
+// parsePAT Registers the PMT of each program
+func (d *tsDemuxer) parsePAT(p tsPacket) {
+	data, ok := section(p)
+	if !ok || data[0] != 0x00 {
+		return
+	}
+	for i := 8; i+4 <= len(data); i += 4 {
+		number := int(data[i])<<8 | int(data[i+1])
+		pid := int(data[i+2]&0x1f)<<8 | int(data[i+3])
+		if number == 0 {
+			continue // Network PID
+		}
+		if _, ok := d.pmtPIDs[pid]; !ok {
+			program := &ProbeProgram{ProgramID: number, ProgramNum: number, PMTPid: pid}
+			d.pmtPIDs[pid] = program
+			d.programs = append(d.programs, program)
+		}
+	}
+}
+
+// parsePMT Registers the streams of a program
+func (d *tsDemuxer) parsePMT(program *ProbeProgram, p tsPacket) {
+	data, ok := section(p)
+	if !ok || data[0] != 0x02 || len(program.Streams) > 0 {
+		return
+	}
+	program.PCRPid = int(data[8]&0x1f)<<8 | int(data[9])
+	i := 12 + (int(data[10]&0x0f)<<8 | int(data[11]))
+	for i+5 <= len(data) {
+		streamType := data[i]
+		pid := int(data[i+1]&0x1f)<<8 | int(data[i+2])
+		infoLength := int(data[i+3]&0x0f)<<8 | int(data[i+4])
+		end := i + 5 + infoLength
+		if end > len(data) {
+			break
+		}
+		descriptors := data[i+5 : end]
+		i = end
+		if _, ok := d.streams[pid]; ok {
+			continue
+		}
+		s := &tsStream{pid: pid, streamType: streamType, firstPTS: -1, lastPTS: -1, stream: &ProbeStream{
+			Index:          len(d.order),
+			CodecTagString: fmt.Sprintf("[%d][0][0][0]", streamType),
+			CodecTag:       fmt.Sprintf("0x%04x", streamType),
+			TimeBase:       fmt.Sprintf("1/%d", tsClock),
+		}}
+		codec, known := tsStreamTypes[streamType]
+		forEachDescriptor(descriptors, func(tag byte, value []byte) {
+			switch tag {
+			case 0x0a: // ISO 639 language
+				if len(value) >= 3 {
+					s.stream.Tags.Language = string(value[:3])
+				}
+			case 0xb0: // Dolby Vision video stream, same layout as the configuration record
+				if len(value) >= 4 {
+					s.stream.SideDataList = append(s.stream.SideDataList, doviConfiguration(value))
+				}
+			default:
+				if c, ok := tsDescriptorCodecs[tag]; ok && streamType == 0x06 {
+					codec, known = c, true
+				}
+			}
+		})
+		if !known {
+			codec = [2]string{"", "data"}
+		}
+		s.stream.CodecName, s.stream.CodecType = codec[0], codec[1]
+		d.streams[pid] = s
+		d.order = append(d.order, s)
+		program.Streams = append(program.Streams, s.stream)
+		program.NBStreams = len(program.Streams)
+	}
+}
+
+// forEachDescriptor Calls `f` on each descriptor of a descriptor loop
+func forEachDescriptor(data []byte, f func(tag byte, value []byte)) {
+	for i := 0; i+2 <= len(data); {
+		length := int(data[i+1])
+		if i+2+length > len(data) {
+			return
+		}
+		f(data[i], data[i+2:i+2+length])
+		i += 2 + length
+	}
+}
+
+// handle Reads a transport stream packet
+func (d *tsDemuxer) handle(p tsPacket) {
+	if p.pid == 0 {
+		d.parsePAT(p)
+		return
+	}
+	if program, ok := d.pmtPIDs[p.pid]; ok {
+		d.parsePMT(program, p)
+		return
+	}
+	s, ok := d.streams[p.pid]
+	if !ok {
+		return
+	}
+	payload := p.payload
+	if p.payloadUnitStart {
+		h, ok := parsePESHeader(payload)
+		if !ok {
+			return
+		}
+		if h.pts >= 0 {
+			if s.firstPTS < 0 || h.pts < s.firstPTS {
+				s.firstPTS = h.pts
+			}
+			if h.pts > s.lastPTS {
+				s.lastPTS = h.pts
+			}
+		}
+		if !s.found && len(s.pes) > 0 {
+			s.parseHeaders()
+			s.pes = s.pes[:0]
+		}
+		payload = payload[h.length:]
+	}
+	if !s.found && len(s.pes) < tsMaxPESBytes {
+		s.pes = append(s.pes, payload...)
+	}
+}
+
+// parseHeaders Looks for the headers of the stream in the elementary stream read so far
+func (s *tsStream) parseHeaders() {
+	switch s.stream.CodecName {
+	case "h264", "hevc":
+		forEachNAL(s.pes, func(nal []byte) bool {
+			var v *videoInfo
+			var err error
+			if s.stream.CodecName == "h264" && nal[0]&0x1f == 7 {
+				v, err = parseAVCSPS(nal)
+			} else if s.stream.CodecName == "hevc" && len(nal) > 1 && nal[0]>>1&0x3f == 33 {
+				v, err = parseHEVCSPS(nal)
+			} else {
+				return true
+			}
+			if err == nil {
+				v.apply(s.stream)
+				s.found = true
+			}
+			return !s.found
+		})
+	case "aac":
+		if i := indexSync(s.pes, 0xff, 0xf0); i >= 0 {
+			if a, err := parseADTS(s.pes[i:]); err == nil {
+				a.apply(s.stream)
+				s.found = true
+			}
+		}
+	case "ac3", "eac3":
+		if i := indexSync(s.pes, 0x0b, 0xff); i >= 0 && s.pes[i+1] == 0x77 {
+			if a, err := parseAC3(s.pes[i:]); err == nil {
+				a.apply(s.stream)
+				s.found = true
+			}
+		}
+	case "mp3":
+		if i := indexSync(s.pes, 0xff, 0xe0); i >= 0 {
+			if a, err := parseMPEGAudio(s.pes[i:]); err == nil {
+				a.apply(s.stream)
+				s.found = true
+			}
+		}
+	default:
+		s.found = true
+	}
+}
+
+// indexSync Returns the index of the first sync word `first`, followed by a byte
+// with the bits of `mask` set, or -1.
+func indexSync(data []byte, first, mask byte) int {
+	for i := 0; i+1 < len(data); i++ {
+		if data[i] == first && data[i+1]&mask == mask {
+			return i
+		}
+	}
+	return -1
+}
+
+// forEachNAL Calls `f` on each NAL unit of an Annex B byte stream until it returns false
+func forEachNAL(data []byte, f func(nal []byte) bool) {
+	start := -1
+	for i := 0; i+2 < len(data); i++ {
+		if data[i] != 0 || data[i+1] != 0 || data[i+2] != 1 {
+			continue
+		}
+		if start >= 0 && start < i {
+			end := i
+			for end > start && data[end-1] == 0 {
+				end-- // Leading zero of a 4-byte start code
+			}
+			if end > start && !f(data[start:end]) {
+				return
+			}
+		}
+		i += 2
+		start = i + 1
+	}
+	if start >= 0 && start < len(data) {
+		f(data[start:])
+	}
+}
+
+// mpegAudioSampleRates The sampling frequencies of MPEG-1 audio
+var mpegAudioSampleRates = []int{44100, 48000, 32000}
+
+// parseMPEGAudio Parses the header of an MPEG audio frame
+func parseMPEGAudio(data []byte) (*audioInfo, error) {
+	if len(data) < 4 || data[0] != 0xff || data[1]&0xe0 != 0xe0 {
+		return nil, errors.New("invalid MPEG audio header")
+	}
+	a := &audioInfo{codec: "mp3"}
+	switch data[1] >> 1 & 0x3 { // Layer
+	case 2:
+		a.codec = "mp2"
+	case 3:
+		a.codec = "mp1"
+	}
+	if index := int(data[2]>>2) & 0x3; index < len(mpegAudioSampleRates) {
+		a.sampleRate = mpegAudioSampleRates[index]
+		switch data[1] >> 3 & 0x3 { // Version
+		case 2: // MPEG-2
+			a.sampleRate /= 2
+		case 0: // MPEG-2.5
+			a.sampleRate /= 4
+		}
+	}
+	a.channels = 2
+	if data[3]>>6 == 3 {
+		a.channels = 1
+	}
+	return a, nil
+}
+
+// readTS Reads the transport stream packets of `r` between `start` and `end`,
+// aligned on the first sync byte.
+func readTS(r io.ReaderAt, start, end int64, handle func(pos int64, p tsPacket)) error {
+	data := make([]byte, end-start)
+	n, err := r.ReadAt(data, start)
+	if err != nil && err != io.EOF {
+		return err
+	}
+	data = data[:n]
+	offset := 0
+	for offset+tsPacketSize < len(data) && (data[offset] != tsSyncByte || data[offset+tsPacketSize] != tsSyncByte) {
+		offset++
+	}
+	for ; offset+tsPacketSize <= len(data); offset += tsPacketSize {
+		p, err := parseTSPacket(data[offset:])
+		if err != nil {
+			return err
+		}
+		handle(start+int64(offset), p)
+	}
+	return nil
+}
+
+// probeTS Reads the programs and streams of an MPEG-TS file of `size` bytes.
+func probeTS(r io.ReaderAt, size int64) (*ProbeData, error) {
+	d := newTSDemuxer()
+	head := size
+	if head > tsHeadLength {
+		head = tsHeadLength
+	}
+	if err := readTS(r, 0, head, func(_ int64, p tsPacket) { d.handle(p) }); err != nil {
+		return nil, err
+	}
+	if len(d.order) == 0 {
+		return nil, errors.New("no stream found in MPEG-TS")
+	}
+	for _, s := range d.order {
+		if !s.found {
+			s.parseHeaders()
+		}
+		s.pes = nil
+	}
+	if size > head {
+		// Only the timestamps of the end of the file are needed
+		tail := size - tsTailLength
+		if tail < head {
+			tail = head
+		}
+		if err := readTS(r, tail, size, func(_ int64, p tsPacket) { d.handle(p) }); err != nil {
+			return nil, err
+		}
+	}
+
+	data := &ProbeData{
+		Format: &ProbeFormat{
+			NBStreams:      len(d.order),
+			NBPrograms:     len(d.programs),
+			FormatName:     "mpegts",
+			FormatLongName: "MPEG-TS (MPEG-2 Transport Stream)",
+		},
+		Programs: d.programs,
+	}
+	var start, end int64 = -1, -1
+	for _, s := range d.order {
+		if s.firstPTS >= 0 {
+			s.stream.StartPts = int(s.firstPTS)
+			s.stream.StartTime = seconds(float64(s.firstPTS) / tsClock)
+			s.stream.DurationTs = uint64(s.lastPTS - s.firstPTS)
+			s.stream.Duration = float64(s.lastPTS-s.firstPTS) / tsClock
+			if start < 0 || s.firstPTS < start {
+				start = s.firstPTS
+			}
+			if s.lastPTS > end {
+				end = s.lastPTS
+			}
+		}
+		data.Streams = append(data.Streams, s.stream)
+	}
+	if start >= 0 {
+		data.Format.StartTimeSeconds = seconds(float64(start) / tsClock)
+		data.Format.DurationSeconds = seconds(float64(end-start) / tsClock)
+	}
+	return data, nil
+}
+
+// tsPackets Returns the packets of the first video stream of an MPEG-TS segment.
+// Packets whose PES header is flagged as a random access point, or that contain
+// an IDR picture, are key-frames.
+func tsPackets(ctx context.Context, r io.ReaderAt, size int64) ([]*ProbePacket, error) {
+	d := newTSDemuxer()
+	var video *tsStream
+	var packets []*ProbePacket
+	var current *ProbePacket
+	var es []byte
+	var dts []int64
+	closePacket := func() {
+		if current == nil {
+			return
+		}
+		current.Size = uint(len(es))
+		if current.Flags != "K_" && containsKeyFrame(video.stream.CodecName, es) {
+			current.Flags = "K_"
+		}
+		packets = append(packets, current)
+		current = nil
+	}
+	err := readTS(r, 0, size, func(pos int64, p tsPacket) {
+		if ctx.Err() != nil {
+			return
+		}
+		if video == nil {
+			d.handle(p)
+			for _, s := range d.order {
+				if s.stream.CodecType == "video" {
+					video = s
+					break
+				}
+			}
+			return
+		}
+		if p.pid != video.pid {
+			if _, ok := d.streams[p.pid]; !ok {
+				d.handle(p)
+			}
+			return
+		}
+		payload := p.payload
+		if p.payloadUnitStart {
+			h, ok := parsePESHeader(payload)
+			if !ok {
+				return
+			}
+			closePacket()
+			current = &ProbePacket{Pos: uint(pos), Flags: "__"}
+			if p.randomAccess {
+				current.Flags = "K_"
+			}
+			current.PtsTime = float64(h.pts) / tsClock
+			current.DtsTime = float64(h.dts) / tsClock
+			dts = append(dts, h.dts)
+			es = es[:0]
+			payload = payload[h.length:]
+		}
+		if current != nil {
+			es = append(es, payload...)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	if video == nil {
+		return nil, errors.New("no video stream found in MPEG-TS")
+	}
+	closePacket()
+	// A packet lasts until the next one is decoded
+	for i, p := range packets {
+		switch {
+		case i+1 < len(dts):
+			p.DurationTime = float64(dts[i+1]-dts[i]) / tsClock
+		case i > 0:
+			p.DurationTime = packets[i-1].DurationTime
+		}
+	}
+	return packets, nil
+}
+
+// containsKeyFrame Returns whether an H.264 or HEVC access unit contains an IRAP picture
+func containsKeyFrame(codec string, es []byte) bool {
+	key := false
+	forEachNAL(es, func(nal []byte) bool {
+		switch codec {
+		case "h264":
+			key = nal[0]&0x1f == 5
+		case "hevc":
+			nalType := nal[0] >> 1 & 0x3f
+			key = nalType >= 16 && nalType <= 21
+		}
+		return !key
+	})
+	return key
+}
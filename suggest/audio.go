@@ -8,6 +8,7 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/allezxandre/go-hls-encoder/codecs"
 	"github.com/allezxandre/go-hls-encoder/input"
 	"github.com/allezxandre/go-hls-encoder/probe"
 	jt_error "gitlab.com/joutube/joutube-server/jt-error"
@@ -43,27 +44,51 @@ const (
 )
 
 type AudioVariant struct {
-	MapInput        string           // The map value: in the form of $input:$stream
-	Codec           string           // Codec to use, or "copy". Required.
-	Type            AudioVariantType // Required (for naming purposes)
-	Bitrate         *string          // Optional
-	ConvertToStereo bool             // If true, this variant is downsampling Surround to Stereo
+	MapInput        string           `json:"map_input" yaml:"map_input"`                 // The map value: in the form of $input:$stream
+	Codec           string           `json:"codec" yaml:"codec"`                         // Codec to use, or "copy". Required.
+	Type            AudioVariantType `json:"type" yaml:"type"`                           // Required (for naming purposes)
+	Bitrate         *string          `json:"bitrate,omitempty" yaml:"bitrate,omitempty"` // Optional
+	ConvertToStereo bool             `json:"convert_to_stereo" yaml:"convert_to_stereo"` // If true, this variant is downsampling Surround to Stereo
 
 	// M3U8 Playlist options: https://tools.ietf.org/html/draft-pantos-http-live-streaming-23
-	GroupID        *string        // Optional group ID. "audio" will be used if `nil`
-	Name           string         // Unique name for variant. Required.
-	Language       input.Language // Primary language https://tools.ietf.org/html/rfc5646
-	DescribesVideo *bool
+	GroupID        *string        `json:"group_id,omitempty" yaml:"group_id,omitempty"` // Optional group ID. "audio" will be used if `nil`
+	Name           string         `json:"name" yaml:"name"`                             // Unique name for variant. Required.
+	Language       input.Language `json:"language" yaml:"language"`                     // Primary language https://tools.ietf.org/html/rfc5646
+	DescribesVideo *bool          `json:"describes_video,omitempty" yaml:"describes_video,omitempty"`
+	Codecs         string         `json:"codecs,omitempty" yaml:"codecs,omitempty"`         // RFC 6381 codec of the variant, e.g. "ec-3". Required for copied streams, see CodecString
+	Default        bool           `json:"default" yaml:"default"`                           // If true, players pick this variant unless the user prefers another language
+	Autoselect     *bool          `json:"autoselect,omitempty" yaml:"autoselect,omitempty"` // If false, players only play this variant if the user picks it. `nil` is true. See SelectRenditions
 }
 
 var DefaultAudioGroupID = "audio"
 
-func SuggestAudioVariants(probeDataInputs []*probe.ProbeData, createAlternateStereo bool, removeVFQ bool) (variants []AudioVariant) {
+// Group Returns the GROUP-ID of the variant
+func (v AudioVariant) Group() string {
+	if v.GroupID != nil {
+		return *v.GroupID
+	}
+	return DefaultAudioGroupID
+}
+
+// SuggestAudioVariants Suggests the audio variants of the inputs with the DefaultLanguagePolicy.
+// If `removeVFQ`, Québec French is dropped when another French is available. See SuggestAudio.
+func SuggestAudioVariants(probeDataInputs []*probe.ProbeData, createAlternateStereo bool, removeVFQ bool) []AudioVariant {
+	return SuggestAudio(probeDataInputs, createAlternateStereo, vfqPolicy(removeVFQ))
+}
+
+// SuggestAudio Suggests the audio variants of the inputs, kept, sorted and marked DEFAULT
+// according to `policy`. If `createAlternateStereo`, surround streams get a stereo variant too.
+func SuggestAudio(probeDataInputs []*probe.ProbeData, createAlternateStereo bool, policy LanguagePolicy) (variants []AudioVariant) {
 	for inputIndex, probeData := range probeDataInputs { // Loop through inputs
 		for streamIndex, stream := range probeData.Streams {
 			// Find tags
-			language := matchLanguage(stream)
+			streamLanguage := matchLanguage(stream)
 			mapInput := strconv.Itoa(inputIndex) + ":" + strconv.Itoa(streamIndex)
+			// Named after the language, e.g. "Deutsch (AAC Stereo)"
+			prefix := "Audio " + strconv.Itoa(streamIndex)
+			if streamLanguage != input.Unknown {
+				prefix = streamLanguage.Name()
+			}
 			if stream.CodecType == "audio" {
 				if stream.Channels <= 2 { // TODO: Handle Mono
 					audioType := StereoSound
@@ -74,8 +99,8 @@ func SuggestAudioVariants(probeDataInputs []*probe.ProbeData, createAlternateSte
 							MapInput:        mapInput,
 							Type:            audioType,
 							Codec:           "copy",
-							Name:            "Audio " + strconv.Itoa(streamIndex) + " (AAC Stereo)",
-							Language:        language,
+							Name:            prefix + " (AAC Stereo)",
+							Language:        streamLanguage,
 							ConvertToStereo: false,
 						})
 					default:
@@ -86,8 +111,8 @@ func SuggestAudioVariants(probeDataInputs []*probe.ProbeData, createAlternateSte
 							Type:            audioType,
 							Codec:           "aac",
 							Bitrate:         &bitrate,
-							Name:            "Audio " + strconv.Itoa(streamIndex),
-							Language:        language,
+							Name:            prefix,
+							Language:        streamLanguage,
 							ConvertToStereo: false,
 						})
 					} // end of switch on codec
@@ -105,8 +130,8 @@ func SuggestAudioVariants(probeDataInputs []*probe.ProbeData, createAlternateSte
 								MapInput:        mapInput,
 								Type:            audioType,
 								Codec:           "eac3", // Could copy, but encoding allows resampling of audio
-								Name:            fmt.Sprintf("Audio %d (%s Surround)", streamIndex, strings.ToUpper(stream.CodecName)),
-								Language:        language,
+								Name:            fmt.Sprintf("%s (%s Surround)", prefix, strings.ToUpper(stream.CodecName)),
+								Language:        streamLanguage,
 								ConvertToStereo: false,
 							})
 							if createAlternateStereo {
@@ -117,20 +142,24 @@ func SuggestAudioVariants(probeDataInputs []*probe.ProbeData, createAlternateSte
 									Type:            StereoSound,
 									Codec:           "aac",
 									Bitrate:         &bitrate,
-									Name:            "Audio " + strconv.Itoa(streamIndex) + " (AAC Stereo)",
-									Language:        language,
+									Name:            prefix + " (AAC Stereo)",
+									Language:        streamLanguage,
 									ConvertToStereo: true,
 								})
 							}
 						} else {
 							// we found the aac 2 channel stream, no need to convert
+							name := fmt.Sprintf("%s (%s Surround Version)", prefix, strings.ToUpper(stream.CodecName))
+							if streamLanguage == input.Unknown {
+								name = fmt.Sprintf("Audio %d&%d (%s Surround Version)", streamIndex, idx, strings.ToUpper(stream.CodecName))
+							}
 							// Copy Surround sound
 							variants = append(variants, AudioVariant{
 								MapInput:        mapInput,
 								Type:            audioType,
 								Codec:           "copy",
-								Name:            fmt.Sprintf("Audio %d&%d (%s Surround Version)", streamIndex, idx, strings.ToUpper(stream.CodecName)),
-								Language:        language,
+								Name:            name,
+								Language:        streamLanguage,
 								ConvertToStereo: false,
 							})
 							// AAC was copied already
@@ -145,8 +174,8 @@ func SuggestAudioVariants(probeDataInputs []*probe.ProbeData, createAlternateSte
 							Type:     audioType,
 							Codec:    "eac3",
 							Bitrate:  &bitrate1,
-							Name:     "Audio " + strconv.Itoa(streamIndex) + " (eAC3 Surround)",
-							Language: language,
+							Name:     prefix + " (eAC3 Surround)",
+							Language: streamLanguage,
 						})
 						if createAlternateStereo {
 							// Convert to AAC 2.0
@@ -156,8 +185,8 @@ func SuggestAudioVariants(probeDataInputs []*probe.ProbeData, createAlternateSte
 								Type:            StereoSound,
 								Codec:           "aac",
 								Bitrate:         &bitrate2,
-								Name:            "Audio " + strconv.Itoa(streamIndex) + " (AAC Stereo)",
-								Language:        language,
+								Name:            prefix + " (AAC Stereo)",
+								Language:        streamLanguage,
 								ConvertToStereo: true,
 							})
 						}
@@ -167,27 +196,57 @@ func SuggestAudioVariants(probeDataInputs []*probe.ProbeData, createAlternateSte
 			}
 		}
 	}
-	if removeVFQ {
-		variants = removeVFQAudio(variants)
+	for i, variant := range variants {
+		if variant.Codec == "copy" {
+			variants[i].Codecs = copiedAudioCodecs(variant.MapInput, probeDataInputs)
+		}
 	}
-	return
+	uniqueAudioNames(variants)
+	variants = policy.applyAudio(variants)
+	markDefaultAudio(variants, policy, probeDataInputs)
+	return variants
 }
 
-func removeVFQAudio(variants []AudioVariant) []AudioVariant {
-	// Check if French is present
-	hasFrench := false
-	filteredVariants := make([]AudioVariant, 0)
-	for _, variant := range variants {
-		hasFrench = hasFrench || variant.Language == input.FrenchLanguage || variant.Language == input.TrueFrench
-		if variant.Language != input.QuebecLanguage {
-			filteredVariants = append(filteredVariants, variant)
-		}
+// sourceStream Returns the stream at `mapInput`, or `nil` if unknown
+func sourceStream(mapInput string, probeDataInputs []*probe.ProbeData) *probe.ProbeStream {
+	var inputIndex, streamIndex int
+	if _, err := fmt.Sscanf(mapInput, "%d:%d", &inputIndex, &streamIndex); err != nil ||
+		inputIndex >= len(probeDataInputs) || streamIndex >= len(probeDataInputs[inputIndex].Streams) {
+		return nil
 	}
+	return probeDataInputs[inputIndex].Streams[streamIndex]
+}
 
-	if hasFrench {
-		return filteredVariants
-	} else {
-		// We don't remove VFQ because it is the only French
-		return variants
+// copiedAudioCodecs Returns the RFC 6381 codec of the stream at `mapInput`, or "" if unknown
+func copiedAudioCodecs(mapInput string, probeDataInputs []*probe.ProbeData) string {
+	stream := sourceStream(mapInput, probeDataInputs)
+	if stream == nil {
+		return ""
+	}
+	return copiedCodecs(stream)
+}
+
+// CodecString Returns the RFC 6381 codec of the variant: `Codecs` if set,
+// or the one of its encoder. Returns "" if unknown.
+func (v AudioVariant) CodecString() string {
+	if len(v.Codecs) > 0 || v.Codec == "copy" {
+		return v.Codecs
+	}
+	codec, _ := codecs.FromEncoder(v.Codec, "", "")
+	return codec
+}
+
+// uniqueAudioNames Numbers the variants that have the same name, e.g. two "English (AAC Stereo)"
+func uniqueAudioNames(variants []AudioVariant) {
+	count := map[string]int{}
+	for _, v := range variants {
+		count[v.Name]++
+	}
+	seen := map[string]int{}
+	for i, v := range variants {
+		if count[v.Name] > 1 {
+			seen[v.Name]++
+			variants[i].Name = fmt.Sprintf("%s %d", v.Name, seen[v.Name])
+		}
 	}
 }
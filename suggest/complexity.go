@@ -0,0 +1,154 @@
+package suggest
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"strconv"
+
+	"github.com/allezxandre/go-hls-encoder/probe"
+)
+
+const (
+	complexityReferenceHeight  = 540     // Height the samples are encoded at
+	complexityReferenceCRF     = 23      // CRF of the sample encodes: the quality to reach
+	complexityReferenceBitrate = 1200000 // Bitrate of the sample encodes for average content, at 30 fps
+	complexitySamples          = 3       // Number of samples spread over the video
+	complexitySampleSeconds    = 10.0    // Maximum duration of a sample
+)
+
+// VideoComplexity The result of the complexity analysis of a video stream
+type VideoComplexity struct {
+	ReferenceHeight int     `json:"reference_height" yaml:"reference_height"` // Height the samples were encoded at
+	ReferenceCRF    int     `json:"reference_crf" yaml:"reference_crf"`       // CRF the samples were encoded with
+	SampledSeconds  float64 `json:"sampled_seconds" yaml:"sampled_seconds"`   // Total duration of the samples
+	MeasuredBitrate int     `json:"measured_bitrate" yaml:"measured_bitrate"` // Bitrate of the samples, in bits/s
+	Factor          float64 `json:"factor" yaml:"factor"`                     // Measured bitrate relative to average content. Below 1 for simple content
+}
+
+// RungAnalysis Explains how the bitrate of a rendition was chosen
+type RungAnalysis struct {
+	Complexity    VideoComplexity `json:"complexity" yaml:"complexity"`
+	LadderBitrate int             `json:"ladder_bitrate" yaml:"ladder_bitrate"` // Bitrate of the rung of the ladder
+	NeededBitrate int             `json:"needed_bitrate" yaml:"needed_bitrate"` // Estimated bitrate to reach the reference quality at this resolution
+	Reason        string          `json:"reason" yaml:"reason"`
+}
+
+// AnalyzeComplexity Encodes a few samples of `videoStream`, read from `inputURL`, at a constant
+// quality to measure how many bits the video needs. `durationSeconds` is the duration
+// of the input, used to spread the samples. 0 if unknown.
+func AnalyzeComplexity(inputURL string, videoStream *probe.ProbeStream, durationSeconds float64) (*VideoComplexity, error) {
+	height := complexityReferenceHeight
+	if videoStream.Height > 0 && videoStream.Height < height {
+		height = videoStream.Height
+	}
+	// Spread the samples over the video, avoiding the very beginning and end
+	samples := []float64{0}
+	sampleDuration := 2 * complexitySampleSeconds
+	if durationSeconds > 0 {
+		sampleDuration = math.Min(complexitySampleSeconds, durationSeconds/complexitySamples)
+		samples = samples[:0]
+		for i := 0; i < complexitySamples; i++ {
+			position := durationSeconds * float64(i+1) / (complexitySamples + 1)
+			samples = append(samples, math.Max(0, position-sampleDuration/2))
+		}
+	}
+	var totalSize int64
+	for _, start := range samples {
+		log.Printf("Encoding a %.0fs complexity sample at %.0fs", sampleDuration, start)
+		size, err := probe.EncodedSize(inputURL, videoStream.Index, start, sampleDuration, height, complexityReferenceCRF)
+		if err != nil {
+			return nil, err
+		}
+		totalSize += size
+	}
+	sampledSeconds := sampleDuration * float64(len(samples))
+	measured := int(float64(totalSize*8) / sampledSeconds)
+	return &VideoComplexity{
+		ReferenceHeight: height,
+		ReferenceCRF:    complexityReferenceCRF,
+		SampledSeconds:  sampledSeconds,
+		MeasuredBitrate: measured,
+		Factor:          float64(measured) / expectedBitrate(height, videoStream.FrameRate()),
+	}, nil
+}
+
+// expectedBitrate Returns the bitrate of the reference encode of average content
+// at the given `height` and `frameRate`.
+func expectedBitrate(height int, frameRate float64) float64 {
+	bitrate := complexityReferenceBitrate * scaleBitrate(height, complexityReferenceHeight)
+	if frameRate > highFrameRate {
+		bitrate *= 1.5
+	}
+	return bitrate
+}
+
+// scaleBitrate Returns the factor to apply to a bitrate at `fromHeight` to get
+// the same quality at `toHeight`. Bitrates do not grow as fast as the number of pixels.
+func scaleBitrate(toHeight, fromHeight int) float64 {
+	return math.Pow(float64(toHeight)/float64(fromHeight), 1.5)
+}
+
+// neededBitrate Returns the estimated bitrate to reach the reference quality at `height`
+func (c *VideoComplexity) neededBitrate(height int) int {
+	return int(float64(c.MeasuredBitrate) * scaleBitrate(height, c.ReferenceHeight))
+}
+
+// rungSpacing Returns the minimum ratio between the bitrates of two consecutive
+// rungs. Simple content gets fewer rungs.
+func (c *VideoComplexity) rungSpacing() float64 {
+	if c.Factor < 0.75 {
+		return 2
+	}
+	return 1.5
+}
+
+// perTitleBitrate Returns the bitrate of a rung at `height` for content of complexity `c`,
+// given the bitrate of the ladder, along with its analysis.
+func (c *VideoComplexity) perTitleBitrate(height, ladderBitrate int) (int, *RungAnalysis) {
+	analysis := &RungAnalysis{
+		Complexity:    *c,
+		LadderBitrate: ladderBitrate,
+		NeededBitrate: c.neededBitrate(height),
+	}
+	// Complex content gets up to twice the bitrate of the ladder
+	boost := math.Max(1, math.Min(2, c.Factor))
+	bitrate := int(float64(ladderBitrate) * boost)
+	switch {
+	case analysis.NeededBitrate < ladderBitrate/4:
+		bitrate = ladderBitrate / 4
+		analysis.Reason = "simple content: a quarter of the ladder bitrate"
+	case analysis.NeededBitrate < bitrate:
+		bitrate = analysis.NeededBitrate
+		analysis.Reason = fmt.Sprintf("bitrate to reach CRF %d quality", c.ReferenceCRF)
+	case boost > 1:
+		analysis.Reason = fmt.Sprintf("complex content: ladder bitrate raised by %.2fx", boost)
+	default:
+		analysis.Reason = "ladder bitrate"
+	}
+	return bitrate, analysis
+}
+
+// SuggestPerTitleVideo Suggests the same variants as SuggestVideo, but the bitrates
+// and number of the renditions of the ladder depend on the complexity of each video,
+// measured by AnalyzeComplexity.
+func SuggestPerTitleVideo(inputURLs []string, probeDataInputs []*probe.ProbeData, options VideoOptions) ([]VideoVariant, error) {
+	analyses := make([]*VideoComplexity, len(probeDataInputs))
+	for inputIndex, probeData := range probeDataInputs {
+		masterVideoIndex, err := masterVideo(probeData.Streams)
+		if err != nil {
+			continue
+		}
+		var duration float64
+		if probeData.Format != nil {
+			duration, _ = strconv.ParseFloat(probeData.Format.DurationSeconds, 64)
+		}
+		analyses[inputIndex], err = AnalyzeComplexity(inputURLs[inputIndex], probeData.Streams[masterVideoIndex], duration)
+		if err != nil {
+			return nil, err
+		}
+		log.Printf("Complexity of %s: %.2f (%d bits/s at %dp)", inputURLs[inputIndex], analyses[inputIndex].Factor,
+			analyses[inputIndex].MeasuredBitrate, analyses[inputIndex].ReferenceHeight)
+	}
+	return suggestVideo(probeDataInputs, options, analyses), nil
+}
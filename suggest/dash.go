@@ -0,0 +1,119 @@
+package suggest
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/allezxandre/go-hls-encoder/dash"
+	"github.com/allezxandre/go-hls-encoder/input"
+)
+
+// DASHAdaptationSet Returns the adaptation set the variant belongs to in a DASH manifest, without its representations.
+// Players only switch between representations of the same codec and dynamic range.
+func (v VideoVariant) DASHAdaptationSet(id int) dash.AdaptationSet {
+	set := dash.AdaptationSet{
+		ID:               id,
+		ContentType:      "video",
+		MimeType:         "video/mp4",
+		SegmentAlignment: true,
+		StartWithSAP:     1,
+	}
+	// SDR players skip HDR sets, see ISO/IEC 23091-2
+	switch v.VideoRange {
+	case "PQ":
+		set.EssentialProperties = append(set.EssentialProperties, dash.Descriptor{SchemeIDURI: dash.TransferCharacteristicsURI, Value: "16"})
+	case "HLG":
+		set.EssentialProperties = append(set.EssentialProperties, dash.Descriptor{SchemeIDURI: dash.TransferCharacteristicsURI, Value: "18"})
+	}
+	return set
+}
+
+// DASHRepresentation Returns the representation of the variant in a DASH manifest, without its segments.
+// `codecs` is the codec of the video alone, see CodecString. `measured` is the bandwidth of the encoded video.
+func (v VideoVariant) DASHRepresentation(id string, codecs string, measured MeasuredBandwidth) dash.Representation {
+	representation := dash.Representation{
+		ID:        id,
+		Bandwidth: measured.Peak,
+		Codecs:    codecs,
+		FrameRate: v.FrameRate,
+	}
+	if parts := strings.SplitN(v.Resolution, "x", 2); len(parts) == 2 {
+		representation.Width, _ = strconv.Atoi(parts[0])
+		representation.Height, _ = strconv.Atoi(parts[1])
+	}
+	return representation
+}
+
+// DASHAdaptationSet Returns the adaptation set of the variant in a DASH manifest, with its only representation,
+// without its segments. `measured` is the bandwidth of the encoded audio.
+func (v AudioVariant) DASHAdaptationSet(id int, representationID string, measured MeasuredBandwidth) dash.AdaptationSet {
+	set := dash.AdaptationSet{
+		ID:               id,
+		ContentType:      "audio",
+		MimeType:         "audio/mp4",
+		SegmentAlignment: true,
+		StartWithSAP:     1,
+		Label:            v.Name,
+		Roles:            []dash.Descriptor{dashRole(v.Default)},
+	}
+	if v.Language != input.Unknown {
+		set.Lang = string(v.Language)
+	}
+	if v.DescribesVideo != nil && *v.DescribesVideo {
+		set.Roles = append(set.Roles, dash.Descriptor{SchemeIDURI: dash.RoleScheme, Value: "description"})
+	}
+	representation := dash.Representation{
+		ID:        representationID,
+		Bandwidth: measured.Peak,
+		Codecs:    v.CodecString(),
+	}
+	switch v.Type {
+	case SurroundSound, StereoSound:
+		representation.AudioChannelConfiguration = []dash.Descriptor{
+			{SchemeIDURI: dash.AudioChannelScheme, Value: strconv.Itoa(int(v.Type))},
+		}
+	}
+	set.Representations = []dash.Representation{representation}
+	return set
+}
+
+// DASHAdaptationSet Returns the adaptation set of the variant in a DASH manifest: its subtitles
+// in the single WebVTT file at `url`. `bandwidth` is the one of the file, in bits/s.
+func (v SubtitleVariant) DASHAdaptationSet(id int, representationID, url string, bandwidth int) dash.AdaptationSet {
+	label := v.Name
+	if len(v.DisplayName) > 0 {
+		label = v.DisplayName
+	}
+	set := dash.AdaptationSet{
+		ID:          id,
+		ContentType: "text",
+		MimeType:    "text/vtt",
+		Label:       label,
+		Roles:       []dash.Descriptor{dashRole(v.Default)},
+	}
+	if v.Language != input.Unknown {
+		set.Lang = string(v.Language)
+	}
+	// Roles of ISO/IEC 23009-1 and of the DASH-IF interoperability points
+	switch {
+	case v.Forced:
+		set.Roles = append(set.Roles, dash.Descriptor{SchemeIDURI: dash.RoleScheme, Value: "forced-subtitle"})
+	case v.HearingImpaired:
+		set.Roles = append(set.Roles, dash.Descriptor{SchemeIDURI: dash.RoleScheme, Value: "caption"})
+	default:
+		set.Roles = append(set.Roles, dash.Descriptor{SchemeIDURI: dash.RoleScheme, Value: "subtitle"})
+	}
+	if bandwidth < 1 {
+		bandwidth = 1
+	}
+	set.Representations = []dash.Representation{{ID: representationID, Bandwidth: bandwidth, BaseURL: url}}
+	return set
+}
+
+// dashRole Returns the "main" role of DEFAULT renditions, or the "alternate" one
+func dashRole(isDefault bool) dash.Descriptor {
+	if isDefault {
+		return dash.Descriptor{SchemeIDURI: dash.RoleScheme, Value: "main"}
+	}
+	return dash.Descriptor{SchemeIDURI: dash.RoleScheme, Value: "alternate"}
+}
@@ -0,0 +1,136 @@
+package suggest
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/allezxandre/go-hls-encoder/codecs"
+	"github.com/allezxandre/go-hls-encoder/probe"
+)
+
+// DynamicRange The dynamic range of a video stream
+type DynamicRange string
+
+const (
+	SDR         DynamicRange = "SDR"
+	HDR10       DynamicRange = "HDR10"        // PQ transfer, with static metadata
+	HLG         DynamicRange = "HLG"          // Hybrid log-gamma
+	DolbyVision DynamicRange = "dolby-vision" // Dolby Vision, with its dynamic metadata
+	// The base layer of Dolby Vision streams without a backward compatible one, e.g. profile 5:
+	// its colors are IPTPQc2, which only Dolby Vision decoders convert
+	DolbyVisionOnly DynamicRange = "dolby-vision-only"
+)
+
+// ClassifyDynamicRange Returns the dynamic range of `videoStream`, from its Dolby Vision
+// configuration record and its transfer function
+func ClassifyDynamicRange(videoStream *probe.ProbeStream) DynamicRange {
+	if videoStream.DOVIConfiguration() != nil {
+		return DolbyVision
+	}
+	switch videoStream.ColorTransfer {
+	case "smpte2084":
+		return HDR10
+	case "arib-std-b67":
+		return HLG
+	default:
+		return SDR
+	}
+}
+
+// baseLayerRange Returns the dynamic range of `videoStream` for players that ignore
+// Dolby Vision metadata. It is the range kept when the stream is converted.
+func baseLayerRange(videoStream *probe.ProbeStream) DynamicRange {
+	dovi := videoStream.DOVIConfiguration()
+	if dovi == nil {
+		return ClassifyDynamicRange(videoStream)
+	}
+	switch dovi.DVBLSignalCompatibilityID {
+	case codecs.DVCompatibilitySDR:
+		return SDR
+	case codecs.DVCompatibilityHLG:
+		return HLG
+	case codecs.DVCompatibilityNone:
+		return DolbyVisionOnly
+	}
+	return HDR10
+}
+
+// VideoRange Returns the VIDEO-RANGE of streams with this dynamic range.
+// Dolby Vision streams should be announced with the range of their base layer instead.
+func (r DynamicRange) VideoRange() string {
+	switch r {
+	case HDR10, DolbyVision, DolbyVisionOnly:
+		return "PQ"
+	case HLG:
+		return "HLG"
+	default:
+		return "SDR"
+	}
+}
+
+// applyDolbyVision Sets the codecs and tag of `variant`, copying the Dolby Vision stream `videoStream`.
+// Streams that HLS cannot carry with their metadata, such as dual-layer profile 7, are copied as HDR10.
+func applyDolbyVision(variant *VideoVariant, videoStream *probe.ProbeStream) {
+	codec, supplemental, err := codecs.FromDOVI(videoStream)
+	dovi := videoStream.DOVIConfiguration()
+	if err != nil || dovi.DVProfile == 7 || dovi.ELPresentFlag != 0 {
+		log.Printf("WARNING: Dolby Vision profile %d is not supported by HLS. Copying stream %d without its metadata",
+			dovi.DVProfile, videoStream.Index)
+		variant.DynamicRange = baseLayerRange(videoStream)
+		return
+	}
+	variant.DynamicRange = DolbyVision
+	variant.Codecs, variant.SupplementalCodecs = codec, supplemental
+	if len(supplemental) == 0 {
+		// Not backward compatible: announced with a Dolby Vision sample entry
+		variant.AddHVC1Tag = false
+		variant.CodecTag = "dvh1"
+	}
+}
+
+// DefaultToneMapOperator The tone-mapping operator of DefaultVideoOptions
+const DefaultToneMapOperator = "hable"
+
+// ToneMapOperators The algorithms of ffmpeg's tonemap filter
+var ToneMapOperators = []string{"none", "clip", "linear", "gamma", "reinhard", "hable", "mobius"}
+
+// ToneMapping How an encoded variant converts its HDR source to SDR
+type ToneMapping struct {
+	Transfer string `json:"transfer" yaml:"transfer"` // Transfer function of the source: "smpte2084" (PQ) or "arib-std-b67" (HLG)
+	Operator string `json:"operator" yaml:"operator"` // Algorithm of ffmpeg's tonemap filter, e.g. "hable"
+}
+
+// Validate Returns an error if the tone mapping is invalid
+func (t ToneMapping) Validate() error {
+	switch t.Transfer {
+	case "smpte2084", "arib-std-b67":
+	default:
+		return fmt.Errorf("cannot tone-map transfer function %q", t.Transfer)
+	}
+	return validateToneMapOperator(t.Operator)
+}
+
+// validateToneMapOperator Returns an error if `operator` is not an algorithm of ffmpeg's tonemap filter
+func validateToneMapOperator(operator string) error {
+	for _, o := range ToneMapOperators {
+		if o == operator {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown tone-mapping operator %q", operator)
+}
+
+// toneMapping Returns the tone mapping to SDR of streams with the dynamic range `baseRange`,
+// using `operator`. Returns `nil` for SDR and DolbyVisionOnly streams, or if `operator` is empty.
+func toneMapping(baseRange DynamicRange, operator string) *ToneMapping {
+	if len(operator) == 0 {
+		return nil
+	}
+	switch baseRange {
+	case HDR10:
+		return &ToneMapping{Transfer: "smpte2084", Operator: operator}
+	case HLG:
+		return &ToneMapping{Transfer: "arib-std-b67", Operator: operator}
+	}
+	return nil
+}
@@ -0,0 +1,197 @@
+package suggest
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+
+	"github.com/allezxandre/go-hls-encoder/probe"
+	"gopkg.in/yaml.v2"
+)
+
+// Rung One rendition of a bitrate ladder
+type Rung struct {
+	Height  int    `json:"height" yaml:"height"`                   // Maximum height of the rendition
+	Bitrate int    `json:"bitrate" yaml:"bitrate"`                 // Average video bitrate at 30 fps or less, in bits/s
+	Profile string `json:"profile" yaml:"profile"`                 // H.264 profile: "baseline", "main" or "high"
+	Level   string `json:"level,omitempty" yaml:"level,omitempty"` // Minimum H.264 level. Raised if the rendition requires it
+}
+
+// Ladder The H.264 renditions to encode below the resolution of the source,
+// from the highest to the lowest.
+type Ladder []Rung
+
+// TN2224Ladder Returns the ladder recommended by Apple's Technical Note TN2224
+func TN2224Ladder() Ladder {
+	return Ladder{
+		{Height: 1080, Bitrate: 6000000, Profile: "high", Level: "4.0"},
+		{Height: 720, Bitrate: 3000000, Profile: "main", Level: "3.1"},
+		{Height: 540, Bitrate: 2000000, Profile: "main", Level: "3.1"},
+		{Height: 360, Bitrate: 365000, Profile: "baseline", Level: "3.0"},
+		{Height: 234, Bitrate: 145000, Profile: "baseline", Level: "3.0"},
+	}
+}
+
+// DefaultLadder The ladder used by SuggestVideoVariants
+var DefaultLadder = TN2224Ladder()
+
+// LoadLadder Reads a ladder from a JSON or YAML file, as a list of rungs
+func LoadLadder(filename string) (Ladder, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var ladder Ladder
+	// YAML being a superset of JSON, one decoder reads both
+	decoder := yaml.NewDecoder(f)
+	decoder.SetStrict(true)
+	if err := decoder.Decode(&ladder); err != nil {
+		return nil, err
+	}
+	return ladder, ladder.Validate()
+}
+
+// Validate Returns an error describing the first invalid rung, if any
+func (l Ladder) Validate() error {
+	for i, rung := range l {
+		if rung.Height <= 0 || rung.Bitrate <= 0 {
+			return fmt.Errorf("rung %d: invalid height or bitrate", i)
+		}
+		switch rung.Profile {
+		case "baseline", "main", "high":
+		default:
+			return fmt.Errorf("rung %d: unsupported profile %q", i, rung.Profile)
+		}
+		if len(rung.Level) > 0 && levelIndex(rung.Level) < 0 {
+			return fmt.Errorf("rung %d: unknown level %q", i, rung.Level)
+		}
+	}
+	return nil
+}
+
+// highFrameRate Above this frame rate, rungs bitrates are increased by half
+const highFrameRate = 30.5
+
+// ladderVariants Returns the variants of `ladder` that are strictly smaller than `topHeight`,
+// for the video stream `videoStream` mapped as `mapInput`.
+// `sourceBitrate` caps the bitrate of the renditions, if known.
+// If the `complexity` of the video is known, bitrates are adapted to it,
+// and rungs too close to the previous one are dropped.
+func ladderVariants(ladder Ladder, mapInput string, videoStream *probe.ProbeStream, topHeight, sourceBitrate int,
+	complexity *VideoComplexity) (variants []VideoVariant) {
+	frameRate := videoStream.FrameRate()
+	if frameRate <= 0 {
+		frameRate = 30
+	}
+	for _, rung := range ladder {
+		if rung.Height >= topHeight {
+			continue
+		}
+		width, height := computeNewRatio(videoStream, rung.Height)
+		width -= width % 2 // As the scale filter does
+		bitrate := rung.Bitrate
+		if frameRate > highFrameRate {
+			bitrate = bitrate * 3 / 2
+		}
+		var analysis *RungAnalysis
+		if complexity != nil {
+			bitrate, analysis = complexity.perTitleBitrate(height, bitrate)
+		}
+		if sourceBitrate > 0 && bitrate > sourceBitrate {
+			bitrate = sourceBitrate
+			if analysis != nil {
+				analysis.Reason = "capped by the source bitrate"
+			}
+		}
+		if complexity != nil && len(variants) > 0 {
+			previous, _ := strconv.Atoi(*variants[len(variants)-1].Bitrate)
+			if float64(previous) < complexity.rungSpacing()*float64(bitrate) {
+				log.Printf("Skipping rung %dp: %d bits/s is too close to the previous rung", rung.Height, bitrate)
+				continue
+			}
+		}
+		maxBitrate := bitrate * 11 / 10 // Peak bitrate within 110% of the average
+		profile := rung.Profile
+		level := h264Level(width, height, frameRate, maxBitrate, rung.Profile, rung.Level)
+		bitrateS := strconv.Itoa(bitrate)
+		maxBitrateS := strconv.Itoa(maxBitrate)
+		bufferSize := strconv.Itoa(2 * bitrate)
+		variants = append(variants, VideoVariant{
+			MapInput:         mapInput,
+			Codec:            "libx264",
+			Profile:          &profile,
+			Level:            &level,
+			Bitrate:          &bitrateS,
+			MaxBitrate:       &maxBitrateS,
+			BufferSize:       &bufferSize,
+			FrameRate:        sourceFrameRate(videoStream),
+			ResolutionHeight: &height,
+			Resolution:       strconv.Itoa(width) + "x" + strconv.Itoa(height),
+			Bandwidth:        maxBitrateS,
+			Analysis:         analysis,
+		})
+	}
+	return
+}
+
+// h264Levels Limits of the H.264 levels, from Table A-1 of the specification.
+var h264Levels = []struct {
+	name    string
+	maxMBPS int // Macroblocks per second
+	maxFS   int // Macroblocks per frame
+	maxBR   int // Bits per second, for the baseline & main profiles
+}{
+	{"1", 1485, 99, 64000},
+	{"1.1", 3000, 396, 192000},
+	{"1.2", 6000, 396, 384000},
+	{"1.3", 11880, 396, 768000},
+	{"2", 11880, 396, 2000000},
+	{"2.1", 19800, 792, 4000000},
+	{"2.2", 20250, 1620, 4000000},
+	{"3.0", 40500, 1620, 10000000},
+	{"3.1", 108000, 3600, 14000000},
+	{"3.2", 216000, 5120, 20000000},
+	{"4.0", 245760, 8192, 20000000},
+	{"4.1", 245760, 8192, 50000000},
+	{"4.2", 522240, 8704, 50000000},
+	{"5.0", 589824, 22080, 135000000},
+	{"5.1", 983040, 36864, 240000000},
+	{"5.2", 2073600, 36864, 240000000},
+}
+
+// levelIndex Returns the index of `level` in h264Levels, or -1
+func levelIndex(level string) int {
+	value, err := strconv.ParseFloat(level, 64)
+	if err != nil {
+		return -1
+	}
+	for i, l := range h264Levels {
+		if lValue, _ := strconv.ParseFloat(l.name, 64); lValue == value {
+			return i
+		}
+	}
+	return -1
+}
+
+// h264Level Returns the lowest H.264 level, not lower than `minimumLevel`,
+// that allows encoding a `width`x`height` stream at `frameRate` and `maxBitrate`.
+func h264Level(width, height int, frameRate float64, maxBitrate int, profile, minimumLevel string) string {
+	frameSize := ((width + 15) / 16) * ((height + 15) / 16)
+	mbps := int(float64(frameSize) * frameRate)
+	brFactor := 1.0
+	if profile == "high" {
+		brFactor = 1.25
+	}
+	start := levelIndex(minimumLevel)
+	if start < 0 {
+		start = 0
+	}
+	for _, l := range h264Levels[start:] {
+		if frameSize <= l.maxFS && mbps <= l.maxMBPS && float64(maxBitrate) <= brFactor*float64(l.maxBR) {
+			return l.name
+		}
+	}
+	return h264Levels[len(h264Levels)-1].name
+}
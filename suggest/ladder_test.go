@@ -0,0 +1,79 @@
+package suggest
+
+import (
+	"testing"
+
+	"github.com/allezxandre/go-hls-encoder/probe"
+)
+
+func TestSuggestVideoLadder(t *testing.T) {
+	probeData := &probe.ProbeData{Streams: []*probe.ProbeStream{{
+		Index:              0,
+		CodecName:          "h264",
+		CodecType:          "video",
+		Width:              1920,
+		Height:             1080,
+		DisplayAspectRatio: "16:9",
+		AvgFrameRate:       "24000/1001",
+		BitRate:            2500000,
+	}}}
+	variants := SuggestVideoLadder([]*probe.ProbeData{probeData}, TN2224Ladder())
+	if len(variants) != 5 {
+		t.Fatal("Unexpected number of variants:", len(variants))
+	}
+	if variants[0].Codec != "copy" {
+		t.Error("Top variant is not copied:", variants[0].Codec)
+	}
+	for _, expected := range []struct {
+		resolution, profile, level, bitrate string
+	}{
+		{"1280x720", "main", "3.1", "2500000"}, // Capped by the source bitrate
+		{"960x540", "main", "3.1", "2000000"},
+		{"640x360", "baseline", "3.0", "365000"},
+		{"416x234", "baseline", "3.0", "145000"},
+	} {
+		variants = variants[1:]
+		v := variants[0]
+		if v.Resolution != expected.resolution || *v.Profile != expected.profile ||
+			*v.Level != expected.level || *v.Bitrate != expected.bitrate {
+			t.Errorf("Unexpected rung %s %s %s %s instead of %+v",
+				v.Resolution, *v.Profile, *v.Level, *v.Bitrate, expected)
+		}
+	}
+}
+
+func TestH264Level(t *testing.T) {
+	if level := h264Level(1920, 1080, 60, 9900000, "high", "4.0"); level != "4.2" {
+		t.Error("Unexpected level for 1080p60:", level)
+	}
+	if level := h264Level(1280, 720, 25, 3300000, "main", ""); level != "3.1" {
+		t.Error("Unexpected level for 720p25:", level)
+	}
+}
+
+func TestPerTitleLadder(t *testing.T) {
+	videoStream := &probe.ProbeStream{
+		Index:              0,
+		CodecName:          "h264",
+		CodecType:          "video",
+		Width:              1920,
+		Height:             1080,
+		DisplayAspectRatio: "16:9",
+		AvgFrameRate:       "25/1",
+	}
+	simple := &VideoComplexity{ReferenceHeight: 540, ReferenceCRF: 23, MeasuredBitrate: 400000, Factor: 1. / 3}
+	complex := &VideoComplexity{ReferenceHeight: 540, ReferenceCRF: 23, MeasuredBitrate: 3000000, Factor: 2.5}
+	lean := ladderVariants(TN2224Ladder(), "0:0", videoStream, 1080, 0, simple)
+	full := ladderVariants(TN2224Ladder(), "0:0", videoStream, 1080, 0, complex)
+	if len(lean) >= len(full) {
+		t.Errorf("Simple content has %d renditions, complex content %d", len(lean), len(full))
+	}
+	if *full[0].Bitrate != "4618802" || full[0].Analysis == nil || full[0].Analysis.LadderBitrate != 3000000 {
+		t.Errorf("Unexpected top rendition for complex content: %s %+v", *full[0].Bitrate, full[0].Analysis)
+	}
+	for _, v := range lean {
+		if v.Analysis == nil || len(v.Analysis.Reason) == 0 {
+			t.Error("Missing analysis for rendition", v.Resolution)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package suggest
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/allezxandre/go-hls-encoder/input"
+	"github.com/allezxandre/go-hls-encoder/language"
+)
+
+// LanguagePolicy Which audio and subtitle languages to keep, in which order, and which one is DEFAULT.
+// It is applied the same way to the audio and subtitle variants.
+type LanguagePolicy struct {
+	// Wanted audio languages, in priority order, e.g. ["fr", "en"].
+	// A language also matches its dialects: "fr" matches "fr-CA".
+	Audio []input.Language `json:"audio,omitempty" yaml:"audio,omitempty"`
+	// Wanted subtitle languages, in priority order.
+	// Those missing from the inputs are looked for with the additional searcher.
+	Subtitles []input.Language `json:"subtitles,omitempty" yaml:"subtitles,omitempty"`
+	// If true, the languages that are not wanted are dropped, unless no variant would be left.
+	// Otherwise they are kept after the wanted ones.
+	Only bool `json:"only" yaml:"only"`
+	// Dialects to drop when another dialect of their language is kept, e.g. "fr-CA"
+	// when there is "fr" or "fr-FR" too.
+	DropDialects []input.Language `json:"drop_dialects,omitempty" yaml:"drop_dialects,omitempty"`
+	// Language of the DEFAULT audio. Unknown to use the default stream of the source. See SelectRenditions.
+	Default input.Language `json:"default,omitempty" yaml:"default,omitempty"`
+}
+
+// DefaultLanguagePolicy Returns the policy used when none is given: every language is kept,
+// and English and French subtitles are looked for
+func DefaultLanguagePolicy() LanguagePolicy {
+	return LanguagePolicy{
+		Subtitles: []input.Language{language.English, language.French},
+	}
+}
+
+// vfqPolicy Returns the DefaultLanguagePolicy, dropping Québec French if `removeVFQ`
+func vfqPolicy(removeVFQ bool) LanguagePolicy {
+	policy := DefaultLanguagePolicy()
+	if removeVFQ {
+		policy.DropDialects = []input.Language{language.CanadianFrench}
+	}
+	return policy
+}
+
+// Validate Checks that all the languages of the policy are valid tags
+func (p LanguagePolicy) Validate() error {
+	all := append(append(append([]input.Language{}, p.Audio...), p.Subtitles...), p.DropDialects...)
+	for _, lang := range append(all, p.Default) {
+		if _, err := language.Parse(string(lang)); err != nil {
+			return fmt.Errorf("%v %q", err, lang)
+		}
+	}
+	for _, dialect := range p.DropDialects {
+		if dialect == dialect.Base() {
+			return fmt.Errorf("%q is not a dialect", dialect)
+		}
+	}
+	return nil
+}
+
+// apply Returns the indexes of `languages` to keep, sorted by their priority in `wanted`.
+// Variants of the same priority keep their order.
+func (p LanguagePolicy) apply(languages []input.Language, wanted []input.Language) []int {
+	var kept []int
+	for i, lang := range languages {
+		if !p.dropsDialect(lang, languages) {
+			kept = append(kept, i)
+		}
+	}
+	if p.Only && len(wanted) > 0 {
+		var only []int
+		for _, i := range kept {
+			if rank(languages[i], wanted) < len(wanted) {
+				only = append(only, i)
+			}
+		}
+		if len(only) > 0 {
+			kept = only
+		}
+	}
+	sort.SliceStable(kept, func(a, b int) bool {
+		return rank(languages[kept[a]], wanted) < rank(languages[kept[b]], wanted)
+	})
+	return kept
+}
+
+// dropsDialect Returns true if `lang` is one of the dialects to drop
+// and another dialect of its language is in `languages`
+func (p LanguagePolicy) dropsDialect(lang input.Language, languages []input.Language) bool {
+	for _, dialect := range p.DropDialects {
+		if !lang.Matches(dialect) {
+			continue
+		}
+		for _, other := range languages {
+			if other.Matches(dialect.Base()) && !other.Matches(dialect) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// rank Returns the priority of `lang` in `wanted`, or len(wanted) if it is not wanted
+func rank(lang input.Language, wanted []input.Language) int {
+	for i, w := range wanted {
+		if lang.Matches(w) {
+			return i
+		}
+	}
+	return len(wanted)
+}
+
+// applyAudio Keeps and sorts the audio variants according to the policy
+func (p LanguagePolicy) applyAudio(variants []AudioVariant) []AudioVariant {
+	languages := make([]input.Language, len(variants))
+	for i, v := range variants {
+		languages[i] = v.Language
+	}
+	kept := make([]AudioVariant, 0, len(variants))
+	for _, i := range p.apply(languages, p.Audio) {
+		kept = append(kept, variants[i])
+	}
+	return kept
+}
+
+// applySubtitles Keeps and sorts the subtitle variants according to the policy
+func (p LanguagePolicy) applySubtitles(variants []SubtitleVariant) []SubtitleVariant {
+	languages := make([]input.Language, len(variants))
+	for i, v := range variants {
+		languages[i] = v.Language
+	}
+	kept := make([]SubtitleVariant, 0, len(variants))
+	for _, i := range p.apply(languages, p.Subtitles) {
+		kept = append(kept, variants[i])
+	}
+	return kept
+}
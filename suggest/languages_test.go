@@ -0,0 +1,86 @@
+package suggest
+
+import (
+	"testing"
+
+	"github.com/allezxandre/go-hls-encoder/input"
+	"github.com/allezxandre/go-hls-encoder/language"
+	"github.com/allezxandre/go-hls-encoder/probe"
+)
+
+func TestLanguagePolicy(t *testing.T) {
+	audio := func(languages ...input.Language) (variants []AudioVariant) {
+		for _, l := range languages {
+			variants = append(variants, AudioVariant{Name: string(l), Language: l})
+		}
+		return
+	}
+	names := func(variants []AudioVariant) (s []string) {
+		for _, v := range variants {
+			s = append(s, v.Name)
+		}
+		return
+	}
+	policy := LanguagePolicy{
+		Audio:        []input.Language{language.French, language.English},
+		DropDialects: []input.Language{language.CanadianFrench},
+	}
+	kept := policy.applyAudio(audio(language.English, language.CanadianFrench, language.German, language.EuropeanFrench))
+	if got := names(kept); len(got) != 3 || got[0] != "fr-FR" || got[1] != "en" || got[2] != "de" {
+		t.Errorf("unexpected order %v", got)
+	}
+	// Québec French is kept when it is the only French
+	kept = policy.applyAudio(audio(language.English, language.CanadianFrench))
+	if got := names(kept); len(got) != 2 || got[0] != "fr-CA" {
+		t.Errorf("Québec French should be kept, got %v", got)
+	}
+	policy.Only = true
+	if got := names(policy.applyAudio(audio(language.German, language.English))); len(got) != 1 || got[0] != "en" {
+		t.Errorf("unwanted languages should be dropped, got %v", got)
+	}
+	if got := names(policy.applyAudio(audio(language.German))); len(got) != 1 {
+		t.Error("the only language should be kept")
+	}
+
+	subtitles := []SubtitleVariant{
+		{Name: "fr", Language: language.French},
+		{Name: "fr-forced", Language: language.French, Forced: true},
+		{Name: "fr-CA", Language: language.CanadianFrench},
+	}
+	if kept := policy.applySubtitles(subtitles); len(kept) != 2 || kept[1].Name != "fr-forced" {
+		t.Errorf("unexpected subtitles %+v", kept)
+	}
+	if (LanguagePolicy{DropDialects: []input.Language{language.French}}).Validate() == nil {
+		t.Error("a language is not a dialect")
+	}
+}
+
+func TestRemoveVFQSubtitles(t *testing.T) {
+	subtitle := func(index int, lang, title string) *probe.ProbeStream {
+		return &probe.ProbeStream{Index: index, CodecName: "subrip", CodecType: "subtitle",
+			Tags: probe.StreamTags{Language: lang, Title: title}}
+	}
+	languages := func(variants []SubtitleVariant) (s []input.Language) {
+		for _, v := range variants {
+			s = append(s, v.Language)
+		}
+		return
+	}
+	noSearch := func([]input.Language) map[input.Language][]input.SubtitleInput { return nil }
+	probeData := &probe.ProbeData{Streams: []*probe.ProbeStream{subtitle(0, "eng", ""), subtitle(1, "fre", "VFQ")}}
+	// Québec French subtitles are dropped even if they are the only French ones
+	variants := SuggestSubtitlesVariants([]string{"movie.mkv"}, []*probe.ProbeData{probeData}, noSearch, true)
+	if got := languages(variants); len(got) != 1 || got[0] != input.EnglishLanguage {
+		t.Errorf("Québec French should be dropped, got %v", got)
+	}
+	// The language policy keeps them in that case
+	variants = SuggestSubtitles([]string{"movie.mkv"}, []*probe.ProbeData{probeData}, noSearch, vfqPolicy(true))
+	if got := languages(variants); len(got) != 2 || got[1] != language.CanadianFrench {
+		t.Errorf("Québec French should be kept by the policy, got %v", got)
+	}
+	probeData.Streams = append(probeData.Streams, subtitle(2, "fre", "VFF"))
+	variants = SuggestSubtitlesVariants([]string{"movie.mkv"}, []*probe.ProbeData{probeData}, noSearch, true)
+	if got := languages(variants); len(got) != 2 || got[1] != language.EuropeanFrench {
+		t.Errorf("Québec French should be dropped, got %v", got)
+	}
+}
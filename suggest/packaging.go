@@ -0,0 +1,234 @@
+package suggest
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/allezxandre/go-hls-encoder/encryption"
+)
+
+// SegmentContainer The container format of the media segments
+type SegmentContainer string
+
+const (
+	FMP4Segments   SegmentContainer = "fmp4"   // Fragmented MP4 (CMAF), the default
+	MPEGTSSegments SegmentContainer = "mpegts" // MPEG Transport Stream
+)
+
+// PlaylistType The value of EXT-X-PLAYLIST-TYPE in media playlists
+type PlaylistType string
+
+const (
+	VODPlaylist   PlaylistType = "vod"   // The default
+	EventPlaylist PlaylistType = "event" // Segments are only appended
+	LivePlaylist  PlaylistType = "live"  // No EXT-X-PLAYLIST-TYPE: segments can be removed
+)
+
+// PackagingOptions How the HLS package is segmented.
+// Zero values are replaced by the ones of DefaultPackagingOptions.
+type PackagingOptions struct {
+	SegmentDuration     float64          `json:"segment_duration,omitempty" yaml:"segment_duration,omitempty"`   // Target segment duration, in seconds
+	SegmentContainer    SegmentContainer `json:"segment_container,omitempty" yaml:"segment_container,omitempty"` // "fmp4" or "mpegts"
+	PlaylistType        PlaylistType     `json:"playlist_type,omitempty" yaml:"playlist_type,omitempty"`         // "vod", "event" or "live"
+	ListSize            int              `json:"list_size,omitempty" yaml:"list_size,omitempty"`                 // Maximum number of segments in media playlists. 0 to keep them all
+	SingleFile          bool             `json:"single_file,omitempty" yaml:"single_file,omitempty"`             // Write a single file per variant, addressed with byte-ranges
+	IndependentSegments bool             `json:"independent_segments,omitempty" yaml:"independent_segments,omitempty"`
+
+	// Filename templates, relative to the output directory.
+	// `%v` is replaced by the variant index, and `%d` by the segment number.
+	// Empty to use ffmpeg's defaults.
+	SegmentFilename string `json:"segment_filename,omitempty" yaml:"segment_filename,omitempty"`
+	InitFilename    string `json:"init_filename,omitempty" yaml:"init_filename,omitempty"` // fMP4 only
+
+	// Also write a DASH manifest next to the master playlist, with the same fMP4 segments
+	DASH bool `json:"dash,omitempty" yaml:"dash,omitempty"`
+
+	// Encryption of the audio and video segments. `nil` to leave them clear
+	Encryption *EncryptionOptions `json:"encryption,omitempty" yaml:"encryption,omitempty"`
+
+	// Low-Latency HLS: media playlists announce the parts of the segments as they are written. `nil` for regular HLS
+	LowLatency *LowLatencyOptions `json:"low_latency,omitempty" yaml:"low_latency,omitempty"`
+}
+
+// LowLatencyOptions How the segments of low-latency playlists are split in parts.
+// ffmpeg writes the parts, which are then joined into segments named after SegmentFilename.
+type LowLatencyOptions struct {
+	PartDuration float64 `json:"part_duration,omitempty" yaml:"part_duration,omitempty"` // Target part duration, in seconds
+	// Filename template of the parts, relative to the output directory.
+	// `%v` is replaced by the variant index, and `%d` by the part number.
+	PartFilename string `json:"part_filename,omitempty" yaml:"part_filename,omitempty"`
+}
+
+// Defaults of low-latency packages
+const (
+	DefaultPartDuration       = 1.0
+	DefaultPartFilename       = "part_%v_%d.m4s"
+	DefaultLowLatencySegments = "segment_%v_%d.m4s" // SegmentFilename, since ffmpeg only names parts
+)
+
+// PartTarget Returns the target part duration
+func (l LowLatencyOptions) PartTarget() time.Duration {
+	if l.PartDuration == 0 {
+		return time.Duration(DefaultPartDuration * float64(time.Second))
+	}
+	return time.Duration(l.PartDuration * float64(time.Second))
+}
+
+// EncryptionOptions How the audio and video segments are encrypted.
+// Subtitles are left clear.
+type EncryptionOptions struct {
+	// "AES-128" for "mpegts" segments, "SAMPLE-AES" ('cbcs') or "SAMPLE-AES-CTR" ('cenc') for "fmp4" segments
+	Method encryption.Method `json:"method" yaml:"method"`
+	// Number of segments encrypted with the same key. 0 to use a single key
+	KeyRotation int `json:"key_rotation,omitempty" yaml:"key_rotation,omitempty"`
+	// Prefix of the URIs of the keys written by the default key provider. Empty for URIs relative to the playlists
+	KeyURIPrefix string `json:"key_uri_prefix,omitempty" yaml:"key_uri_prefix,omitempty"`
+}
+
+// Validate Returns an error if the encryption method cannot be used with `container` segments
+func (e EncryptionOptions) Validate(container SegmentContainer) error {
+	switch e.Method {
+	case encryption.AES128:
+		if container != MPEGTSSegments {
+			return fmt.Errorf("%s encryption needs %q segments: use %s or %s", e.Method, MPEGTSSegments,
+				encryption.SampleAES, encryption.SampleAESCTR)
+		}
+	case encryption.SampleAES, encryption.SampleAESCTR:
+		if container != FMP4Segments {
+			return fmt.Errorf("%s encryption needs %q segments: use %s", e.Method, FMP4Segments, encryption.AES128)
+		}
+	default:
+		return fmt.Errorf("unknown encryption method %q", e.Method)
+	}
+	if e.KeyRotation < 0 {
+		return fmt.Errorf("invalid key rotation %d", e.KeyRotation)
+	}
+	return nil
+}
+
+// DefaultPackagingOptions Returns the options used when none are provided
+func DefaultPackagingOptions() PackagingOptions {
+	return PackagingOptions{
+		SegmentDuration:  6,
+		SegmentContainer: FMP4Segments,
+		PlaylistType:     VODPlaylist,
+	}
+}
+
+// WithDefaults Returns the options where zero values are replaced by the default ones
+func (o PackagingOptions) WithDefaults() PackagingOptions {
+	defaults := DefaultPackagingOptions()
+	if o.SegmentDuration == 0 {
+		o.SegmentDuration = defaults.SegmentDuration
+	}
+	if len(o.SegmentContainer) == 0 {
+		o.SegmentContainer = defaults.SegmentContainer
+	}
+	if len(o.PlaylistType) == 0 {
+		o.PlaylistType = defaults.PlaylistType
+	}
+	if o.LowLatency != nil {
+		lowLatency := *o.LowLatency
+		if lowLatency.PartDuration == 0 {
+			lowLatency.PartDuration = DefaultPartDuration
+		}
+		if len(lowLatency.PartFilename) == 0 {
+			lowLatency.PartFilename = DefaultPartFilename
+		}
+		o.LowLatency = &lowLatency
+		if len(o.SegmentFilename) == 0 {
+			o.SegmentFilename = DefaultLowLatencySegments
+		}
+	}
+	return o
+}
+
+// TargetDuration Returns the target segment duration
+func (o PackagingOptions) TargetDuration() time.Duration {
+	return time.Duration(o.WithDefaults().SegmentDuration * float64(time.Second))
+}
+
+// Validate Returns an error describing the first invalid option, if any
+func (o PackagingOptions) Validate() error {
+	o = o.WithDefaults()
+	if o.SegmentDuration < 0 {
+		return fmt.Errorf("invalid segment duration %v", o.SegmentDuration)
+	}
+	switch o.SegmentContainer {
+	case FMP4Segments, MPEGTSSegments:
+	default:
+		return fmt.Errorf("unknown segment container %q", o.SegmentContainer)
+	}
+	switch o.PlaylistType {
+	case VODPlaylist, EventPlaylist, LivePlaylist:
+	default:
+		return fmt.Errorf("unknown playlist type %q", o.PlaylistType)
+	}
+	if o.ListSize < 0 {
+		return fmt.Errorf("invalid list size %d", o.ListSize)
+	}
+	if o.ListSize > 0 && o.PlaylistType != LivePlaylist {
+		return fmt.Errorf("a list size can only be set for %q playlists", LivePlaylist)
+	}
+	if len(o.SegmentFilename) > 0 {
+		if !strings.Contains(o.SegmentFilename, "%v") {
+			return fmt.Errorf("segment filename %q must contain %%v", o.SegmentFilename)
+		}
+		if !o.SingleFile && !strings.Contains(o.SegmentFilename, "%d") && !strings.Contains(o.SegmentFilename, "%0") {
+			return fmt.Errorf("segment filename %q must contain a segment number such as %%d", o.SegmentFilename)
+		}
+	}
+	if len(o.InitFilename) > 0 {
+		if o.SegmentContainer != FMP4Segments {
+			return fmt.Errorf("an init filename can only be set for %q segments", FMP4Segments)
+		}
+		if !strings.Contains(o.InitFilename, "%v") {
+			return fmt.Errorf("init filename %q must contain %%v", o.InitFilename)
+		}
+	}
+	if o.DASH {
+		if o.SegmentContainer != FMP4Segments {
+			return fmt.Errorf("a DASH manifest needs %q segments", FMP4Segments)
+		}
+		// The manifest is static: it is written once the encode is done
+		if o.PlaylistType != VODPlaylist {
+			return fmt.Errorf("a DASH manifest can only be written for %q playlists", VODPlaylist)
+		}
+		// Players would need a DRM system to get the keys
+		if o.Encryption != nil {
+			return fmt.Errorf("a DASH manifest cannot be written for encrypted segments")
+		}
+	}
+	if o.Encryption != nil {
+		if err := o.Encryption.Validate(o.SegmentContainer); err != nil {
+			return err
+		}
+		// Segments are encrypted once the encode is done
+		if o.PlaylistType != VODPlaylist {
+			return fmt.Errorf("encryption is only supported for %q playlists", VODPlaylist)
+		}
+		if o.SingleFile {
+			return fmt.Errorf("encryption is not supported for single file variants")
+		}
+	}
+	if o.LowLatency != nil {
+		if o.SegmentContainer != FMP4Segments {
+			return fmt.Errorf("low-latency HLS needs %q segments", FMP4Segments)
+		}
+		if o.PlaylistType == VODPlaylist {
+			return fmt.Errorf("low-latency HLS needs %q or %q playlists", EventPlaylist, LivePlaylist)
+		}
+		if o.SingleFile {
+			return fmt.Errorf("low-latency HLS is not supported for single file variants")
+		}
+		if part := o.LowLatency.PartDuration; part <= 0 || part >= o.SegmentDuration {
+			return fmt.Errorf("invalid part duration %v: it must be shorter than the segment duration", part)
+		}
+		if !strings.Contains(o.LowLatency.PartFilename, "%v") ||
+			(!strings.Contains(o.LowLatency.PartFilename, "%d") && !strings.Contains(o.LowLatency.PartFilename, "%0")) {
+			return fmt.Errorf("part filename %q must contain %%v and a part number such as %%d", o.LowLatency.PartFilename)
+		}
+	}
+	return nil
+}
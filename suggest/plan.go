@@ -0,0 +1,324 @@
+package suggest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/allezxandre/go-hls-encoder/input"
+	"github.com/allezxandre/go-hls-encoder/language"
+	"github.com/allezxandre/go-hls-encoder/probe"
+	"gopkg.in/yaml.v2"
+)
+
+// HLSSettings Naming of the HLS package files.
+type HLSSettings struct {
+	MasterPlaylistName string `json:"master_playlist_name" yaml:"master_playlist_name"` // Without extension
+	StreamPlaylistName string `json:"stream_playlist_name" yaml:"stream_playlist_name"` // Prefix of the variant playlists, without extension
+}
+
+// DefaultHLSSettings Returns the settings used by SuggestPlan
+func DefaultHLSSettings() HLSSettings {
+	return HLSSettings{
+		MasterPlaylistName: "master",
+		StreamPlaylistName: "stream",
+	}
+}
+
+// EncodingPlan Everything the converter needs to encode a set of inputs.
+// A plan can be suggested, saved to JSON or YAML, edited, and loaded back.
+type EncodingPlan struct {
+	Inputs    []string          `json:"inputs" yaml:"inputs"`
+	HLS       HLSSettings       `json:"hls" yaml:"hls"`
+	Packaging PackagingOptions  `json:"packaging" yaml:"packaging"`
+	Video     []VideoVariant    `json:"video" yaml:"video"`
+	Audio     []AudioVariant    `json:"audio" yaml:"audio"`
+	Subtitles []SubtitleVariant `json:"subtitles" yaml:"subtitles"`
+}
+
+// SuggestPlan Suggests a plan for the inputs at `inputURLs`, of which
+// `probeDataInputs` are the probe data, with the DefaultLanguagePolicy. See SuggestVideoVariants,
+// SuggestAudioVariants and SuggestSubtitlesVariants for the other arguments.
+func SuggestPlan(inputURLs []string, probeDataInputs []*probe.ProbeData, createAlternateStereo bool,
+	additionalSearcher func(languages []input.Language) map[input.Language][]input.SubtitleInput,
+	removeVFQ bool) *EncodingPlan {
+	plan := SuggestPlanWithPolicy(inputURLs, probeDataInputs, createAlternateStereo, additionalSearcher, vfqPolicy(removeVFQ))
+	if removeVFQ {
+		plan.Subtitles = dropVFQSubtitles(plan.Subtitles)
+		SelectRenditions(plan.Audio, plan.Subtitles)
+	}
+	return plan
+}
+
+// SuggestPlanWithPolicy Suggests a plan for the inputs at `inputURLs`, of which
+// `probeDataInputs` are the probe data, whose languages follow `policy`. See SuggestVideoVariants,
+// SuggestAudio and SuggestSubtitles for the other arguments.
+func SuggestPlanWithPolicy(inputURLs []string, probeDataInputs []*probe.ProbeData, createAlternateStereo bool,
+	additionalSearcher func(languages []input.Language) map[input.Language][]input.SubtitleInput,
+	policy LanguagePolicy) *EncodingPlan {
+	plan := &EncodingPlan{
+		Inputs:    inputURLs,
+		HLS:       DefaultHLSSettings(),
+		Packaging: DefaultPackagingOptions(),
+		Video:     SuggestVideoVariants(probeDataInputs),
+		Audio:     SuggestAudio(probeDataInputs, createAlternateStereo, policy),
+		Subtitles: SuggestSubtitles(inputURLs, probeDataInputs, additionalSearcher, policy),
+	}
+	SelectRenditions(plan.Audio, plan.Subtitles)
+	return plan
+}
+
+// PlanError Lists all the problems found in a plan.
+type PlanError []string
+
+func (e PlanError) Error() string {
+	return "invalid encoding plan: " + strings.Join(e, "; ")
+}
+
+// Validate Checks the plan can be passed to the converter.
+// The returned error is a PlanError.
+func (p *EncodingPlan) Validate() error {
+	var problems PlanError
+	addProblem := func(format string, a ...interface{}) {
+		problems = append(problems, fmt.Sprintf(format, a...))
+	}
+
+	if len(p.Inputs) == 0 {
+		addProblem("no input")
+	}
+	for name, value := range map[string]string{
+		"master playlist name": p.HLS.MasterPlaylistName,
+		"stream playlist name": p.HLS.StreamPlaylistName,
+	} {
+		if len(value) == 0 || strings.ContainsAny(value, `/\`) {
+			addProblem("invalid %s %q", name, value)
+		}
+	}
+	if err := p.Packaging.Validate(); err != nil {
+		addProblem("packaging: %v", err)
+	}
+	if len(p.Video) == 0 && len(p.Audio) == 0 {
+		addProblem("no video nor audio variant")
+	}
+
+	for i, v := range p.Video {
+		if err := p.validateMapInput(v.MapInput); err != nil {
+			addProblem("video variant %d: %v", i, err)
+		}
+		if len(v.Codec) == 0 {
+			addProblem("video variant %d: missing codec", i)
+		}
+		if v.Profile != nil && v.Level == nil {
+			addProblem("video variant %d: a level is required with profile %q", i, *v.Profile)
+		}
+		if v.Codec == "copy" && (v.ResolutionHeight != nil || v.Bitrate != nil || v.CRF != nil || v.MaxBitrate != nil) {
+			addProblem("video variant %d: cannot scale or change the bitrate of a copied stream", i)
+		}
+		if v.Codec == "copy" && p.Packaging.LowLatency != nil {
+			// Its keyframes follow the source: segments would last longer than the target duration
+			addProblem("video variant %d: low-latency playlists cannot copy video", i)
+		}
+		if v.MaxBitrate != nil && v.BufferSize == nil {
+			addProblem("video variant %d: a buffer size is required with a max bitrate", i)
+		}
+		if _, err := strconv.Atoi(v.Bandwidth); err != nil {
+			addProblem("video variant %d: invalid bandwidth %q", i, v.Bandwidth)
+		}
+		switch v.VideoRange {
+		case "", "SDR", "PQ", "HLG":
+		default:
+			addProblem("video variant %d: invalid video range %q", i, v.VideoRange)
+		}
+		switch v.DynamicRange {
+		case "", SDR, HDR10, HLG, DolbyVision, DolbyVisionOnly:
+		default:
+			addProblem("video variant %d: invalid dynamic range %q", i, v.DynamicRange)
+		}
+		if v.ToneMap != nil {
+			if err := v.ToneMap.Validate(); err != nil {
+				addProblem("video variant %d: %v", i, err)
+			}
+			if v.Codec == "copy" {
+				addProblem("video variant %d: cannot tone-map a copied stream", i)
+			}
+		}
+		if len(v.SupplementalCodecs) > 0 && v.Codec != "copy" {
+			addProblem("video variant %d: supplemental codecs require a copied stream", i)
+		}
+		switch v.HDCPLevel {
+		case "", "NONE", "TYPE-0", "TYPE-1":
+		default:
+			addProblem("video variant %d: invalid HDCP level %q", i, v.HDCPLevel)
+		}
+	}
+
+	audioNames := map[string]bool{}
+	audioDefaults := map[string]bool{}
+	for i, v := range p.Audio {
+		if err := p.validateMapInput(v.MapInput); err != nil {
+			addProblem("audio variant %d: %v", i, err)
+		}
+		if len(v.Codec) == 0 {
+			addProblem("audio variant %d: missing codec", i)
+		}
+		if len(v.Name) == 0 {
+			addProblem("audio variant %d: missing name", i)
+		} else if audioNames[v.Name] {
+			addProblem("audio variant %d: duplicate name %q", i, v.Name)
+		}
+		audioNames[v.Name] = true
+		if _, err := language.Parse(string(v.Language)); err != nil {
+			addProblem("audio variant %d: %v %q", i, err, v.Language)
+		}
+		if v.Default {
+			if audioDefaults[v.Group()] {
+				addProblem("audio variant %d: another variant of group %q is DEFAULT", i, v.Group())
+			}
+			audioDefaults[v.Group()] = true
+			if v.Autoselect != nil && !*v.Autoselect {
+				addProblem("audio variant %d: a DEFAULT variant must be AUTOSELECT", i)
+			}
+		}
+		if v.ConvertToStereo && v.Codec == "copy" {
+			addProblem("audio variant %d: cannot convert a copied stream to stereo", i)
+		}
+	}
+
+	subtitleNames := map[string]bool{}
+	subtitleDefaults := map[string]bool{}
+	outputIndexes := map[uint]bool{}
+	for i, v := range p.Subtitles {
+		if len(v.InputURL) == 0 {
+			addProblem("subtitle variant %d: missing input URL", i)
+		}
+		if len(v.Name) == 0 || strings.ContainsAny(v.Name, `/\`) {
+			addProblem("subtitle variant %d: invalid name %q", i, v.Name)
+		} else if subtitleNames[v.Name] {
+			addProblem("subtitle variant %d: duplicate name %q", i, v.Name)
+		}
+		subtitleNames[v.Name] = true
+		if _, err := language.Parse(string(v.Language)); err != nil {
+			addProblem("subtitle variant %d: %v %q", i, err, v.Language)
+		}
+		if v.Default {
+			if subtitleDefaults[v.Group()] {
+				addProblem("subtitle variant %d: another variant of group %q is DEFAULT", i, v.Group())
+			}
+			subtitleDefaults[v.Group()] = true
+			if v.Autoselect != nil && !*v.Autoselect {
+				addProblem("subtitle variant %d: a DEFAULT variant must be AUTOSELECT", i)
+			}
+		}
+		if outputIndexes[v.OutputIndex] {
+			addProblem("subtitle variant %d: duplicate output index %d", i, v.OutputIndex)
+		}
+		outputIndexes[v.OutputIndex] = true
+	}
+
+	if len(problems) > 0 {
+		return problems
+	}
+	return nil
+}
+
+// validateMapInput Checks that `mapInput` is in the form of $input:$stream
+// and refers to one of the inputs of the plan.
+func (p *EncodingPlan) validateMapInput(mapInput string) error {
+	parts := strings.Split(mapInput, ":")
+	if len(parts) != 2 {
+		return fmt.Errorf("invalid map %q", mapInput)
+	}
+	inputIndex, err1 := strconv.Atoi(parts[0])
+	_, err2 := strconv.Atoi(parts[1])
+	if err1 != nil || err2 != nil {
+		return fmt.Errorf("invalid map %q", mapInput)
+	}
+	if inputIndex < 0 || inputIndex >= len(p.Inputs) {
+		return fmt.Errorf("map %q refers to an unknown input", mapInput)
+	}
+	return nil
+}
+
+// PlanFormat The serialization format of a plan
+type PlanFormat string
+
+const (
+	JSONPlan PlanFormat = "json"
+	YAMLPlan PlanFormat = "yaml"
+)
+
+// PlanFormatForFile Returns the format to use for a file, based on its extension.
+// Defaults to JSON.
+func PlanFormatForFile(filename string) PlanFormat {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".yaml", ".yml":
+		return YAMLPlan
+	default:
+		return JSONPlan
+	}
+}
+
+// Encode Writes the plan to `w` in the given format.
+func (p *EncodingPlan) Encode(w io.Writer, format PlanFormat) error {
+	switch format {
+	case YAMLPlan:
+		encoder := yaml.NewEncoder(w)
+		if err := encoder.Encode(p); err != nil {
+			return err
+		}
+		return encoder.Close()
+	default:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(p)
+	}
+}
+
+// DecodePlan Reads a plan from `r` in the given format, and validates it.
+// An invalid plan is returned along with its PlanError.
+func DecodePlan(r io.Reader, format PlanFormat) (*EncodingPlan, error) {
+	var p EncodingPlan
+	var err error
+	switch format {
+	case YAMLPlan:
+		decoder := yaml.NewDecoder(r)
+		decoder.SetStrict(true)
+		err = decoder.Decode(&p)
+	default:
+		decoder := json.NewDecoder(r)
+		decoder.DisallowUnknownFields()
+		err = decoder.Decode(&p)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &p, p.Validate()
+}
+
+// Save Writes the plan to `filename`, in the format matching its extension.
+func (p *EncodingPlan) Save(filename string) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	if err := p.Encode(f, PlanFormatForFile(filename)); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// LoadPlan Reads and validates the plan at `filename`,
+// in the format matching its extension.
+func LoadPlan(filename string) (*EncodingPlan, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return DecodePlan(f, PlanFormatForFile(filename))
+}
@@ -0,0 +1,152 @@
+package suggest
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/allezxandre/go-hls-encoder/encryption"
+	"github.com/allezxandre/go-hls-encoder/input"
+)
+
+func testPlan() *EncodingPlan {
+	bitrate := "256k"
+	return &EncodingPlan{
+		Inputs: []string{"movie.mkv"},
+		HLS:    DefaultHLSSettings(),
+		Video: []VideoVariant{{
+			MapInput:   "0:0",
+			Codec:      "copy",
+			Resolution: "1920x1080",
+			Bandwidth:  "5000000",
+		}},
+		Audio: []AudioVariant{{
+			MapInput: "0:1",
+			Codec:    "aac",
+			Type:     StereoSound,
+			Bitrate:  &bitrate,
+			Name:     "Audio 1",
+			Language: input.FrenchLanguage,
+		}},
+		Subtitles: []SubtitleVariant{{
+			InputURL:    "movie.mkv",
+			StreamIndex: 2,
+			Name:        "Subtitle2",
+			Language:    input.EnglishLanguage,
+			OutputIndex: 1,
+		}},
+	}
+}
+
+func TestPlanRoundTrip(t *testing.T) {
+	for _, format := range []PlanFormat{JSONPlan, YAMLPlan} {
+		var buffer bytes.Buffer
+		if err := testPlan().Encode(&buffer, format); err != nil {
+			t.Error("Cannot encode plan as", format, err)
+			continue
+		}
+		p, err := DecodePlan(&buffer, format)
+		if err != nil {
+			t.Error("Cannot decode plan as", format, err)
+			continue
+		}
+		if p.Audio[0].Language != input.FrenchLanguage || *p.Audio[0].Bitrate != "256k" ||
+			p.Subtitles[0].StreamIndex != 2 || p.HLS.MasterPlaylistName != "master" {
+			t.Errorf("Unexpected plan after %s round trip: %+v", format, p)
+		}
+	}
+}
+
+func TestPlanValidation(t *testing.T) {
+	if err := testPlan().Validate(); err != nil {
+		t.Error("Valid plan reported as invalid:", err)
+	}
+
+	p := testPlan()
+	height := 720
+	p.Video[0].ResolutionHeight = &height // Cannot scale a copy
+	p.Audio[0].MapInput = "1:1"           // Unknown input
+	p.Subtitles = append(p.Subtitles, p.Subtitles[0])
+	err := p.Validate()
+	problems, ok := err.(PlanError)
+	if !ok {
+		t.Fatal("Expected a PlanError, got", err)
+	}
+	if len(problems) != 4 { // Scale, map, duplicate name & output index
+		t.Error("Unexpected problems:", problems)
+	}
+}
+
+func TestPackagingValidation(t *testing.T) {
+	for _, packaging := range []PackagingOptions{
+		{},
+		{SegmentContainer: MPEGTSSegments, SegmentFilename: "segment_%v_%03d.ts"},
+		{PlaylistType: LivePlaylist, ListSize: 5},
+		{InitFilename: "init_%v.mp4", SegmentFilename: "segment_%v_%d.m4s"},
+		{SingleFile: true, SegmentFilename: "stream_%v.m4s"},
+	} {
+		if err := packaging.Validate(); err != nil {
+			t.Errorf("Valid packaging %+v reported as invalid: %v", packaging, err)
+		}
+	}
+	for _, packaging := range []PackagingOptions{
+		{SegmentDuration: -1},
+		{SegmentContainer: "mkv"},
+		{PlaylistType: "static"},
+		{PlaylistType: LivePlaylist, ListSize: -1},
+		{PlaylistType: EventPlaylist, ListSize: 5}, // Only for live playlists
+		{SegmentFilename: "segment_%d.m4s"},        // No variant
+		{SegmentFilename: "segment_%v.m4s"},        // No segment number
+		{SegmentContainer: MPEGTSSegments, InitFilename: "init_%v.mp4"},
+		{InitFilename: "init.mp4"},
+	} {
+		if err := packaging.Validate(); err == nil {
+			t.Errorf("Invalid packaging %+v reported as valid", packaging)
+		}
+	}
+}
+
+func TestPackagingEncryption(t *testing.T) {
+	p := testPlan()
+	p.Packaging.Encryption = &EncryptionOptions{Method: encryption.SampleAES, KeyRotation: 10}
+	if err := p.Validate(); err != nil {
+		t.Error("Valid encryption reported as invalid:", err)
+	}
+	for _, packaging := range []PackagingOptions{
+		{Encryption: &EncryptionOptions{Method: encryption.AES128}},                                      // Needs MPEG-TS
+		{SegmentContainer: MPEGTSSegments, Encryption: &EncryptionOptions{Method: encryption.SampleAES}}, // Needs fMP4
+		{PlaylistType: EventPlaylist, Encryption: &EncryptionOptions{Method: encryption.SampleAES}},
+		{SingleFile: true, Encryption: &EncryptionOptions{Method: encryption.SampleAESCTR}},
+		{Encryption: &EncryptionOptions{Method: encryption.SampleAES, KeyRotation: -1}},
+	} {
+		if err := packaging.Validate(); err == nil {
+			t.Errorf("Invalid encryption %+v reported as valid", *packaging.Encryption)
+		}
+	}
+}
+
+func TestPackagingLowLatency(t *testing.T) {
+	valid := PackagingOptions{PlaylistType: EventPlaylist, LowLatency: &LowLatencyOptions{}}
+	if err := valid.Validate(); err != nil {
+		t.Error("Valid low-latency options reported as invalid:", err)
+	}
+	if o := valid.WithDefaults(); o.LowLatency.PartDuration != DefaultPartDuration || valid.LowLatency.PartDuration != 0 {
+		t.Error("Unexpected defaults:", *o.LowLatency)
+	}
+	for _, packaging := range []PackagingOptions{
+		{LowLatency: &LowLatencyOptions{}}, // Needs event or live playlists
+		{PlaylistType: LivePlaylist, SegmentContainer: MPEGTSSegments, LowLatency: &LowLatencyOptions{}},
+		{PlaylistType: LivePlaylist, LowLatency: &LowLatencyOptions{PartDuration: 6}},
+		{PlaylistType: LivePlaylist, LowLatency: &LowLatencyOptions{PartFilename: "part_%d.m4s"}},
+	} {
+		if err := packaging.Validate(); err == nil {
+			t.Errorf("Invalid low-latency options %+v reported as valid", *packaging.LowLatency)
+		}
+	}
+	// The keyframes of copied video do not follow the parts
+	p := testPlan()
+	p.Packaging = valid
+	if err := p.Validate(); err == nil || !strings.Contains(err.Error(), "cannot copy video") {
+		t.Error("Low-latency plan copying video reported as valid:", err)
+	}
+}
@@ -0,0 +1,144 @@
+package suggest
+
+import (
+	"strings"
+
+	"github.com/allezxandre/go-hls-encoder/input"
+	"github.com/allezxandre/go-hls-encoder/probe"
+)
+
+// markDefaultAudio Marks the DEFAULT audio variant: the first one in the DEFAULT language of `policy`,
+// or else the first one of a stream flagged as default in its source, or else the first one.
+// `variants` are in the default group.
+func markDefaultAudio(variants []AudioVariant, policy LanguagePolicy, probeDataInputs []*probe.ProbeData) {
+	if len(variants) == 0 {
+		return
+	}
+	chosen := 0
+	if i := indexOfAudio(variants, func(v AudioVariant) bool {
+		return policy.Default != input.Unknown && v.Language.Matches(policy.Default)
+	}); i >= 0 {
+		chosen = i
+	} else if i := indexOfAudio(variants, func(v AudioVariant) bool {
+		stream := sourceStream(v.MapInput, probeDataInputs)
+		return stream != nil && stream.Disposition.Default == 1
+	}); i >= 0 {
+		chosen = i
+	}
+	for i := range variants {
+		variants[i].Default = i == chosen
+	}
+}
+
+func indexOfAudio(variants []AudioVariant, match func(AudioVariant) bool) int {
+	for i, v := range variants {
+		if match(v) {
+			return i
+		}
+	}
+	return -1
+}
+
+// SelectRenditions Sets the DEFAULT and AUTOSELECT attributes of the renditions, following the HLS rules
+// (RFC 8216 section 4.3.4.1.1):
+//   - forced subtitles take the language of the audio of their language, e.g. "fr" becomes "fr-FR",
+//     so that players show them with it, and those of the DEFAULT audio are DEFAULT;
+//   - there is one DEFAULT audio rendition per group, the first one if none is marked,
+//     at most one DEFAULT subtitle rendition per group, and they are AUTOSELECT;
+//   - in a group, only one of the renditions with the same LANGUAGE, FORCED and CHARACTERISTICS
+//     is AUTOSELECT: the DEFAULT one, or else the first one.
+func SelectRenditions(audio []AudioVariant, subtitles []SubtitleVariant) {
+	// One DEFAULT audio per group
+	var defaultAudio *AudioVariant
+	audioDefaults := map[string]bool{}
+	for i := range audio {
+		if !audio[i].Default {
+			continue
+		}
+		if audioDefaults[audio[i].Group()] {
+			audio[i].Default = false
+			continue
+		}
+		audioDefaults[audio[i].Group()] = true
+		if defaultAudio == nil {
+			defaultAudio = &audio[i]
+		}
+	}
+	for i := range audio {
+		if !audioDefaults[audio[i].Group()] {
+			audio[i].Default = true
+			audioDefaults[audio[i].Group()] = true
+			if defaultAudio == nil {
+				defaultAudio = &audio[i]
+			}
+		}
+	}
+	// Forced subtitles are shown with the audio of their language
+	for i, s := range subtitles {
+		subtitles[i].Default = false
+		if !s.Forced {
+			continue
+		}
+		for _, a := range audio {
+			if a.Language != input.Unknown && a.Language.Matches(s.Language) {
+				subtitles[i].Language = a.Language
+				break
+			}
+		}
+	}
+	if defaultAudio != nil && defaultAudio.Language != input.Unknown {
+		groups := map[string]bool{}
+		for i, s := range subtitles {
+			if s.Forced && s.Language == defaultAudio.Language && !groups[s.Group()] {
+				subtitles[i].Default = true
+				groups[s.Group()] = true
+			}
+		}
+	}
+
+	audioKeys := make([]string, len(audio))
+	isDefault := make([]bool, len(audio))
+	for i, a := range audio {
+		audioKeys[i] = renditionKey(a.Group(), a.Language, a.Rendition("").Characteristics, false)
+		isDefault[i] = a.Default
+	}
+	for i, selected := range autoselect(audioKeys, isDefault) {
+		selected := selected
+		audio[i].Autoselect = &selected
+	}
+	subtitleKeys := make([]string, len(subtitles))
+	subtitleDefaults := make([]bool, len(subtitles))
+	for i, s := range subtitles {
+		subtitleKeys[i] = renditionKey(s.Group(), s.Language, s.Rendition().Characteristics, s.Forced)
+		subtitleDefaults[i] = s.Default
+	}
+	for i, selected := range autoselect(subtitleKeys, subtitleDefaults) {
+		selected := selected
+		subtitles[i].Autoselect = &selected
+	}
+}
+
+// renditionKey Returns what must differ between the AUTOSELECT renditions of a group
+func renditionKey(group string, lang input.Language, characteristics []string, forced bool) string {
+	key := []string{group, string(lang), strings.Join(characteristics, ",")}
+	if forced {
+		key = append(key, "forced")
+	}
+	return strings.Join(key, "|")
+}
+
+// autoselect Returns which renditions are AUTOSELECT: one per key,
+// the DEFAULT one if any, or else the first one
+func autoselect(keys []string, defaults []bool) []bool {
+	selected := make([]bool, len(keys))
+	chosen := map[string]int{}
+	for i, key := range keys {
+		if j, ok := chosen[key]; !ok || defaults[i] && !defaults[j] {
+			chosen[key] = i
+		}
+	}
+	for _, i := range chosen {
+		selected[i] = true
+	}
+	return selected
+}
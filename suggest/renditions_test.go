@@ -0,0 +1,58 @@
+package suggest
+
+import (
+	"testing"
+
+	"github.com/allezxandre/go-hls-encoder/input"
+	"github.com/allezxandre/go-hls-encoder/language"
+	"github.com/allezxandre/go-hls-encoder/probe"
+)
+
+func TestSelectRenditions(t *testing.T) {
+	probeData := &probe.ProbeData{Streams: []*probe.ProbeStream{
+		{Index: 0, CodecType: "audio", CodecName: "ac3", Channels: 6},
+		{Index: 1, CodecType: "audio", CodecName: "dts", Channels: 6},
+	}}
+	probeData.Streams[0].Tags.Language = "eng"
+	probeData.Streams[1].Tags.Language = "fre"
+	probeData.Streams[1].Tags.Title = "VFF"
+	probeData.Streams[1].Disposition.Default = 1
+
+	audio := SuggestAudio([]*probe.ProbeData{probeData}, true, DefaultLanguagePolicy())
+	if len(audio) != 4 || !audio[2].Default || audio[0].Default || audio[3].Default {
+		t.Fatalf("the first variant of the default stream should be DEFAULT: %+v", audio)
+	}
+	policy := DefaultLanguagePolicy()
+	policy.Default = language.English
+	if english := SuggestAudio([]*probe.ProbeData{probeData}, true, policy); !english[0].Default || english[2].Default {
+		t.Error("the language of the policy should be DEFAULT")
+	}
+
+	subtitles := []SubtitleVariant{
+		{Name: "fr", Language: language.French},
+		{Name: "fr-forced", Language: language.French, Forced: true},
+		{Name: "en-forced", Language: language.English, Forced: true},
+		{Name: "none"},
+		{Name: "none2"},
+	}
+	SelectRenditions(audio, subtitles)
+	if subtitles[1].Language != language.EuropeanFrench || subtitles[0].Language != language.French {
+		t.Error("forced subtitles should take the language of their audio")
+	}
+	if !subtitles[1].Default || subtitles[0].Default || subtitles[2].Default {
+		t.Error("the forced subtitles of the DEFAULT audio should be DEFAULT")
+	}
+	for i, expected := range []bool{true, false, true, false} {
+		if *audio[i].Autoselect != expected || audio[i].Rendition("").Autoselect != expected {
+			t.Errorf("audio %d: AUTOSELECT should be %v", i, expected)
+		}
+	}
+	if !*subtitles[3].Autoselect || *subtitles[4].Autoselect {
+		t.Error("only one of the subtitles without language should be AUTOSELECT")
+	}
+	for _, a := range audio {
+		if a.Default && a.Language == input.Unknown {
+			t.Error("unexpected DEFAULT")
+		}
+	}
+}
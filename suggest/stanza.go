@@ -1,109 +1,154 @@
 package suggest
 
 import (
-	"fmt"
-	"github.com/allezxandre/go-hls-encoder/input"
 	"log"
-	"strings"
+	"strconv"
+
+	"github.com/allezxandre/go-hls-encoder/codecs"
+	"github.com/allezxandre/go-hls-encoder/input"
+	"github.com/allezxandre/go-hls-encoder/playlist"
+	"github.com/allezxandre/go-hls-encoder/probe"
 )
 
-// Generates the entry for the m3u8 playlist
-// #EXT-X-STREAM-INF:BANDWIDTH=1500000,RESOLUTION=1920x796,CODECS="avc1.42e00a",AUDIO="audio"
-func (v VideoVariant) Stanza(streamPlaylistFilename string, audioGroup *string, subtitleGroup *string) string {
+// MeasuredBandwidth The bandwidth of an encoded variant, in bits/s
+type MeasuredBandwidth struct {
+	Peak    int // Bitrate of the largest segment
+	Average int // Bitrate of the whole variant
+}
+
+// Add Returns the bandwidth of two variants delivered together
+func (b MeasuredBandwidth) Add(other MeasuredBandwidth) MeasuredBandwidth {
+	return MeasuredBandwidth{Peak: b.Peak + other.Peak, Average: b.Average + other.Average}
+}
+
+// Max Returns the largest of both bandwidths
+func (b MeasuredBandwidth) Max(other MeasuredBandwidth) MeasuredBandwidth {
+	if other.Peak > b.Peak {
+		b.Peak = other.Peak
+	}
+	if other.Average > b.Average {
+		b.Average = other.Average
+	}
+	return b
+}
+
+// StreamInf Returns the entry of the variant in the master playlist.
+// `codecs` is the CODECS attribute, see VariantCodecs. Empty if unknown.
+// `measured` is the bandwidth of the encoded variant, including its audio. `nil` to use `Bandwidth`.
+func (v VideoVariant) StreamInf(streamPlaylistFilename string, audioGroup *string, subtitleGroup *string, codecs string,
+	measured *MeasuredBandwidth) playlist.Variant {
 	// From https://tools.ietf.org/html/draft-pantos-http-live-streaming-23#section-4.3.4.2
-	var optionsList []string // The list of options to create the entry
-	optionsList = append(optionsList,
-		fmt.Sprintf("BANDWIDTH=%v", v.Bandwidth),
-		fmt.Sprintf("RESOLUTION=%v", v.Resolution))
-	if audioGroup != nil && len(*audioGroup) > 0 {
-		optionsList = append(optionsList,
-			fmt.Sprintf("AUDIO=\"%v\"", *audioGroup))
-	}
-	if subtitleGroup != nil && len(*subtitleGroup) > 0 {
-		optionsList = append(optionsList,
-			fmt.Sprintf("SUBTITLES=\"%v\"", *subtitleGroup))
-	}
-	// TODO: Add CODECS
-	return fmt.Sprintf("#EXT-X-STREAM-INF:%v\n%v",
-		strings.Join(optionsList, ","), streamPlaylistFilename)
+	variant := playlist.Variant{
+		URI:          streamPlaylistFilename,
+		Codecs:       codecs,
+		Supplemental: v.SupplementalCodecs,
+		Resolution:   v.Resolution,
+		VideoRange:   v.VideoRange,
+		HDCPLevel:    v.HDCPLevel,
+	}
+	if measured != nil {
+		variant.Bandwidth = measured.Peak
+		variant.AverageBandwidth = measured.Average
+	} else {
+		variant.Bandwidth, _ = strconv.Atoi(v.Bandwidth)
+	}
+	if frameRate, err := probe.ParseRational(v.FrameRate); err == nil && frameRate > 0 {
+		variant.FrameRate = frameRate
+	}
+	if audioGroup != nil {
+		variant.Audio = *audioGroup
+	}
+	if subtitleGroup != nil {
+		variant.Subtitles = *subtitleGroup
+	}
+	return variant
 }
 
-func (v AudioVariant) Stanza(streamPlaylistFilename string) string {
+// Generates the entry for the m3u8 playlist
+// #EXT-X-STREAM-INF:BANDWIDTH=1500000,RESOLUTION=1920x796,CODECS="avc1.42c00a",AUDIO="audio"
+// See StreamInf.
+func (v VideoVariant) Stanza(streamPlaylistFilename string, audioGroup *string, subtitleGroup *string, codecs string,
+	measured *MeasuredBandwidth) string {
+	return v.StreamInf(streamPlaylistFilename, audioGroup, subtitleGroup, codecs, measured).String()
+}
+
+// Rendition Returns the entry of the variant in the master playlist
+func (v AudioVariant) Rendition(streamPlaylistFilename string) playlist.Rendition {
 	// From https://tools.ietf.org/html/draft-pantos-http-live-streaming-23#section-4.3.4.1
-	var optionsList []string // The list of options to create the entry
-	groupID := DefaultAudioGroupID
-	if v.GroupID != nil {
-		groupID = *v.GroupID
-	}
-	// Required attributes
-	optionsList = append(optionsList,
-		"TYPE=AUDIO",
-		"AUTOSELECT=YES",
-		fmt.Sprintf("GROUP-ID=\"%v\"", groupID),
-		fmt.Sprintf("NAME=\"%v\"", v.Name))
+	rendition := playlist.Rendition{
+		Type:       playlist.Audio,
+		GroupID:    v.Group(),
+		Name:       v.Name,
+		Default:    v.Default,
+		Autoselect: v.Default || v.Autoselect == nil || *v.Autoselect,
+		URI:        streamPlaylistFilename,
+	}
 	// Channel number
 	switch v.Type {
 	case SurroundSound, StereoSound:
-		optionsList = append(optionsList, fmt.Sprintf("CHANNELS=\"%d\"", v.Type))
+		rendition.Channels = strconv.Itoa(int(v.Type))
 	default:
 		log.Println("WARNING: Unknown number of channels")
 	}
 	// Language
 	if v.Language != input.Unknown {
-		optionsList = append(optionsList,
-			fmt.Sprintf("LANGUAGE=\"%v\"", v.Language))
+		rendition.Language = string(v.Language)
 	}
 	// Characteristics
-	var characteristics []string
 	if v.DescribesVideo != nil && *v.DescribesVideo {
-		characteristics = append(characteristics, "public.accessibility.describes-video")
+		rendition.Characteristics = append(rendition.Characteristics, "public.accessibility.describes-video")
 	}
-	if len(characteristics) > 0 {
-		optionsList = append(optionsList,
-			fmt.Sprintf("CHARACTERISTICS=\"%v\"", strings.Join(characteristics, ",")))
-	}
-	optionsList = append(optionsList,
-		fmt.Sprintf("URI=\"%v\"", streamPlaylistFilename))
-
-	return "#EXT-X-MEDIA:" + strings.Join(optionsList, ",")
+	return rendition
 }
 
-func (v SubtitleVariant) Stanza() string {
-	streamPlaylistFilename := v.PlaylistName("")
+// Stanza Generates the entry for the m3u8 playlist. See Rendition.
+func (v AudioVariant) Stanza(streamPlaylistFilename string) string {
+	return v.Rendition(streamPlaylistFilename).String()
+}
 
-	var optionsList []string // The list of options to create the entry
-	groupID := DefaultSubtitlesGroupID
-	if v.GroupID != nil {
-		groupID = *v.GroupID
-	}
-	optionsList = append(optionsList,
-		"TYPE=SUBTITLES",
-		"AUTOSELECT=YES",
-		fmt.Sprintf("GROUP-ID=\"%v\"", groupID),
-		fmt.Sprintf("NAME=\"%v\"", v.Name))
-	if v.Language != input.Unknown {
-		optionsList = append(optionsList,
-			fmt.Sprintf("LANGUAGE=\"%v\"", v.Language))
+// Rendition Returns the entry of the variant in the master playlist
+func (v SubtitleVariant) Rendition() playlist.Rendition {
+	name := v.Name
+	if len(v.DisplayName) > 0 {
+		name = v.DisplayName
 	}
-	forced := "NO"
-	if v.Forced {
-		forced = "YES"
+	rendition := playlist.Rendition{
+		Type:       playlist.Subtitles,
+		GroupID:    v.Group(),
+		Name:       name,
+		Default:    v.Default,
+		Autoselect: v.Default || v.Autoselect == nil || *v.Autoselect,
+		Forced:     v.Forced,
+		URI:        v.PlaylistName(""),
+	}
+	if v.Language != input.Unknown {
+		rendition.Language = string(v.Language)
 	}
-	optionsList = append(optionsList,
-		fmt.Sprintf("FORCED=%v", forced))
 	// Characteristics
-	var characteristics []string
 	if v.HearingImpaired {
-		characteristics = append(characteristics,
+		rendition.Characteristics = append(rendition.Characteristics,
 			"public.accessibility.transcribes-spoken-dialog",
 			"public.accessibility.describes-music-and-sound")
 	}
-	if len(characteristics) > 0 {
-		optionsList = append(optionsList,
-			fmt.Sprintf("CHARACTERISTICS=\"%v\"", strings.Join(characteristics, ",")))
-	}
-	optionsList = append(optionsList,
-		fmt.Sprintf("URI=\"%v\"", streamPlaylistFilename))
+	return rendition
+}
 
-	return "#EXT-X-MEDIA:" + strings.Join(optionsList, ",")
+// Stanza Generates the entry for the m3u8 playlist. See Rendition.
+func (v SubtitleVariant) Stanza() string {
+	return v.Rendition().String()
+}
+
+// VariantCodecs Returns the CODECS attribute of `video`: its codec followed by the ones
+// of `audioVariants`, its audio group. Returns "" if any of them is unknown.
+func VariantCodecs(video VideoVariant, audioVariants []AudioVariant) string {
+	all := []string{video.CodecString()}
+	for _, audio := range audioVariants {
+		all = append(all, audio.CodecString())
+	}
+	for _, codec := range all {
+		if len(codec) == 0 {
+			return ""
+		}
+	}
+	return codecs.Join(all...)
 }
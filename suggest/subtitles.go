@@ -2,36 +2,84 @@ package suggest
 
 import (
 	"github.com/allezxandre/go-hls-encoder/input"
+	"github.com/allezxandre/go-hls-encoder/language"
 	"github.com/allezxandre/go-hls-encoder/probe"
 	"path/filepath"
 	"strconv"
 )
 
 type SubtitleVariant struct {
-	InputURL    string // The stream URL where the subtitle should be found
-	StreamIndex uint   // The stream index of the subtitle in the input from InputURL
+	InputURL    string `json:"input_url" yaml:"input_url"`       // The stream URL where the subtitle should be found
+	StreamIndex uint   `json:"stream_index" yaml:"stream_index"` // The stream index of the subtitle in the input from InputURL
 
 	// M3U8 Playlist options: https://tools.ietf.org/html/draft-pantos-http-live-streaming-23
-	Name            string  // Unique name for variant. Required.
-	GroupID         *string // Optional group ID. "subtitles" will be used if `nil`
-	HearingImpaired bool
-	Forced          bool
-	Language        input.Language // Primary language https://tools.ietf.org/html/rfc5646
+	Name            string         `json:"name" yaml:"name"`                             // Unique name for variant. Required.
+	GroupID         *string        `json:"group_id,omitempty" yaml:"group_id,omitempty"` // Optional group ID. "subtitles" will be used if `nil`
+	HearingImpaired bool           `json:"hearing_impaired" yaml:"hearing_impaired"`
+	Forced          bool           `json:"forced" yaml:"forced"`
+	Language        input.Language `json:"language" yaml:"language"`                             // Primary language https://tools.ietf.org/html/rfc5646
+	DisplayName     string         `json:"display_name,omitempty" yaml:"display_name,omitempty"` // Optional. NAME in the master playlist, instead of `Name`
+	Default         bool           `json:"default" yaml:"default"`                               // If true, players show this variant unless the user chooses another one
+	Autoselect      *bool          `json:"autoselect,omitempty" yaml:"autoselect,omitempty"`     // If false, players only show this variant if the user picks it. `nil` is true. See SelectRenditions
 
 	// A unique output index for the subtitle file.
 	// Each subtitle variant should have its own.
-	OutputIndex uint
+	OutputIndex uint `json:"output_index" yaml:"output_index"`
 }
 
 var DefaultSubtitlesGroupID = "subtitles"
 
+// Group Returns the GROUP-ID of the variant
+func (v SubtitleVariant) Group() string {
+	if v.GroupID != nil {
+		return *v.GroupID
+	}
+	return DefaultSubtitlesGroupID
+}
+
 // SuggestSubtitlesVariants From an array of input URLs and another of the corresponding probe data,
-// SuggestSubtitlesVariants creates an array of suggested subtitle variants to create.
+// SuggestSubtitlesVariants creates an array of suggested subtitle variants to create, with the
+// DefaultLanguagePolicy. If `removeVFQ`, Québec French subtitles are dropped, even if they are the only French ones.
+// See SuggestSubtitles.
 func SuggestSubtitlesVariants(probeDataInputsURLs []string, probeDataInputs []*probe.ProbeData,
 	additionalSearcher func(languages []input.Language) map[input.Language][]input.SubtitleInput,
 	removeVFQ bool) []SubtitleVariant {
-	// Create a map of languages to their subtitles
-	languages := map[input.Language][]SubtitleVariant{input.EnglishLanguage: {}, input.FrenchLanguage: {}}
+	variants := SuggestSubtitles(probeDataInputsURLs, probeDataInputs, additionalSearcher, vfqPolicy(removeVFQ))
+	if removeVFQ {
+		variants = dropVFQSubtitles(variants)
+	}
+	return variants
+}
+
+// dropVFQSubtitles Returns the subtitle variants that are not in Québec French.
+// Unlike the audio variants, they are dropped even if they are the only French ones.
+func dropVFQSubtitles(variants []SubtitleVariant) (kept []SubtitleVariant) {
+	for _, variant := range variants {
+		if !variant.Language.Matches(language.CanadianFrench) {
+			kept = append(kept, variant)
+		}
+	}
+	return
+}
+
+// SuggestSubtitles Suggests the subtitle variants of the inputs at `probeDataInputsURLs`, of which
+// `probeDataInputs` are the probe data, kept and sorted according to `policy`.
+// The wanted languages of `policy` missing from the inputs are looked for with `additionalSearcher`.
+func SuggestSubtitles(probeDataInputsURLs []string, probeDataInputs []*probe.ProbeData,
+	additionalSearcher func(languages []input.Language) map[input.Language][]input.SubtitleInput,
+	policy LanguagePolicy) []SubtitleVariant {
+	// Create a map of languages to their subtitles, in the order they are found
+	languages := map[input.Language][]SubtitleVariant{}
+	var order []input.Language
+	add := func(lang input.Language, variants ...SubtitleVariant) {
+		if _, ok := languages[lang]; !ok {
+			order = append(order, lang)
+		}
+		languages[lang] = append(languages[lang], variants...)
+	}
+	for _, lang := range policy.Subtitles {
+		add(lang)
+	}
 	var outputIndex uint = 0
 
 	// First using the probe data...
@@ -39,25 +87,25 @@ func SuggestSubtitlesVariants(probeDataInputsURLs []string, probeDataInputs []*p
 		for streamIndex, stream := range probeData.Streams {
 			if stream.CodecType == "subtitle" && stream.CodecName != "hdmv_pgs_subtitle" {
 				outputIndex += 1
-				language := matchLanguage(stream)
+				streamLanguage := matchLanguage(stream)
 				variant := SubtitleVariant{
 					InputURL:        probeDataInputsURLs[inputIndex],
 					StreamIndex:     uint(streamIndex),
-					Language:        language,
+					Language:        streamLanguage,
 					Name:            "Subtitle" + strconv.Itoa(streamIndex),
 					HearingImpaired: matchHearingImpairedTag(stream),
 					Forced:          matchForcedTag(stream),
 					OutputIndex:     outputIndex,
 				}
-				languages[language] = append(languages[language], variant)
+				add(streamLanguage, variant)
 			}
 		}
 	}
 
 	// List all languages that still don't have enough subtitles
 	var languagesToSearch []input.Language
-	for lang, variants := range languages {
-		if len(variants) == 0 {
+	for _, lang := range order {
+		if len(languages[lang]) == 0 {
 			languagesToSearch = append(languagesToSearch, lang)
 		}
 	}
@@ -75,23 +123,22 @@ func SuggestSubtitlesVariants(probeDataInputsURLs []string, probeDataInputs []*p
 				Forced:          subtitleInput.Forced,
 				OutputIndex:     outputIndex,
 			}
-			languages[subtitleInput.Language] = append(languages[subtitleInput.Language], variant)
+			add(subtitleInput.Language, variant)
 		}
 	}
 
 	// Only keep one per language
-	variants := cleanVariants(languages, removeVFQ)
-	return variants
+	variants := cleanVariants(languages, order)
+	for i := range variants {
+		variants[i].DisplayName = subtitleDisplayName(variants[i])
+	}
+	return policy.applySubtitles(variants)
 }
 
-func cleanVariants(languages map[input.Language][]SubtitleVariant, removeVFQ bool) (variants []SubtitleVariant) {
+func cleanVariants(languages map[input.Language][]SubtitleVariant, order []input.Language) (variants []SubtitleVariant) {
 	// For each language...
-	for language, subtitleVariants := range languages {
-		if removeVFQ && language == input.QuebecLanguage {
-			// Skip VFQ
-			continue
-		}
-		if len(subtitleVariants) > 0 {
+	for _, lang := range order {
+		if subtitleVariants := languages[lang]; len(subtitleVariants) > 0 {
 			gotForced := false
 			gotFull := false
 			for _, subVariant := range subtitleVariants {
@@ -114,6 +161,22 @@ func cleanVariants(languages map[input.Language][]SubtitleVariant, removeVFQ boo
 	return variants
 }
 
+// subtitleDisplayName Returns the NAME of a subtitle rendition, after its language,
+// e.g. "Español (Forced)". Returns "" if the language is unknown.
+func subtitleDisplayName(v SubtitleVariant) string {
+	name := v.Language.Name()
+	if len(name) == 0 {
+		return ""
+	}
+	switch {
+	case v.Forced:
+		name += " (Forced)"
+	case v.HearingImpaired:
+		name += " (SDH)"
+	}
+	return name
+}
+
 // PlaylistName Returns the name of the m3u8 playlist.
 // If `outputDir` is not "", joins the filename with the outputDir
 func (v SubtitleVariant) PlaylistName(outputDir string) string {
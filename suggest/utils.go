@@ -2,47 +2,95 @@ package suggest
 
 import (
 	"github.com/allezxandre/go-hls-encoder/input"
+	"github.com/allezxandre/go-hls-encoder/language"
 	"github.com/allezxandre/go-hls-encoder/probe"
 	"regexp"
 	"strings"
+	"unicode"
 )
 
+var (
+	matchVFF = regexp.MustCompile(`\b(vff|vfi|true(\b)*french)\b`)
+	matchVFQ = regexp.MustCompile(`\bvfq\b|\bqu[eé]bec[a-z]*\b`)
+)
+
+// titleLanguages Abbreviations and names of languages found in the titles of streams,
+// in addition to the names known by language.FromName
+var titleLanguages = map[string]input.Language{
+	"vf":         language.French,
+	"fre":        language.French,
+	"ang":        language.English,
+	"angl":       language.English,
+	"anglais":    language.English,
+	"eng":        language.English,
+	"engl":       language.English,
+	"vo":         language.English, // Original version: usually English in our catalog
+	"latino":     language.LatinAmericanSpanish,
+	"castellano": language.EuropeanSpanish,
+	"castilian":  language.EuropeanSpanish,
+	"brazilian":  language.BrazilianPortuguese,
+	"brasileiro": language.BrazilianPortuguese,
+	"american":   language.AmericanEnglish,
+	"british":    language.BritishEnglish,
+}
+
+// matchTitle Guesses the language of a stream from its title, e.g. "Français (VFQ)"
+func matchTitle(title string) input.Language {
+	guess := input.Unknown
+	words := strings.FieldsFunc(strings.ToLower(title), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+	for _, word := range words {
+		found, ok := titleLanguages[word]
+		if !ok {
+			found, ok = language.FromName(word)
+		}
+		if !ok {
+			continue
+		}
+		// Keep the most specific guess, e.g. "pt-BR" for "Portuguese (Brazilian)"
+		if guess == input.Unknown || found.Matches(guess.Base()) && len(found.Region()) > 0 {
+			guess = found
+		}
+	}
+	return guess
+}
+
 func matchLanguage(stream *probe.ProbeStream) input.Language {
 	currentGuess := input.Unknown
 	// Match title
 	if len(stream.Tags.Title) > 0 {
-		matchVFF := regexp.MustCompile(`\b(vff|vfi|true(\b)*french)\b`)
-		matchVFQ := regexp.MustCompile(`\bvfq\b|\bqu[eé]bec[a-z]*\b`)
-		matchFrench := regexp.MustCompile(`(fre|french|fran[cç]ais)`)
-		matchEnglish := regexp.MustCompile(`(ang|angl|eng|engl|anglais|english|vo)`)
 		titleString := strings.ToLower(stream.Tags.Title)
 		switch {
 		// If VFQ or VFF, just return right away
 		case matchVFF.MatchString(titleString):
-			return input.TrueFrench
+			return language.EuropeanFrench
 		case matchVFQ.MatchString(titleString):
-			return input.QuebecLanguage
-		case matchFrench.MatchString(titleString):
-			currentGuess = input.FrenchLanguage // Just a guess for now
-		case matchEnglish.MatchString(titleString):
-			currentGuess = input.EnglishLanguage
+			return language.CanadianFrench
+		default:
+			currentGuess = matchTitle(titleString) // Just a guess for now
 		}
 	}
 	// Match language tag
 	if len(stream.Tags.Language) == 0 {
 		return currentGuess
 	}
-	matchFrench := regexp.MustCompile(`^(fre|french|fran[cç]ais)$`)
-	matchEnglish := regexp.MustCompile(`^(ang|angl|eng|engl|anglais|english)$`)
-	languageString := strings.ToLower(stream.Tags.Language)
-	switch {
-	case matchFrench.MatchString(languageString):
-		return input.FrenchLanguage
-	case matchEnglish.MatchString(languageString):
-		return input.EnglishLanguage
-	default:
+	tag, err := language.Parse(stream.Tags.Language)
+	if err != nil {
+		// Some files have names instead of codes
+		var ok bool
+		if tag, ok = language.FromName(stream.Tags.Language); !ok {
+			return currentGuess
+		}
+	}
+	if tag == input.Unknown {
+		return currentGuess
+	}
+	// The title may tell the dialect, e.g. "por" titled "Brazilian"
+	if currentGuess.Matches(tag) {
 		return currentGuess
 	}
+	return tag
 }
 
 func matchForcedTag(stream *probe.ProbeStream) bool {
@@ -0,0 +1,33 @@
+package suggest
+
+import (
+	"testing"
+
+	"github.com/allezxandre/go-hls-encoder/language"
+	"github.com/allezxandre/go-hls-encoder/probe"
+)
+
+func TestMatchLanguage(t *testing.T) {
+	for _, c := range []struct {
+		tag, title string
+		expected   language.Tag
+	}{
+		{"fre", "Français (VFQ)", language.CanadianFrench},
+		{"fre", "VFF", language.EuropeanFrench},
+		{"ger", "", language.German},
+		{"spa", "Latino", language.LatinAmericanSpanish},
+		{"por", "Portuguese (Brazilian)", language.BrazilianPortuguese},
+		{"jpn", "Commentary", language.Japanese},
+		{"ara", "", language.Arabic},
+		{"Japanese", "", language.Japanese},
+		{"", "English SDH", language.English},
+		{"und", "", language.Unknown},
+	} {
+		stream := &probe.ProbeStream{}
+		stream.Tags.Language = c.tag
+		stream.Tags.Title = c.title
+		if found := matchLanguage(stream); found != c.expected {
+			t.Errorf("%q titled %q: %q, expected %q", c.tag, c.title, found, c.expected)
+		}
+	}
+}
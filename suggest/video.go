@@ -2,6 +2,8 @@ package suggest
 
 import (
 	"errors"
+	"fmt"
+	"github.com/allezxandre/go-hls-encoder/codecs"
 	"github.com/allezxandre/go-hls-encoder/probe"
 	"log"
 	"strconv"
@@ -27,96 +29,280 @@ func masterVideo(fileStreams []*probe.ProbeStream) (streamIndex int, err error)
 }
 
 type VideoVariant struct {
-	MapInput   string  // The map value: in the form of $input:$stream
-	Codec      string  // Codec to use, or "copy". Required.
-	CRF        *int    // Optional. CRF Value.
-	Profile    *string // Optional
-	Level      *string // Required if `Profile` is provided.
-	Bitrate    *string // Optional
-	AddHVC1Tag bool    // Add tag `-tag:v hvc1`
+	MapInput   string  `json:"map_input" yaml:"map_input"`                         // The map value: in the form of $input:$stream
+	Codec      string  `json:"codec" yaml:"codec"`                                 // Codec to use, or "copy". Required.
+	CRF        *int    `json:"crf,omitempty" yaml:"crf,omitempty"`                 // Optional. CRF Value.
+	Profile    *string `json:"profile,omitempty" yaml:"profile,omitempty"`         // Optional
+	Level      *string `json:"level,omitempty" yaml:"level,omitempty"`             // Required if `Profile` is provided.
+	Bitrate    *string `json:"bitrate,omitempty" yaml:"bitrate,omitempty"`         // Optional
+	MaxBitrate *string `json:"max_bitrate,omitempty" yaml:"max_bitrate,omitempty"` // Optional. Peak bitrate, requires `BufferSize`
+	BufferSize *string `json:"buffer_size,omitempty" yaml:"buffer_size,omitempty"` // Optional. Rate control buffer size
+	AddHVC1Tag bool    `json:"add_hvc1_tag" yaml:"add_hvc1_tag"`                   // Add tag `-tag:v hvc1`
+	CodecTag   string  `json:"codec_tag,omitempty" yaml:"codec_tag,omitempty"`     // Optional. Tag of the stream, e.g. "dvh1". Replaces `AddHVC1Tag`
+	FrameRate  string  `json:"frame_rate,omitempty" yaml:"frame_rate,omitempty"`   // Frame rate of the source, e.g. "24000/1001". Used to align key frames
+	Codecs     string  `json:"codecs,omitempty" yaml:"codecs,omitempty"`           // RFC 6381 codec of the variant, e.g. "hvc1.2.4.L123.B0". Required for copied streams, see CodecString
+	// Optional. Codec of the enhancement of the stream, e.g. "dvh1.08.06/db1p" for Dolby Vision with an HDR10 base layer
+	SupplementalCodecs string `json:"supplemental_codecs,omitempty" yaml:"supplemental_codecs,omitempty"`
 	// Associated Media
-	AudioGroup    *string // Optional Audio Group
-	SubtitleGroup *string // Optional Subtitle Group
+	AudioGroup    *string `json:"audio_group,omitempty" yaml:"audio_group,omitempty"`       // Optional Audio Group
+	SubtitleGroup *string `json:"subtitle_group,omitempty" yaml:"subtitle_group,omitempty"` // Optional Subtitle Group
 	// M3U8 Playlist options
-	Resolution       string // Resolution for variant in M3U8 playlist
-	Bandwidth        string
-	ResolutionHeight *int // Optional. To use as -filter:v scale="trunc(oh*a/2)*2:HEIGHT"
+	Resolution       string       `json:"resolution" yaml:"resolution"` // Resolution for variant in M3U8 playlist
+	Bandwidth        string       `json:"bandwidth" yaml:"bandwidth"`
+	ResolutionHeight *int         `json:"resolution_height,omitempty" yaml:"resolution_height,omitempty"` // Optional. To use as -filter:v scale="trunc(oh*a/2)*2:HEIGHT"
+	VideoRange       string       `json:"video_range,omitempty" yaml:"video_range,omitempty"`             // Optional. "SDR", "PQ" or "HLG"
+	DynamicRange     DynamicRange `json:"dynamic_range,omitempty" yaml:"dynamic_range,omitempty"`         // Optional. Dynamic range of the variant. HDR metadata of copied streams is kept
+	ToneMap          *ToneMapping `json:"tone_map,omitempty" yaml:"tone_map,omitempty"`                   // Optional. Converts the HDR source to SDR
+	HDCPLevel        string       `json:"hdcp_level,omitempty" yaml:"hdcp_level,omitempty"`               // Optional. "NONE", "TYPE-0" or "TYPE-1"
+	// Informative
+	Analysis *RungAnalysis `json:"analysis,omitempty" yaml:"analysis,omitempty"` // Why the bitrate was chosen, for per-title renditions
 }
 
+// HEVCPolicy How HEVC sources are handled
+type HEVCPolicy string
+
+const (
+	HEVCCopy             HEVCPolicy = "copy"          // Only copy HEVC, without any H.264 rendition: players that cannot decode it get no video
+	HEVCWithH264Fallback HEVCPolicy = "h264-fallback" // Copy HEVC, and add an H.264 rendition at the same resolution. The default
+	HEVCToH264           HEVCPolicy = "h264"          // Only encode H.264 renditions
+)
+
+// VideoOptions How video variants are suggested
+type VideoOptions struct {
+	Ladder  Ladder     // H.264 renditions below the top variant
+	HEVC    HEVCPolicy // Handling of HEVC sources
+	ToneMap string     // Tone-mapping operator of the H.264 renditions of HDR sources, to SDR. Empty to keep them in HDR
+}
+
+// DefaultVideoOptions Returns the options used by SuggestVideoVariants
+func DefaultVideoOptions() VideoOptions {
+	return VideoOptions{
+		Ladder:  DefaultLadder,
+		HEVC:    HEVCWithH264Fallback,
+		ToneMap: DefaultToneMapOperator,
+	}
+}
+
+// Validate Returns an error if the options are invalid
+func (o VideoOptions) Validate() error {
+	switch o.HEVC {
+	case HEVCCopy, HEVCWithH264Fallback, HEVCToH264:
+	default:
+		return fmt.Errorf("unknown HEVC policy %q", o.HEVC)
+	}
+	if len(o.ToneMap) > 0 {
+		if err := validateToneMapOperator(o.ToneMap); err != nil {
+			return err
+		}
+	}
+	return o.Ladder.Validate()
+}
+
+// SuggestVideoVariants Suggests the variants of the video streams,
+// with the DefaultVideoOptions. See SuggestVideo.
 func SuggestVideoVariants(probeDataInputs []*probe.ProbeData) (variants []VideoVariant) {
+	return SuggestVideo(probeDataInputs, DefaultVideoOptions())
+}
+
+// SuggestVideoLadder Suggests the variants of the video streams, with
+// the renditions of `ladder`. See SuggestVideo.
+func SuggestVideoLadder(probeDataInputs []*probe.ProbeData, ladder Ladder) (variants []VideoVariant) {
+	options := DefaultVideoOptions()
+	options.Ladder = ladder
+	return SuggestVideo(probeDataInputs, options)
+}
+
+// SuggestVideo Suggests a top variant for each video stream, copied if possible,
+// followed by the H.264 renditions of the ladder whose resolution is lower.
+func SuggestVideo(probeDataInputs []*probe.ProbeData, options VideoOptions) (variants []VideoVariant) {
+	return suggestVideo(probeDataInputs, options, nil)
+}
+
+// suggestVideo Suggests the variants of the video streams.
+// `analyses` are the complexities of the inputs, if they were analyzed.
+func suggestVideo(probeDataInputs []*probe.ProbeData, options VideoOptions, analyses []*VideoComplexity) (variants []VideoVariant) {
 	for inputIndex, probeData := range probeDataInputs { // Loop through inputs
 		if masterVideoIndex, err := masterVideo(probeData.Streams); err == nil {
 			// Found a video in this input
 			videoStream := probeData.Streams[masterVideoIndex]
+			firstVariant := len(variants)
+			mapInput := strconv.Itoa(inputIndex) + ":" + strconv.Itoa(masterVideoIndex)
 			bandwidth := 700000 // FIXME: Handle unknown bandwidth
+			sourceBitrate := 0  // Unknown
 			if videoStream.BitRate > 0 {
 				bandwidth = videoStream.BitRate
+				sourceBitrate = videoStream.BitRate
 			}
+			frameRate := sourceFrameRate(videoStream)
+			topHeight := videoStream.Height
 			// Match codec
-			switch videoStream.CodecName {
-			case "h264":
-				if videoStream.Height > 540 {
-					// Additional low-quality variant
-					// h264Width, h264Height := computeNewRatio(videoStream, 420)
-					// crf := 28
-					// variants = append(variants, VideoVariant{
-					// 	MapInput:         strconv.Itoa(inputIndex) + ":" + strconv.Itoa(masterVideoIndex),
-					// 	Codec:            "libx264",
-					// 	CRF:              &crf,
-					// 	ResolutionHeight: &h264Height,
-					// 	Resolution:       strconv.Itoa(h264Width) + "x" + strconv.Itoa(h264Height),
-					// 	Bandwidth:        strconv.Itoa(bandwidth / 10),
-					// })
-				}
-				// Only one variant: copy
+			switch {
+			case videoStream.CodecName == "h264":
+				// Top variant: copy
 				variants = append(variants, VideoVariant{
-					MapInput:   strconv.Itoa(inputIndex) + ":" + strconv.Itoa(masterVideoIndex),
+					MapInput:   mapInput,
 					Codec:      "copy",
 					Resolution: strconv.Itoa(videoStream.Width) + "x" + strconv.Itoa(videoStream.Height),
 					Bandwidth:  strconv.Itoa(bandwidth),
+					FrameRate:  frameRate,
+					Codecs:     copiedCodecs(videoStream),
 				})
-			case "h265", "hevc":
-				// HEVC -> 2 variants: copy and x264
+			case isHEVC(videoStream) && options.HEVC == HEVCCopy:
+				// HEVC only: no H.264 renditions
 				log.Println("High efficiency stream detected. Copying...")
-				variants = append(variants, VideoVariant{
-					MapInput:   strconv.Itoa(inputIndex) + ":" + strconv.Itoa(masterVideoIndex),
-					Codec:      "copy",
-					Resolution: strconv.Itoa(videoStream.Width) + "x" + strconv.Itoa(videoStream.Height),
-					Bandwidth:  strconv.Itoa(bandwidth * 2),
-					AddHVC1Tag: true,
-				})
-				/*
-					// For the x264 variant, compute height setting
-					h264Width, h264Height := computeNewRatio(videoStream, 360)
-					crf := 18
-					variants = append(variants, VideoVariant{
-						MapInput:         strconv.Itoa(inputIndex) + ":" + strconv.Itoa(masterVideoIndex),
-						Codec:            "libx264",
-						CRF:              &crf,
-						ResolutionHeight: &h264Height,
-						Resolution:       strconv.Itoa(h264Width) + "x" + strconv.Itoa(h264Height),
-						Bandwidth:        strconv.Itoa(730000),
-					})
-				*/
+				variants = append(variants, hevcCopyVariant(mapInput, videoStream, bandwidth))
+				topHeight = 0 // Below every rung of the ladder
+			case isHEVC(videoStream) && options.HEVC == HEVCWithH264Fallback:
+				// HEVC -> copy, and an x264 rendition for players that cannot decode it
+				log.Println("High efficiency stream detected. Copying, with an H.264 fallback...")
+				variants = append(variants, hevcCopyVariant(mapInput, videoStream, bandwidth))
+				// An H.264 stream needs about twice the bitrate of an HEVC stream
+				fallback := h264TopVariant(mapInput, videoStream, 2*bandwidth, 2*sourceBitrate)
+				variants = append(variants, fallback)
+				topHeight = *fallback.ResolutionHeight
+				sourceBitrate = 0
 			default:
-				// One variant: converter to x264, after computing height setting
-				h264Width, h264Height := computeNewRatio(videoStream, 1080)
-				crf := 18
-				variants = append(variants, VideoVariant{
-					MapInput:         strconv.Itoa(inputIndex) + ":" + strconv.Itoa(masterVideoIndex),
-					Codec:            "libx264",
-					CRF:              &crf,
-					ResolutionHeight: &h264Height,
-					Resolution:       strconv.Itoa(h264Width) + "x" + strconv.Itoa(h264Height),
-					Bandwidth:        strconv.Itoa(bandwidth),
-				})
+				// Top variant: converted to x264
+				if isHEVC(videoStream) {
+					// An H.264 stream needs about twice the bitrate of an HEVC stream
+					bandwidth, sourceBitrate = 2*bandwidth, 2*sourceBitrate
+				}
+				top := h264TopVariant(mapInput, videoStream, bandwidth, sourceBitrate)
+				variants = append(variants, top)
+				topHeight = *top.ResolutionHeight
+				// The source bitrate does not apply to another codec
+				sourceBitrate = 0
+			}
+			// Lower renditions
+			var complexity *VideoComplexity
+			if inputIndex < len(analyses) {
+				complexity = analyses[inputIndex]
+			}
+			variants = append(variants, ladderVariants(options.Ladder, mapInput, videoStream, topHeight, sourceBitrate, complexity)...)
+			// The transfer function of the base layer is kept by the conversions,
+			// unless they are tone-mapped to SDR
+			baseRange := baseLayerRange(videoStream)
+			if baseRange == DolbyVisionOnly {
+				// Converting its base layer would give wrong colors: the stream is only copied
+				log.Printf("WARNING: Dolby Vision profile %d stream %d has no backward compatible base layer. Skipping its H.264 renditions",
+					videoStream.DOVIConfiguration().DVProfile, videoStream.Index)
+				copied := variants[:firstVariant]
+				for _, variant := range variants[firstVariant:] {
+					if variant.Codec == "copy" {
+						copied = append(copied, variant)
+					}
+				}
+				variants = copied
+			}
+			toneMap := toneMapping(baseRange, options.ToneMap)
+			for i := firstVariant; i < len(variants); i++ {
+				if toneMap != nil && variants[i].Codec != "copy" {
+					t := *toneMap
+					variants[i].ToneMap = &t
+					variants[i].DynamicRange = SDR
+				}
+				if len(variants[i].DynamicRange) == 0 {
+					variants[i].DynamicRange = baseRange
+				}
+				variants[i].VideoRange = variants[i].DynamicRange.VideoRange()
+				if variants[i].DynamicRange == DolbyVision {
+					// Announced with the range of its base layer
+					variants[i].VideoRange = baseRange.VideoRange()
+				}
 			}
-
 		}
 	}
 	return
 }
 
+// isHEVC Returns true if the stream is encoded in HEVC
+func isHEVC(videoStream *probe.ProbeStream) bool {
+	return videoStream.CodecName == "hevc" || videoStream.CodecName == "h265"
+}
+
+// hevcCopyVariant Returns the variant copying the HEVC stream `videoStream`,
+// with its HDR metadata
+func hevcCopyVariant(mapInput string, videoStream *probe.ProbeStream, bandwidth int) VideoVariant {
+	variant := VideoVariant{
+		MapInput:     mapInput,
+		Codec:        "copy",
+		Resolution:   strconv.Itoa(videoStream.Width) + "x" + strconv.Itoa(videoStream.Height),
+		Bandwidth:    strconv.Itoa(bandwidth * 2),
+		AddHVC1Tag:   true,
+		FrameRate:    sourceFrameRate(videoStream),
+		Codecs:       copiedCodecs(videoStream),
+		DynamicRange: ClassifyDynamicRange(videoStream),
+	}
+	if variant.DynamicRange == DolbyVision {
+		applyDolbyVision(&variant, videoStream)
+	}
+	return variant
+}
+
+// h264TopVariant Returns a variant converting `videoStream` to x264, at 1080p at most.
+// The bitrate is capped to `maxBitrate`, unless it is 0.
+func h264TopVariant(mapInput string, videoStream *probe.ProbeStream, bandwidth, maxBitrate int) VideoVariant {
+	h264Width, h264Height := computeNewRatio(videoStream, 1080)
+	crf := 18
+	profile := "high"
+	level := h264Level(h264Width, h264Height, videoStream.FrameRate(), bandwidth, profile, "4.0")
+	variant := VideoVariant{
+		MapInput:         mapInput,
+		Codec:            "libx264",
+		CRF:              &crf,
+		Profile:          &profile,
+		Level:            &level,
+		ResolutionHeight: &h264Height,
+		Resolution:       strconv.Itoa(h264Width) + "x" + strconv.Itoa(h264Height),
+		Bandwidth:        strconv.Itoa(bandwidth),
+		FrameRate:        sourceFrameRate(videoStream),
+	}
+	if maxBitrate > 0 {
+		maxBitrateS := strconv.Itoa(maxBitrate)
+		bufferSize := strconv.Itoa(2 * maxBitrate)
+		variant.MaxBitrate = &maxBitrateS
+		variant.BufferSize = &bufferSize
+	}
+	return variant
+}
+
+// CodecString Returns the RFC 6381 codec of the variant: `Codecs` if set,
+// or the one of its encoder settings. Returns "" if unknown.
+func (v VideoVariant) CodecString() string {
+	if len(v.Codecs) > 0 || v.Codec == "copy" {
+		return v.Codecs
+	}
+	var profile, level string
+	if v.Profile != nil {
+		profile = *v.Profile
+	}
+	if v.Level != nil {
+		level = *v.Level
+	}
+	codec, _ := codecs.FromEncoder(v.Codec, profile, level)
+	return codec
+}
+
+// copiedCodecs Returns the RFC 6381 codec of `stream` when copied, or "" if unknown
+func copiedCodecs(stream *probe.ProbeStream) string {
+	codec, err := codecs.FromProbe(stream)
+	if err != nil {
+		log.Println("WARNING: Cannot find the CODECS of stream", stream.Index, err)
+	}
+	return codec
+}
+
+// sourceFrameRate Returns the frame rate of the stream as written by ffprobe,
+// preferring the average frame rate. Returns "" if unknown.
+func sourceFrameRate(videoStream *probe.ProbeStream) string {
+	for _, value := range []string{videoStream.AvgFrameRate, videoStream.RFrameRate} {
+		if rate, err := probe.ParseRational(value); err == nil && rate > 0 {
+			return value
+		}
+	}
+	return ""
+}
+
 func computeNewRatio(videoStream *probe.ProbeStream, maximumHeight int) (int, int) {
 	h264Height := videoStream.Height // The height of the h264 stream to use
 	if h264Height > maximumHeight {
@@ -0,0 +1,121 @@
+package suggest
+
+import (
+	"testing"
+
+	"github.com/allezxandre/go-hls-encoder/probe"
+)
+
+func TestHEVCFallback(t *testing.T) {
+	probeData := &probe.ProbeData{Streams: []*probe.ProbeStream{{
+		Index:              0,
+		CodecName:          "hevc",
+		CodecType:          "video",
+		Profile:            "Main 10",
+		Level:              150,
+		Width:              3840,
+		Height:             2160,
+		DisplayAspectRatio: "16:9",
+		BitRate:            8000000,
+	}, {
+		Index:     1,
+		CodecName: "aac",
+		CodecType: "audio",
+		Profile:   "LC",
+		Channels:  2,
+	}}}
+	options := VideoOptions{HEVC: HEVCWithH264Fallback}
+	variants := SuggestVideo([]*probe.ProbeData{probeData}, options)
+	if len(variants) != 2 {
+		t.Fatal("Unexpected number of variants:", len(variants))
+	}
+	if variants[0].Codec != "copy" || variants[0].CodecString() != "hvc1.2.4.L150.B0" {
+		t.Errorf("Unexpected HEVC variant: %s %s", variants[0].Codec, variants[0].CodecString())
+	}
+	if variants[1].Codec != "libx264" || variants[1].Resolution != "1920x1080" || variants[1].CodecString() != "avc1.640028" {
+		t.Errorf("Unexpected fallback variant: %s %s %s", variants[1].Codec, variants[1].Resolution, variants[1].CodecString())
+	}
+
+	options.HEVC = HEVCCopy
+	if variants := SuggestVideo([]*probe.ProbeData{probeData}, options); len(variants) != 1 {
+		t.Error("Unexpected number of variants for the copy policy:", len(variants))
+	}
+
+	audio := SuggestAudio([]*probe.ProbeData{probeData}, false, DefaultLanguagePolicy())
+	if codecs := VariantCodecs(variants[1], audio); codecs != "avc1.640028,mp4a.40.2" {
+		t.Error("Unexpected codecs of the fallback variant:", codecs)
+	}
+}
+
+// testHDR10Stream Returns a 4K HEVC stream with PQ transfer and BT.2020 primaries
+func testHDR10Stream() *probe.ProbeStream {
+	return &probe.ProbeStream{
+		Index:              0,
+		CodecName:          "hevc",
+		CodecType:          "video",
+		Profile:            "Main 10",
+		Level:              153,
+		Width:              3840,
+		Height:             2160,
+		DisplayAspectRatio: "16:9",
+		ColorTransfer:      "smpte2084",
+		ColorPrimaries:     "bt2020",
+	}
+}
+
+func TestDynamicRange(t *testing.T) {
+	stream := testHDR10Stream()
+	probeData := []*probe.ProbeData{{Streams: []*probe.ProbeStream{stream}}}
+	options := VideoOptions{HEVC: HEVCWithH264Fallback}
+
+	variants := SuggestVideo(probeData, options)
+	if variants[0].DynamicRange != HDR10 || variants[0].VideoRange != "PQ" || !variants[0].AddHVC1Tag {
+		t.Errorf("Unexpected HDR10 variant: %+v", variants[0])
+	}
+	copyOptions := VideoOptions{HEVC: HEVCCopy}
+	if v := SuggestVideo(probeData, copyOptions); len(v) != 1 || v[0].VideoRange != "PQ" {
+		t.Errorf("Unexpected HDR10 copy: %+v", v)
+	}
+
+	// Dolby Vision profile 8.4, with an HLG base layer
+	stream.ColorTransfer = "arib-std-b67"
+	stream.SideDataList = []probe.StreamSideData{{SideDataType: probe.SideDataDOVIConfiguration,
+		DVProfile: 8, DVLevel: 6, RPUPresentFlag: 1, BLPresentFlag: 1, DVBLSignalCompatibilityID: 4}}
+	variants = SuggestVideo(probeData, options)
+	if v := variants[0]; v.DynamicRange != DolbyVision || v.VideoRange != "HLG" || v.SupplementalCodecs != "dvh1.08.06/db4h" {
+		t.Errorf("Unexpected Dolby Vision variant: %+v", v)
+	}
+	if v := variants[1]; v.Codec != "libx264" || v.DynamicRange != HLG || len(v.SupplementalCodecs) > 0 {
+		t.Errorf("Unexpected fallback of the Dolby Vision variant: %+v", v)
+	}
+
+	// Dolby Vision profile 5, without a backward compatible base layer
+	stream.SideDataList[0].DVProfile, stream.SideDataList[0].DVBLSignalCompatibilityID = 5, 0
+	variants = SuggestVideo(probeData, options)
+	if len(variants) != 1 {
+		t.Fatal("Unexpected H.264 renditions of an IPTPQc2 base layer:", variants[1:])
+	}
+	if v := variants[0]; v.Codecs != "dvh1.05.06" || v.CodecTag != "dvh1" || v.VideoRange != "PQ" {
+		t.Errorf("Unexpected Dolby Vision profile 5 variant: %+v", v)
+	}
+	if variants := SuggestVideo(probeData, VideoOptions{HEVC: HEVCToH264, ToneMap: DefaultToneMapOperator}); len(variants) != 0 {
+		t.Error("Unexpected conversions of a Dolby Vision profile 5 stream:", variants)
+	}
+}
+
+func TestToneMapping(t *testing.T) {
+	stream := testHDR10Stream()
+	stream.BitRate = 16000000
+	variants := SuggestVideoVariants([]*probe.ProbeData{{Streams: []*probe.ProbeStream{stream}}})
+	if len(variants) < 3 {
+		t.Fatal("Unexpected number of variants:", len(variants))
+	}
+	if v := variants[0]; v.Codec != "copy" || v.VideoRange != "PQ" || v.ToneMap != nil {
+		t.Errorf("Unexpected HDR variant: %+v", v)
+	}
+	for _, v := range variants[1:] {
+		if v.VideoRange != "SDR" || v.ToneMap == nil || v.ToneMap.Transfer != "smpte2084" || v.ToneMap.Operator != DefaultToneMapOperator {
+			t.Errorf("H.264 rendition %s not tone-mapped: %+v", v.Resolution, v)
+		}
+	}
+}
@@ -25,7 +25,10 @@ const (
 
 var BytesBOM = []byte{239, 187, 191}
 
+// ReadFromWebVTT Reads WebVTT blocks from `i` and sends them to `c`.
+// `c` is closed when the input is exhausted or an error occurs.
 func ReadFromWebVTT(i io.Reader, c chan<- SubtitleBlock) (err error) {
+	defer close(c)
 	// Init
 	var scanner = bufio.NewScanner(i)
 	var line string
@@ -102,8 +105,7 @@ func ReadFromWebVTT(i io.Reader, c chan<- SubtitleBlock) (err error) {
 		// Send last block
 		c <- *currentBlock
 	}
-	close(c)
-	return
+	return scanner.Err()
 }
 
 // parseDurationWebVTT parses a .vtt duration
@@ -0,0 +1,204 @@
+package webvtt
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"path/filepath"
+	"time"
+
+	"github.com/allezxandre/go-hls-encoder/llhls"
+)
+
+// SegmentParts Segments the webvtt input from `r` into parts of `partDuration`, announced in a
+// low-latency playlist as soon as they are written, so that subtitles follow the parts of the video.
+// Parts without cues are written in real time, see cueDelay.
+// Parts are named `<name>-<segment>.<part>.vtt` and segments `<name>-<segment>.vtt`.
+// It returns once `r` has been read entirely, even if segmenting failed.
+func SegmentParts(r io.Reader, targetDuration, partDuration time.Duration, outputDir, name string) error {
+	return SegmentPartsContext(context.Background(), r, targetDuration, partDuration, outputDir, name)
+}
+
+// SegmentPartsContext Same as SegmentParts, but returns `ctx.Err()` as soon as `ctx` is done.
+// In that case, `r` keeps being read in the background until it is closed.
+func SegmentPartsContext(ctx context.Context, r io.Reader, targetDuration, partDuration time.Duration, outputDir, name string) error {
+	if partDuration <= 0 || partDuration >= targetDuration {
+		return fmt.Errorf("invalid part duration %v for segments of %v", partDuration, targetDuration)
+	}
+	s := newWindowSegmenter(outputDir, name, targetDuration, partDuration)
+	return readBlocks(ctx, r, func(c <-chan SubtitleBlock) error {
+		if err := s.follow(ctx, c); err != nil {
+			return err
+		}
+		return s.end()
+	})
+}
+
+// cueDelay How long after their end, in real time, windows are written when no later cue wrote them
+// before, e.g. during a gap in dialogue: ffmpeg starts reading the input before the clock starts.
+var cueDelay = 10 * time.Second
+
+// follow Adds the blocks of `c` until it is closed, then ends the input. Meanwhile, the windows
+// are written in real time, `cueDelay` after their end, so that the playlist keeps up with the video.
+func (s *windowSegmenter) follow(ctx context.Context, c <-chan SubtitleBlock) error {
+	start := time.Now()
+	ticker := time.NewTicker(s.window / 2)
+	defer ticker.Stop()
+	for {
+		select {
+		case b, ok := <-c:
+			if !ok {
+				return s.endInput()
+			}
+			if err := s.add(b); err != nil {
+				return err
+			}
+		case <-ticker.C:
+			if err := s.advance(time.Since(start) - cueDelay); err != nil {
+				return err
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// windowSegmenter Writes the blocks of fixed time windows to parts and segments: part `k` covers
+// [k*partDuration, (k+1)*partDuration), and segments are made of `partsPerSegment` parts.
+type windowSegmenter struct {
+	outputDir, name string
+	window          time.Duration // Duration of the parts
+	partsPerSegment int
+
+	playlist llhls.Playlist
+	part     int             // Index of the window being written, from the start
+	blocks   []SubtitleBlock // Blocks of the segment being written, by start time
+	lastEnd  time.Duration   // End of the last block
+}
+
+func newWindowSegmenter(outputDir, name string, targetDuration, partDuration time.Duration) *windowSegmenter {
+	s := &windowSegmenter{
+		outputDir:       outputDir,
+		name:            name,
+		window:          partDuration,
+		partsPerSegment: int(float64(targetDuration)/float64(partDuration) + 0.5),
+	}
+	s.playlist.PartTarget = partDuration
+	s.playlist.TargetDuration = time.Duration(s.partsPerSegment) * s.window
+	return s
+}
+
+func (s *windowSegmenter) partStart() time.Duration {
+	return time.Duration(s.part) * s.window
+}
+
+func (s *windowSegmenter) partEnd() time.Duration {
+	return s.partStart() + s.window
+}
+
+// partName Returns the filename of the part `part`
+func (s *windowSegmenter) partName(part int) string {
+	return fmt.Sprintf("%s-%05d.%d.vtt", s.name, part/s.partsPerSegment, part%s.partsPerSegment)
+}
+
+// add Adds a block. Blocks come by start time: the windows before it are complete.
+func (s *windowSegmenter) add(b SubtitleBlock) error {
+	if err := s.advance(b.StartTime); err != nil {
+		return err
+	}
+	if b.EndTime <= s.partStart() {
+		log.Printf("WARNING: Subtitle block at %v of %s came after its segment was written\n", b.StartTime, s.name)
+		return nil
+	}
+	s.blocks = append(s.blocks, b)
+	if b.EndTime > s.lastEnd {
+		s.lastEnd = b.EndTime
+	}
+	return nil
+}
+
+// advance Writes the windows that end before `t`
+func (s *windowSegmenter) advance(t time.Duration) error {
+	for t >= s.partEnd() {
+		if err := s.closePart(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// endInput Writes the windows up to the end of the last block, and the last, shorter, segment
+func (s *windowSegmenter) endInput() error {
+	for s.lastEnd > s.partStart() {
+		if err := s.closePart(); err != nil {
+			return err
+		}
+	}
+	if len(s.playlist.Pending) > 0 {
+		if err := s.closeSegment(); err != nil {
+			return err
+		}
+	}
+	return s.write()
+}
+
+// end Ends the playlist
+func (s *windowSegmenter) end() error {
+	s.playlist.Ended = true
+	return s.write()
+}
+
+func (s *windowSegmenter) write() error {
+	s.playlist.PreloadHint = s.partName(s.part)
+	return s.playlist.WriteFile(filepath.Join(s.outputDir, s.name+".m3u8"))
+}
+
+// closePart Writes the blocks of the current window, and the segment if it is its last part,
+// then updates the playlist
+func (s *windowSegmenter) closePart() error {
+	partName := s.partName(s.part)
+	if err := writeBlocksToVTT(overlapping(s.blocks, s.partStart(), s.partEnd()), filepath.Join(s.outputDir, partName)); err != nil {
+		return err
+	}
+	s.playlist.Pending = append(s.playlist.Pending, llhls.Part{Duration: s.window, URI: partName, Independent: true})
+	s.part++
+	if s.part%s.partsPerSegment == 0 {
+		if err := s.closeSegment(); err != nil {
+			return err
+		}
+	}
+	return s.write()
+}
+
+// closeSegment Writes the blocks of the segment that ends with the current window
+func (s *windowSegmenter) closeSegment() error {
+	end := s.partStart()
+	start := end - time.Duration((s.part-1)%s.partsPerSegment+1)*s.window
+	segmentName := fmt.Sprintf("%s-%05d.vtt", s.name, (s.part-1)/s.partsPerSegment)
+	if err := writeBlocksToVTT(overlapping(s.blocks, start, end), filepath.Join(s.outputDir, segmentName)); err != nil {
+		return err
+	}
+	s.playlist.Segments = append(s.playlist.Segments, llhls.Segment{Duration: end - start, URI: segmentName, Parts: s.playlist.Pending})
+	s.playlist.Pending = nil
+	// Blocks astride the segments are kept for the next one
+	var next []SubtitleBlock
+	for _, b := range s.blocks {
+		if b.EndTime > end {
+			next = append(next, b)
+		}
+	}
+	s.blocks = next
+	return nil
+}
+
+// overlapping Returns the blocks that are displayed between `start` and `end`
+func overlapping(blocks []SubtitleBlock, start, end time.Duration) []SubtitleBlock {
+	var kept []SubtitleBlock
+	for _, b := range blocks {
+		if b.StartTime < end && b.EndTime > start {
+			kept = append(kept, b)
+		}
+	}
+	return kept
+}
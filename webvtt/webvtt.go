@@ -5,12 +5,14 @@ package webvtt
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"log"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 )
 
@@ -19,14 +21,46 @@ type SubtitleBlock struct {
 	Lines              bytes.Buffer  // A buffer containing the whole block
 }
 
-// Segment Segments the webvtt input from `r`
+// Segment Segments the webvtt input from `r`.
+// It returns once `r` has been read entirely, even if segmenting failed.
 func Segment(r io.Reader, targetDuration time.Duration, outputDir, name string) error {
+	return SegmentContext(context.Background(), r, targetDuration, outputDir, name)
+}
+
+// SegmentContext Same as Segment, but returns `ctx.Err()` as soon as `ctx` is done.
+// In that case, `r` keeps being read in the background until it is closed.
+func SegmentContext(ctx context.Context, r io.Reader, targetDuration time.Duration, outputDir, name string) error {
+	return readBlocks(ctx, r, func(c <-chan SubtitleBlock) error {
+		return segment(ctx, c, targetDuration, outputDir, name)
+	})
+}
+
+// readBlocks Reads the blocks of `r` and sends them to `f`.
+// If `f` fails, `r` is read entirely, in the background if `ctx` is done.
+func readBlocks(ctx context.Context, r io.Reader, f func(c <-chan SubtitleBlock) error) error {
 	c := make(chan SubtitleBlock)
-	go ReadFromWebVTT(r, c)
-	return segment(c, targetDuration, outputDir, name)
+	readErr := make(chan error, 1)
+	go func() {
+		readErr <- ReadFromWebVTT(r, c)
+	}()
+	if err := f(c); err != nil {
+		// Let the reader finish
+		drain := func() {
+			for range c {
+			}
+		}
+		if ctx.Err() != nil {
+			go drain()
+			return err
+		}
+		drain()
+		<-readErr
+		return err
+	}
+	return <-readErr
 }
 
-func segment(c <-chan SubtitleBlock, targetDuration time.Duration, outputDir, name string) error {
+func segment(ctx context.Context, c <-chan SubtitleBlock, targetDuration time.Duration, outputDir, name string) error {
 	playlistPath := filepath.Join(outputDir, name+".m3u8")
 	playlist, err := createPlaylistFile(playlistPath, targetDuration)
 	if err != nil {
@@ -40,7 +74,19 @@ func segment(c <-chan SubtitleBlock, targetDuration time.Duration, outputDir, na
 	var endTime time.Duration = 0
 	var count uint = 0
 
-	b, ok := <-c
+	next := func() (SubtitleBlock, bool, error) {
+		select {
+		case b, ok := <-c:
+			return b, ok, nil
+		case <-ctx.Done():
+			return SubtitleBlock{}, false, ctx.Err()
+		}
+	}
+
+	b, ok, err := next()
+	if err != nil {
+		return err
+	}
 	segmentAdded := false
 	for ok {
 		newEnd := b.EndTime
@@ -57,7 +103,9 @@ func segment(c <-chan SubtitleBlock, targetDuration time.Duration, outputDir, na
 		// Segment now?
 		if endTime-startTime >= targetDuration {
 			// Yes
-			createSegment(name, count, outputDir, blocks, playlist, startTime, endTime)
+			if err := createSegment(name, count, outputDir, blocks, playlist, startTime, endTime); err != nil {
+				return err
+			}
 
 			// New segment
 			blocks = make([]SubtitleBlock, 0, 5)
@@ -71,20 +119,26 @@ func segment(c <-chan SubtitleBlock, targetDuration time.Duration, outputDir, na
 		}
 		if segmentAdded {
 			// Next block
-			b, ok = <-c
+			b, ok, err = next()
+			if err != nil {
+				return err
+			}
 			segmentAdded = false
 		}
 	}
 	if endTime-startTime > 0 {
-		createSegment(name, count, outputDir, blocks, playlist, startTime, endTime)
+		return createSegment(name, count, outputDir, blocks, playlist, startTime, endTime)
 	}
 	return nil
 }
-func createSegment(basename string, segmentCount uint, outputDir string, blocks []SubtitleBlock, playlist *os.File, startTime, endTime time.Duration) {
+
+func createSegment(basename string, segmentCount uint, outputDir string, blocks []SubtitleBlock, playlist *os.File, startTime, endTime time.Duration) error {
 	segmentName := fmt.Sprintf("%s-%05d.vtt", basename, segmentCount)
 	segmentFilepath := filepath.Join(outputDir, segmentName)
-	writeBlocksToVTT(blocks, segmentFilepath)
-	addSegmentToPlaylist(playlist, endTime-startTime, segmentName)
+	if err := writeBlocksToVTT(blocks, segmentFilepath); err != nil {
+		return err
+	}
+	return addSegmentToPlaylist(playlist, endTime-startTime, segmentName)
 }
 
 func writeBlocksToVTT(blocks []SubtitleBlock, filepath string) error {
@@ -123,6 +177,50 @@ func closePlaylistFile(f *os.File) {
 	f.Close()
 }
 
-func addSegmentToPlaylist(p *os.File, duration time.Duration, name string) {
-	p.WriteString(fmt.Sprintf("#EXTINF:%.6f,\n%s\n", duration.Seconds(), name))
+func addSegmentToPlaylist(p *os.File, duration time.Duration, name string) error {
+	_, err := p.WriteString(fmt.Sprintf("#EXTINF:%.6f,\n%s\n", duration.Seconds(), name))
+	return err
+}
+
+// Join Writes the cues of the segments of the WebVTT media playlist at `playlistPath`
+// to a single file at `output`, e.g. for DASH players. Cues written in several segments
+// because they span their boundary are only written once.
+func Join(playlistPath, output string) error {
+	data, err := ioutil.ReadFile(playlistPath)
+	if err != nil {
+		return err
+	}
+	var joined bytes.Buffer
+	joined.WriteString("WEBVTT\n\n")
+	var previous map[string]bool // Cues of the previous segment
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+		f, err := os.Open(filepath.Join(filepath.Dir(playlistPath), line))
+		if err != nil {
+			return err
+		}
+		c := make(chan SubtitleBlock)
+		readErr := make(chan error, 1)
+		go func() {
+			readErr <- ReadFromWebVTT(f, c)
+		}()
+		cues := map[string]bool{}
+		for b := range c {
+			// Blank lines before the cue depend on its position in the segment
+			cue := strings.TrimSpace(b.Lines.String())
+			if !previous[cue] {
+				joined.WriteString(cue + "\n\n")
+			}
+			cues[cue] = true
+		}
+		f.Close()
+		if err := <-readErr; err != nil {
+			return fmt.Errorf("%s: %v", line, err)
+		}
+		previous = cues
+	}
+	return ioutil.WriteFile(output, joined.Bytes(), 0644)
 }
@@ -1,9 +1,13 @@
 package webvtt
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 )
@@ -23,6 +27,110 @@ func TestRead(t *testing.T) {
 
 	c := make(chan SubtitleBlock)
 	go ReadFromWebVTT(f, c)
-	segment(c, 5*time.Second, outputDir, "test1")
+	segment(context.Background(), c, 5*time.Second, outputDir, "test1")
 	// TODO: Test output
 }
+
+func TestJoin(t *testing.T) {
+	dir, err := ioutil.TempDir("", "go-hls-encoder-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	files := map[string]string{
+		"sub.m3u8":      "#EXTM3U\n#EXTINF:5.000000,\nsub-00000.vtt\n#EXTINF:5.000000,\nsub-00001.vtt\n#EXT-X-ENDLIST\n",
+		"sub-00000.vtt": "WEBVTT\n\n00:00:01.000 --> 00:00:02.000\nFirst\n\n00:00:04.000 --> 00:00:06.000\nAcross\n\n",
+		"sub-00001.vtt": "WEBVTT\n\n00:00:04.000 --> 00:00:06.000\nAcross\n\n00:00:07.000 --> 00:00:08.000\nLast\n\n",
+	}
+	for name, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := Join(filepath.Join(dir, "sub.m3u8"), filepath.Join(dir, "sub.vtt")); err != nil {
+		t.Fatal("Cannot join segments:", err)
+	}
+	joined, _ := ioutil.ReadFile(filepath.Join(dir, "sub.vtt"))
+	expected := "WEBVTT\n\n00:00:01.000 --> 00:00:02.000\nFirst\n\n00:00:04.000 --> 00:00:06.000\nAcross\n\n" +
+		"00:00:07.000 --> 00:00:08.000\nLast\n\n"
+	if string(joined) != expected {
+		t.Errorf("Unexpected joined subtitles:\n%s", joined)
+	}
+}
+
+func TestSegmentParts(t *testing.T) {
+	dir, err := ioutil.TempDir("", "go-hls-encoder-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	input := "WEBVTT\n\n00:00:00.500 --> 00:00:01.500\nFirst\n\n00:00:03.500 --> 00:00:04.500\nAcross\n\n" +
+		"00:00:05.000 --> 00:00:05.500\nLast\n"
+	if err := SegmentParts(strings.NewReader(input), 4*time.Second, time.Second, dir, "sub"); err != nil {
+		t.Fatal("Cannot segment:", err)
+	}
+	playlist, _ := ioutil.ReadFile(filepath.Join(dir, "sub.m3u8"))
+	// Two segments of 4 and 2 parts, the second one ending with the last cue
+	for _, expected := range []string{
+		"#EXT-X-PART-INF:PART-TARGET=1.00000\n",
+		"#EXT-X-PART:DURATION=1.00000,URI=\"sub-00000.3.vtt\",INDEPENDENT=YES\n#EXTINF:4.00000,\nsub-00000.vtt\n",
+		"#EXT-X-PART:DURATION=1.00000,URI=\"sub-00001.1.vtt\",INDEPENDENT=YES\n#EXTINF:2.00000,\nsub-00001.vtt\n#EXT-X-ENDLIST\n",
+	} {
+		if !strings.Contains(string(playlist), expected) {
+			t.Errorf("Expected %q in playlist:\n%s", expected, playlist)
+		}
+	}
+	for name, cues := range map[string][]string{
+		"sub-00000.0.vtt": {"First"},
+		"sub-00000.2.vtt": nil,
+		"sub-00000.3.vtt": {"Across"},
+		"sub-00001.0.vtt": {"Across"},
+		"sub-00001.1.vtt": {"Last"},
+		"sub-00001.vtt":   {"Across", "Last"},
+	} {
+		data, err := ioutil.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Count(string(data), "-->") != len(cues) {
+			t.Errorf("Unexpected cues in %s:\n%s", name, data)
+		}
+		for _, cue := range cues {
+			if !strings.Contains(string(data), cue) {
+				t.Errorf("Expected cue %q in %s:\n%s", cue, name, data)
+			}
+		}
+	}
+}
+
+func TestSegmentPartsGap(t *testing.T) {
+	dir, err := ioutil.TempDir("", "go-hls-encoder-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	defer func(delay time.Duration) { cueDelay = delay }(cueDelay)
+	cueDelay = 0
+	r, w := io.Pipe()
+	done := make(chan error, 1)
+	go func() {
+		done <- SegmentParts(r, 400*time.Millisecond, 100*time.Millisecond, dir, "sub")
+	}()
+	// A single cue, then a gap in dialogue: the parts keep being written in real time
+	io.WriteString(w, "WEBVTT\n\n00:00:00.000 --> 00:00:00.100\nFirst\n\n00:00:02.000 --> 00:00:02.100\nLater\n")
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		playlist, _ := ioutil.ReadFile(filepath.Join(dir, "sub.m3u8"))
+		if strings.Contains(string(playlist), "sub-00001.vtt") {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("The parts stalled during the gap:\n%s", playlist)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	w.Close()
+	if err := <-done; err != nil {
+		t.Fatal("Cannot segment:", err)
+	}
+}